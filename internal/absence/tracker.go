@@ -0,0 +1,179 @@
+package absence
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/NikolayStrekalov/vigilant-octo-waddle.git/internal/logger"
+	"github.com/NikolayStrekalov/vigilant-octo-waddle.git/internal/models"
+	"github.com/mailru/easyjson"
+)
+
+const (
+	KindAgent    = "agent"
+	StateAbsent  = "absent"
+	StatePresent = "present"
+)
+
+// Notifier получает события о пропаже и возвращении метрик и агентов.
+type Notifier interface {
+	Send(easyjson.Marshaler) error
+}
+
+type entry struct {
+	lastSeen  time.Time
+	changedAt time.Time
+	absent    bool
+}
+
+// Tracker помечает отсутствующими метрики и агентов, от которых давно не было обновлений.
+type Tracker struct {
+	notifier Notifier
+	seen     map[[2]string]*entry // ключ — тип и имя
+	queue    []models.Absence     // события, ещё не переданные получателю
+	wake     chan struct{}
+	mux      *sync.Mutex
+	timeout  time.Duration
+}
+
+func NewTracker(timeout time.Duration) *Tracker {
+	return &Tracker{
+		seen:    make(map[[2]string]*entry),
+		wake:    make(chan struct{}, 1),
+		mux:     &sync.Mutex{},
+		timeout: timeout,
+	}
+}
+
+// SetNotifier задаёт получателя событий и запускает их отправку.
+func (t *Tracker) SetNotifier(n Notifier) {
+	t.mux.Lock()
+	started := t.notifier != nil
+	t.notifier = n
+	t.mux.Unlock()
+	if !started {
+		go t.send()
+	}
+}
+
+// send передаёт события получателю в порядке их появления, одна горутина на трекер.
+func (t *Tracker) send() {
+	for range t.wake {
+		t.mux.Lock()
+		events, n := t.queue, t.notifier
+		t.queue = nil
+		t.mux.Unlock()
+		for i := range events {
+			if err := n.Send(&events[i]); err != nil {
+				logger.Info("absence notification error:", err)
+			}
+		}
+	}
+}
+
+// Seen отмечает обновление, отсутствие снимается сразу.
+func (t *Tracker) Seen(kind, name string, now time.Time) {
+	t.mux.Lock()
+	defer t.mux.Unlock()
+	e, ok := t.seen[[2]string{kind, name}]
+	if !ok {
+		t.seen[[2]string{kind, name}] = &entry{lastSeen: now, changedAt: now}
+		return
+	}
+	e.lastSeen = now
+	if e.absent {
+		e.absent = false
+		e.changedAt = now
+		t.notify([]models.Absence{absence(kind, name, e)})
+	}
+}
+
+// Forget перестаёт отслеживать метрики, для которых match возвращает true, например удалённые из хранилища.
+func (t *Tracker) Forget(match func(kind, name string) bool) {
+	t.mux.Lock()
+	defer t.mux.Unlock()
+	for key := range t.seen {
+		if key[0] != KindAgent && match(key[0], key[1]) {
+			delete(t.seen, key)
+		}
+	}
+}
+
+// Run проверяет отсутствие с интервалом interval.
+func (t *Tracker) Run(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		<-ticker.C
+		t.Check(time.Now())
+	}
+}
+
+// Check помечает отсутствующими всех, от кого не было обновлений дольше таймаута.
+func (t *Tracker) Check(now time.Time) {
+	t.mux.Lock()
+	defer t.mux.Unlock()
+	var events []models.Absence
+	for key, e := range t.seen {
+		if e.absent || now.Sub(e.lastSeen) <= t.timeout {
+			continue
+		}
+		e.absent = true
+		e.changedAt = now
+		events = append(events, absence(key[0], key[1], e))
+	}
+	sortAbsences(events)
+	t.notify(events)
+}
+
+// Absent сообщает, что метрика или агент отмечены отсутствующими.
+func (t *Tracker) Absent(kind, name string) bool {
+	t.mux.Lock()
+	defer t.mux.Unlock()
+	e, ok := t.seen[[2]string{kind, name}]
+	return ok && e.absent
+}
+
+// Absences возвращает отсутствующие метрики и агентов, упорядоченные по типу и имени.
+func (t *Tracker) Absences() models.Absences {
+	t.mux.Lock()
+	defer t.mux.Unlock()
+	ret := models.Absences{}
+	for key, e := range t.seen {
+		if e.absent {
+			ret = append(ret, absence(key[0], key[1], e))
+		}
+	}
+	sortAbsences(ret)
+	return ret
+}
+
+// notify вызывается под блокировкой и ставит события в очередь горутины send.
+func (t *Tracker) notify(events []models.Absence) {
+	if t.notifier == nil || len(events) == 0 {
+		return
+	}
+	t.queue = append(t.queue, events...)
+	select {
+	case t.wake <- struct{}{}:
+	default:
+	}
+}
+
+func absence(kind, name string, e *entry) models.Absence {
+	state := StatePresent
+	if e.absent {
+		state = StateAbsent
+	}
+	return models.Absence{LastSeen: e.lastSeen, ChangedAt: e.changedAt, Kind: kind, Name: name, State: state}
+}
+
+func sortAbsences(a []models.Absence) {
+	sort.Slice(a, func(i, j int) bool {
+		if a[i].Kind != a[j].Kind {
+			return a[i].Kind < a[j].Kind
+		}
+		return a[i].Name < a[j].Name
+	})
+}
@@ -0,0 +1,86 @@
+package absence
+
+import (
+	"testing"
+	"time"
+
+	"github.com/NikolayStrekalov/vigilant-octo-waddle.git/internal/models"
+	"github.com/mailru/easyjson"
+	"github.com/stretchr/testify/assert"
+)
+
+type chanNotifier chan models.Absence
+
+func (c chanNotifier) Send(payload easyjson.Marshaler) error {
+	if a, ok := payload.(*models.Absence); ok {
+		c <- *a
+	}
+	return nil
+}
+
+func TestTracker(t *testing.T) {
+	tr := NewTracker(30 * time.Second)
+	events := make(chanNotifier, 4)
+	tr.SetNotifier(events)
+	start := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+
+	tr.Seen("gauge", "Alloc", start)
+	tr.Seen(KindAgent, "10.0.0.1", start)
+	tr.Check(start.Add(30 * time.Second))
+	assert.Empty(t, tr.Absences())
+
+	tr.Seen("gauge", "Alloc", start.Add(20*time.Second))
+	tr.Check(start.Add(31 * time.Second))
+	assert.Equal(t, models.Absences{{
+		LastSeen:  start,
+		ChangedAt: start.Add(31 * time.Second),
+		Kind:      KindAgent,
+		Name:      "10.0.0.1",
+		State:     StateAbsent,
+	}}, tr.Absences())
+	assert.True(t, tr.Absent(KindAgent, "10.0.0.1"))
+	assert.False(t, tr.Absent("gauge", "Alloc"))
+	assert.Equal(t, StateAbsent, (<-events).State)
+
+	// повторная проверка не шлёт событие второй раз
+	tr.Check(start.Add(40 * time.Second))
+	tr.Seen(KindAgent, "10.0.0.1", start.Add(45*time.Second))
+	assert.Empty(t, tr.Absences())
+	event := <-events
+	assert.Equal(t, StatePresent, event.State)
+	assert.Equal(t, start.Add(45*time.Second), event.ChangedAt)
+	assert.Empty(t, events)
+}
+
+func TestTracker_Order(t *testing.T) {
+	tr := NewTracker(time.Second)
+	events := make(chanNotifier, 100)
+	tr.SetNotifier(events)
+	start := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+	tr.Seen("gauge", "Alloc", start)
+	for i := 1; i <= 50; i++ {
+		now := start.Add(time.Duration(i) * time.Minute)
+		tr.Check(now)
+		tr.Seen("gauge", "Alloc", now)
+	}
+	for i := 0; i < 100; i++ {
+		want := StateAbsent
+		if i%2 == 1 {
+			want = StatePresent
+		}
+		assert.Equal(t, want, (<-events).State, "event %d", i)
+	}
+}
+
+func TestTracker_Forget(t *testing.T) {
+	tr := NewTracker(time.Second)
+	start := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+	tr.Seen("gauge", "Alloc", start)
+	tr.Seen("counter", "Alloc", start)
+	tr.Seen(KindAgent, "Alloc", start)
+	tr.Check(start.Add(time.Minute))
+	tr.Forget(func(kind, name string) bool { return name == "Alloc" && kind != "counter" })
+	assert.False(t, tr.Absent("gauge", "Alloc"))
+	assert.True(t, tr.Absent("counter", "Alloc"))
+	assert.True(t, tr.Absent(KindAgent, "Alloc"), "agents are not forgotten")
+}
@@ -0,0 +1,80 @@
+package agent
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/NikolayStrekalov/vigilant-octo-waddle.git/internal/models"
+)
+
+// Ключ и номер пачки, по которым сервер отбрасывает повторно присланные пачки.
+const (
+	idempotencyKeyHeader        = "Idempotency-Key"
+	idempotencySequenceHeader   = "Idempotency-Sequence"
+	idempotencyKeyMetadata      = "idempotency-key"
+	idempotencySequenceMetadata = "idempotency-sequence"
+	// maxPendingBatches ограничивает очередь неотправленных пачек, при недоступности сервера
+	// её хватает на несколько часов.
+	maxPendingBatches = 1000
+	batchKeyBytes     = 16
+)
+
+// batch — пачка метрик с номером, под которым она повторяется до успешной отправки.
+type batch struct {
+	metrics models.MetricsSlice
+	seq     int64
+}
+
+var (
+	// BatchKey отличает пачки этого запуска агента от пачек других агентов и прошлых запусков.
+	BatchKey = newBatchKey()
+	batchSeq int64
+	// pendingBatches — неотправленные пачки со счётчиками, защищены statMutex.
+	pendingBatches []batch
+)
+
+func newBatchKey() string {
+	b := make([]byte, batchKeyBytes)
+	if _, err := rand.Read(b); err != nil {
+		return strconv.FormatInt(time.Now().UnixNano(), 16)
+	}
+	return hex.EncodeToString(b)
+}
+
+// nextSeq возвращает номер следующей пачки. Вызывается под statMutex.
+func nextSeq() int64 {
+	batchSeq++
+	return batchSeq
+}
+
+// takePending забирает неотправленные пачки. Вызывается под statMutex.
+func takePending() []batch {
+	ret := pendingBatches
+	pendingBatches = nil
+	return ret
+}
+
+// retryLater откладывает счётчики неотправленной пачки, чтобы повторить их с тем же номером:
+// если сервер пачку всё же применил, повтор не удвоит значения. Gauge не повторяются,
+// к следующей отправке они устаревают.
+func retryLater(b batch) {
+	counters := models.MetricsSlice{}
+	for i := range b.metrics {
+		if b.metrics[i].MType == string(counterKind) {
+			counters = append(counters, b.metrics[i])
+		}
+	}
+	if len(counters) == 0 {
+		return
+	}
+	statMutex.Lock()
+	defer statMutex.Unlock()
+	pendingBatches = append(pendingBatches, batch{metrics: counters, seq: b.seq})
+	if len(pendingBatches) > maxPendingBatches {
+		fmt.Println("Too many unsent batches, dropping batch", pendingBatches[0].seq)
+		pendingBatches = pendingBatches[1:]
+	}
+}
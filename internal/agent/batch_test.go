@@ -0,0 +1,34 @@
+package agent
+
+import (
+	"testing"
+
+	"github.com/NikolayStrekalov/vigilant-octo-waddle.git/internal/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_retryLater(t *testing.T) {
+	defer func() { pendingBatches = nil }()
+	value, delta := 1.5, int64(3)
+	retryLater(batch{seq: 1, metrics: models.MetricsSlice{{ID: "Alloc", MType: "gauge", Value: &value}}})
+	assert.Empty(t, pendingBatches, "batches without counters are not retried")
+
+	retryLater(batch{seq: 2, metrics: models.MetricsSlice{
+		{ID: "Alloc", MType: "gauge", Value: &value},
+		{ID: "PollCount", MType: "counter", Delta: &delta},
+	}})
+	statMutex.Lock()
+	pending := takePending()
+	statMutex.Unlock()
+	if assert.Len(t, pending, 1) {
+		assert.Equal(t, int64(2), pending[0].seq, "retry keeps the sequence number")
+		assert.Equal(t, models.MetricsSlice{{ID: "PollCount", MType: "counter", Delta: &delta}}, pending[0].metrics)
+	}
+	assert.Empty(t, pendingBatches)
+
+	for i := 0; i <= maxPendingBatches; i++ {
+		retryLater(batch{seq: int64(i), metrics: models.MetricsSlice{{ID: "PollCount", MType: "counter", Delta: &delta}}})
+	}
+	assert.Len(t, pendingBatches, maxPendingBatches)
+	assert.Equal(t, int64(1), pendingBatches[0].seq)
+}
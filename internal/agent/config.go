@@ -13,6 +13,8 @@ import (
 type Conf struct {
 	ServerAddress  string `json:"address"`
 	SignKey        string `json:"key"`
+	GRPCAddress    string `json:"grpc"`
+	AgentID        string `json:"id"`
 	PollInterval   uint   `json:"poll"`
 	ReportInterval uint   `json:"report"`
 	RateLimit      uint   `json:"limit"`
@@ -44,6 +46,8 @@ func setupConfig() {
 	flag.UintVar(&Config.PollInterval, "p", Config.PollInterval, "Частота опроса метрик в секундах, больше нуля")
 	flag.UintVar(&Config.ReportInterval, "r", Config.ReportInterval, "Частота отправки метрик в секундах, больше нуля")
 	flag.UintVar(&Config.RateLimit, "l", Config.RateLimit, "Максимальное число одновременных исходящих запросов")
+	flag.StringVar(&Config.GRPCAddress, "g", "", "Эндпоинт gRPC HOST:PORT, если задан — отправка по gRPC вместо HTTP")
+	flag.StringVar(&Config.AgentID, "id", "", "Идентификатор агента на сервере, по умолчанию имя хоста")
 	flag.Parse()
 	if len(flag.Args()) > 0 || Config.PollInterval == 0 || Config.ReportInterval == 0 {
 		flag.PrintDefaults()
@@ -72,6 +76,18 @@ func setupConfig() {
 	if envSignKey := os.Getenv("KEY"); envSignKey != "" {
 		Config.SignKey = envSignKey
 	}
+	if envGRPCAddress := os.Getenv("GRPC_ADDRESS"); envGRPCAddress != "" {
+		Config.GRPCAddress = envGRPCAddress
+	}
+	if envAgentID := os.Getenv("AGENT_ID"); envAgentID != "" {
+		Config.AgentID = envAgentID
+	}
+	if Config.AgentID == "" {
+		Config.AgentID = hostname
+	}
+	if Config.AgentID == "" {
+		Config.AgentID = BatchKey
+	}
 	if rateLimit := os.Getenv("RATE_LIMIT"); rateLimit != "" {
 		val, err := strconv.Atoi(rateLimit)
 		if err != nil || val < 0 {
@@ -84,6 +100,14 @@ func setupConfig() {
 	ReportBaseURL = fmt.Sprintf("http://%s/update/", Config.ServerAddress)
 	ReportBulkURL = fmt.Sprintf("http://%s/updates/", Config.ServerAddress)
 	RequestLimiter = semaphore.NewWeighted(int64(Config.RateLimit))
+	if Config.GRPCAddress != "" {
+		client, err := dialGRPC(Config.GRPCAddress)
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(exitCodeMisconfigured)
+		}
+		MetricsClient = client
+	}
 
 	Config.log()
 }
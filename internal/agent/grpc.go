@@ -0,0 +1,93 @@
+package agent
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/NikolayStrekalov/vigilant-octo-waddle.git/internal/models"
+	pb "github.com/NikolayStrekalov/vigilant-octo-waddle.git/internal/proto"
+	"github.com/NikolayStrekalov/vigilant-octo-waddle.git/internal/sign"
+	"github.com/avast/retry-go/v4"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+)
+
+// signatureMetadata — ключ метаданных с подписью запроса, как заголовок Hashsha256 в HTTP.
+const signatureMetadata = "hashsha256"
+
+// MetricsClient отправляет метрики по gRPC, если задан адрес сервиса.
+var MetricsClient pb.MetricsClient
+
+func dialGRPC(addr string) (pb.MetricsClient, error) {
+	conn, err := grpc.NewClient(addr, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		return nil, fmt.Errorf("grpc dial error: %w", err)
+	}
+	return pb.NewMetricsClient(conn), nil
+}
+
+func toProto(metrics models.MetricsSlice) []*pb.Metric {
+	ret := make([]*pb.Metric, 0, len(metrics))
+	for i := range metrics {
+		m := &pb.Metric{Id: metrics[i].ID, Value: metrics[i].Value, Delta: metrics[i].Delta, Labels: metrics[i].Labels}
+		switch metrics[i].MType {
+		case string(gaugeKind):
+			m.Type = pb.Metric_GAUGE
+		case string(counterKind):
+			m.Type = pb.Metric_COUNTER
+		}
+		ret = append(ret, m)
+	}
+	return ret
+}
+
+// sendStatGRPC отправляет пачку метрик вызовом BulkUpdate вместо sendStatJSON.
+// Повторяются только вызовы, не дошедшие до сервера.
+func sendStatGRPC(client pb.MetricsClient, b *batch) error {
+	req := &pb.BulkUpdateRequest{Metrics: toProto(b.metrics)}
+	ctx := metadata.AppendToOutgoingContext(context.Background(),
+		idempotencyKeyMetadata, BatchKey, idempotencySequenceMetadata, strconv.FormatInt(b.seq, 10))
+	identify(func(key, value string) { ctx = metadata.AppendToOutgoingContext(ctx, key, value) })
+	if Config.SignKey != "" {
+		data, err := proto.MarshalOptions{Deterministic: true}.Marshal(req)
+		if err != nil {
+			return fmt.Errorf("fail to serialize metrics: %w", err)
+		}
+		signature, err := sign.Sign(data, Config.SignKey)
+		if err != nil {
+			return fmt.Errorf("create sign error: %w", err)
+		}
+		ctx = metadata.AppendToOutgoingContext(ctx, signatureMetadata, signature)
+	}
+	err := retry.Do(
+		func() error {
+			if err := RequestLimiter.Acquire(ctx, 1); err != nil {
+				return fmt.Errorf("request limiter error: %w", err)
+			}
+			_, err := client.BulkUpdate(ctx, req)
+			RequestLimiter.Release(1)
+			if status.Code(err) == codes.Unavailable {
+				return fmt.Errorf("request error: %w", err)
+			}
+			if err != nil {
+				return retry.Unrecoverable(fmt.Errorf("request error: %w", err))
+			}
+			return nil
+		},
+		retry.Attempts(maxRequestAttempts),
+		retry.LastErrorOnly(true),
+		retry.DelayType(func(n uint, err error, config *retry.Config) time.Duration {
+			return time.Duration(1+n*2) * time.Second
+		}),
+	)
+	if err != nil {
+		return fmt.Errorf("grpc bulk update error: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,75 @@
+package agent
+
+import (
+	"context"
+	"net"
+	"testing"
+
+	"github.com/NikolayStrekalov/vigilant-octo-waddle.git/internal/models"
+	pb "github.com/NikolayStrekalov/vigilant-octo-waddle.git/internal/proto"
+	"github.com/NikolayStrekalov/vigilant-octo-waddle.git/internal/sign"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/sync/semaphore"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/protobuf/proto"
+)
+
+type fakeMetricsServer struct {
+	pb.UnimplementedMetricsServer
+	got       *pb.BulkUpdateRequest
+	signature string
+	sequence  string
+	agentID   string
+}
+
+func (f *fakeMetricsServer) BulkUpdate(ctx context.Context, req *pb.BulkUpdateRequest) (*pb.BulkUpdateResponse, error) {
+	f.got = req
+	md, _ := metadata.FromIncomingContext(ctx)
+	if s := md.Get(signatureMetadata); len(s) > 0 {
+		f.signature = s[0]
+	}
+	if s := md.Get(idempotencySequenceMetadata); len(s) > 0 && md.Get(idempotencyKeyMetadata)[0] == BatchKey {
+		f.sequence = s[0]
+	}
+	if s := md.Get(agentIDHeader); len(s) > 0 {
+		f.agentID = s[0]
+	}
+	return &pb.BulkUpdateResponse{}, nil
+}
+
+func Test_sendStatGRPC(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	fake := &fakeMetricsServer{}
+	s := grpc.NewServer()
+	pb.RegisterMetricsServer(s, fake)
+	go func() { _ = s.Serve(ln) }()
+	defer s.Stop()
+	client, err := dialGRPC(ln.Addr().String())
+	require.NoError(t, err)
+	RequestLimiter = semaphore.NewWeighted(1)
+	defer func(prev Conf) { Config = prev }(Config)
+	Config.SignKey = "secret"
+	Config.AgentID = "web-1"
+
+	value, delta := 1.5, int64(4)
+	err = sendStatGRPC(client, &batch{seq: 7, metrics: models.MetricsSlice{
+		{ID: "Alloc", MType: "gauge", Value: &value},
+		{ID: "PollCount", MType: "counter", Delta: &delta},
+	}})
+	require.NoError(t, err)
+	require.Len(t, fake.got.GetMetrics(), 2)
+	assert.Equal(t, pb.Metric_GAUGE, fake.got.GetMetrics()[0].GetType())
+	assert.Equal(t, 1.5, fake.got.GetMetrics()[0].GetValue())
+	assert.Equal(t, pb.Metric_COUNTER, fake.got.GetMetrics()[1].GetType())
+	assert.Equal(t, int64(4), fake.got.GetMetrics()[1].GetDelta())
+	data, err := proto.MarshalOptions{Deterministic: true}.Marshal(fake.got)
+	require.NoError(t, err)
+	want, err := sign.Sign(data, "secret")
+	require.NoError(t, err)
+	assert.Equal(t, want, fake.signature)
+	assert.Equal(t, "7", fake.sequence)
+	assert.Equal(t, "web-1", fake.agentID)
+}
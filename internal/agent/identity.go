@@ -0,0 +1,28 @@
+package agent
+
+import (
+	"os"
+	"runtime"
+)
+
+// Заголовки со сведениями об агенте, по gRPC — одноимённые метаданные.
+const (
+	agentIDHeader       = "Agent-Id"
+	agentHostnameHeader = "Agent-Hostname"
+	agentPlatformHeader = "Agent-Platform"
+	agentVersionHeader  = "Agent-Version"
+)
+
+// Version — версия агента, задаётся при сборке: -ldflags "-X <модуль>/internal/agent.Version=1.2.0".
+var Version = "dev"
+
+// hostname — имя хоста агента, по умолчанию агент называет себя им.
+var hostname, _ = os.Hostname()
+
+// identify передаёт функции set сведения об агенте для заголовков или метаданных пачки.
+func identify(set func(key, value string)) {
+	set(agentIDHeader, Config.AgentID)
+	set(agentHostnameHeader, hostname)
+	set(agentPlatformHeader, runtime.GOOS+"/"+runtime.GOARCH)
+	set(agentVersionHeader, Version)
+}
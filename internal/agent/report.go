@@ -9,6 +9,7 @@ import (
 	"net/url"
 	"reflect"
 	"runtime"
+	"strconv"
 	"time"
 
 	"github.com/NikolayStrekalov/vigilant-octo-waddle.git/internal/models"
@@ -42,7 +43,7 @@ func sendStat(kind StatKind, name StatName, value string) {
 	}
 }
 
-func sendStatJSON(m easyjson.Marshaler, toURL string) error {
+func sendStatJSON(m easyjson.Marshaler, toURL string, header http.Header) error {
 	data, err := easyjson.Marshal(m)
 	if err != nil {
 		return fmt.Errorf("fail to serialize metric: %w", err)
@@ -55,6 +56,9 @@ func sendStatJSON(m easyjson.Marshaler, toURL string) error {
 	if err != nil {
 		return fmt.Errorf("create request error: %w", err)
 	}
+	for k, v := range header {
+		req.Header[k] = v
+	}
 	req.Header.Set("Content-Type", "application/json")
 	req.Header.Set("Content-Encoding", "gzip")
 	if Config.SignKey != "" {
@@ -99,6 +103,18 @@ func sendStatJSON(m easyjson.Marshaler, toURL string) error {
 	return nil
 }
 
+// sendMetrics отправляет пачку по gRPC, если он настроен, иначе на /updates/.
+func sendMetrics(b *batch) error {
+	if MetricsClient != nil {
+		return sendStatGRPC(MetricsClient, b)
+	}
+	header := http.Header{}
+	identify(header.Set)
+	header.Set(idempotencyKeyHeader, BatchKey)
+	header.Set(idempotencySequenceHeader, strconv.FormatInt(b.seq, 10))
+	return sendStatJSON(b.metrics, ReportBulkURL, header)
+}
+
 func reportStats() {
 	var err error
 	ticker := time.NewTicker(time.Duration(Config.ReportInterval) * time.Second)
@@ -137,6 +153,8 @@ func reportStats() {
 		*pollMetrics.Delta = PollCount
 		metrics = append(metrics, pollMetrics)
 		PollCount = 0
+		pending := takePending()
+		seq := nextSeq()
 		statMutex.Unlock()
 
 		var metric models.Metrics
@@ -154,12 +172,16 @@ func reportStats() {
 		})
 
 		go func() {
-			err := sendStatJSON(metrics, ReportBulkURL)
-			if err != nil {
-				fmt.Println(err)
-				statMutex.Lock()
-				PollCount += *pollMetrics.Delta
-				statMutex.Unlock()
+			batches := append(pending, batch{metrics: metrics, seq: seq})
+			for i := range batches {
+				if err := sendMetrics(&batches[i]); err != nil {
+					fmt.Println(err)
+					// остальные пачки повторятся при следующей отправке
+					for j := i; j < len(batches); j++ {
+						retryLater(batches[j])
+					}
+					return
+				}
 			}
 		}()
 	}
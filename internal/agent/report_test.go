@@ -103,7 +103,7 @@ func Test_sendStatJSON(t *testing.T) {
 	ReportBaseURL = ts.URL
 	RequestLimiter = semaphore.NewWeighted(1)
 	for _, tt := range tests {
-		_ = sendStatJSON(tt.args.m, ReportBaseURL)
+		_ = sendStatJSON(tt.args.m, ReportBaseURL, nil)
 		assert.Equal(t, "application/json", contentTypeHeader)
 		assert.Equal(t, "gzip", contentEncodingHeader)
 
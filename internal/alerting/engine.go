@@ -0,0 +1,209 @@
+package alerting
+
+import (
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/NikolayStrekalov/vigilant-octo-waddle.git/internal/logger"
+	"github.com/NikolayStrekalov/vigilant-octo-waddle.git/internal/models"
+)
+
+// Состояния правила.
+const (
+	StateInactive = "inactive"
+	StatePending  = "pending"
+	StateFiring   = "firing"
+	StateResolved = "resolved"
+)
+
+// Source — хранилище, из которого читаются значения метрик.
+type Source interface {
+	GetGauge(string) (float64, error)
+	GetCounter(string) (int64, error)
+}
+
+// Notifier получает события о срабатывании и восстановлении правил.
+type Notifier interface {
+	Notify(*models.AlertEvent) error
+}
+
+type ruleState struct {
+	activeSince time.Time
+	changedAt   time.Time
+	prevAt      time.Time // время предыдущего чтения счётчика для rate
+	value       *float64
+	prevCounter *int64 // предыдущее значение счётчика для rate
+	state       string
+}
+
+// Engine периодически проверяет правила и отслеживает их состояния.
+type Engine struct {
+	source   Source
+	notifier Notifier
+	states   map[string]*ruleState
+	mux      *sync.Mutex
+	rules    []Rule
+}
+
+func NewEngine(source Source, rules []Rule) *Engine {
+	return &Engine{
+		source: source,
+		rules:  rules,
+		states: make(map[string]*ruleState),
+		mux:    &sync.Mutex{},
+	}
+}
+
+// SetRules заменяет набор правил, состояния неизменившихся правил сохраняются.
+func (e *Engine) SetRules(rules []Rule) {
+	e.mux.Lock()
+	defer e.mux.Unlock()
+	keep := make(map[string]*ruleState, len(rules))
+	for i := range rules {
+		key := stateKey(rules, i)
+		if st, ok := e.states[key]; ok {
+			keep[key] = st
+		}
+	}
+	e.rules = rules
+	e.states = keep
+}
+
+// stateKey определяет, по какому ключу хранится состояние i-го правила: правила из хранилища различаются
+// идентификатором, правила из файла — порядковым номером, поэтому одинаковые правила не делят состояние.
+// Запись правила тоже входит в ключ, чтобы изменённое правило начиналось с начала.
+func stateKey(rules []Rule, i int) string {
+	if rules[i].ID != 0 {
+		return "id " + strconv.FormatInt(rules[i].ID, 10) + ": " + rules[i].Key()
+	}
+	return "file " + strconv.Itoa(i) + ": " + rules[i].Key()
+}
+
+// SetNotifier задаёт получателя событий о смене состояний правил.
+func (e *Engine) SetNotifier(n Notifier) {
+	e.mux.Lock()
+	defer e.mux.Unlock()
+	e.notifier = n
+}
+
+// Run проверяет правила с интервалом interval.
+func (e *Engine) Run(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		<-ticker.C
+		e.Evaluate(time.Now())
+	}
+}
+
+// Evaluate проверяет все правила на момент now.
+func (e *Engine) Evaluate(now time.Time) {
+	e.mux.Lock()
+	defer e.mux.Unlock()
+	var events []models.AlertEvent
+	for i := range e.rules {
+		rule := &e.rules[i]
+		key := stateKey(e.rules, i)
+		st, ok := e.states[key]
+		if !ok {
+			st = &ruleState{state: StateInactive, changedAt: now}
+			e.states[key] = st
+		}
+		breached := false
+		if value, ok := e.value(rule, st, now); ok {
+			st.value = &value
+			breached = rule.breached(value)
+		} else {
+			st.value = nil
+		}
+		if st.transit(breached, rule.For, now) && (st.state == StateFiring || st.state == StateResolved) {
+			events = append(events, rule.event(st))
+		}
+	}
+	if e.notifier != nil && len(events) > 0 {
+		go notify(e.notifier, events)
+	}
+}
+
+// notify отправляет события по порядку, чтобы восстановление не обогнало срабатывание.
+func notify(n Notifier, events []models.AlertEvent) {
+	for i := range events {
+		if err := n.Notify(&events[i]); err != nil {
+			logger.Info("alert notification error:", err)
+		}
+	}
+}
+
+// value возвращает значение, с которым сравнивается порог, и false, если его нельзя вычислить.
+func (e *Engine) value(rule *Rule, st *ruleState, now time.Time) (float64, bool) {
+	if rule.Kind == gaugeKind {
+		v, err := e.source.GetGauge(rule.Name)
+		return v, err == nil
+	}
+	v, err := e.source.GetCounter(rule.Name)
+	if err != nil {
+		st.prevCounter = nil
+		return 0, false
+	}
+	if !rule.Rate {
+		return float64(v), true
+	}
+	prev, prevAt := st.prevCounter, st.prevAt
+	st.prevCounter, st.prevAt = &v, now
+	if prev == nil || !now.After(prevAt) {
+		return 0, false
+	}
+	delta := v - *prev
+	if delta < 0 {
+		// счётчик сбросили, считаем что он рос с нуля
+		delta = v
+	}
+	return float64(delta) / now.Sub(prevAt).Seconds(), true
+}
+
+// transit переводит правило в следующее состояние и сообщает, сменилось ли оно.
+func (st *ruleState) transit(breached bool, hold time.Duration, now time.Time) bool {
+	next := st.state
+	switch {
+	case breached && (st.state == StateInactive || st.state == StateResolved):
+		st.activeSince = now
+		next = StatePending
+		if hold == 0 {
+			next = StateFiring
+		}
+	case breached && st.state == StatePending && now.Sub(st.activeSince) >= hold:
+		next = StateFiring
+	case !breached && st.state == StatePending:
+		next = StateInactive
+	case !breached && st.state == StateFiring:
+		next = StateResolved
+	}
+	if next == st.state {
+		return false
+	}
+	st.state = next
+	st.changedAt = now
+	return true
+}
+
+// Statuses возвращает текущие состояния правил в порядке их объявления.
+func (e *Engine) Statuses() models.AlertStatuses {
+	e.mux.Lock()
+	defer e.mux.Unlock()
+	ret := make(models.AlertStatuses, 0, len(e.rules))
+	for i := range e.rules {
+		status := models.AlertStatus{Rule: e.rules[i].Expr, RuleID: e.rules[i].ID, State: StateInactive}
+		if st, ok := e.states[stateKey(e.rules, i)]; ok {
+			status.State = st.state
+			status.ChangedAt = st.changedAt
+			status.Value = st.value
+			if st.state == StatePending || st.state == StateFiring {
+				since := st.activeSince
+				status.ActiveSince = &since
+			}
+		}
+		ret = append(ret, status)
+	}
+	return ret
+}
@@ -0,0 +1,151 @@
+package alerting
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/NikolayStrekalov/vigilant-octo-waddle.git/internal/models"
+	"github.com/stretchr/testify/assert"
+)
+
+type fakeSource struct {
+	gauges   map[string]float64
+	counters map[string]int64
+}
+
+var errMissing = errors.New("missing")
+
+func (f *fakeSource) GetGauge(name string) (float64, error) {
+	if v, ok := f.gauges[name]; ok {
+		return v, nil
+	}
+	return 0, errMissing
+}
+
+func (f *fakeSource) GetCounter(name string) (int64, error) {
+	if v, ok := f.counters[name]; ok {
+		return v, nil
+	}
+	return 0, errMissing
+}
+
+func mustParseRules(t *testing.T, text string) []Rule {
+	t.Helper()
+	rules, err := ParseRules(text)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return rules
+}
+
+func TestEngine_GaugeStates(t *testing.T) {
+	src := &fakeSource{gauges: map[string]float64{"FreeMemory": 1 << 30}}
+	e := NewEngine(src, mustParseRules(t, "gauge FreeMemory < 500MB for 2m"))
+	start := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+
+	steps := []struct {
+		value float64
+		after time.Duration
+		want  string
+	}{
+		{value: 1 << 30, after: 0, want: StateInactive},
+		{value: 100 << 20, after: time.Minute, want: StatePending},
+		{value: 100 << 20, after: 2 * time.Minute, want: StatePending},
+		{value: 100 << 20, after: 3 * time.Minute, want: StateFiring},
+		{value: 1 << 30, after: 4 * time.Minute, want: StateResolved},
+		{value: 100 << 20, after: 5 * time.Minute, want: StatePending},
+		{value: 1 << 30, after: 6 * time.Minute, want: StateInactive},
+	}
+	for _, step := range steps {
+		src.gauges["FreeMemory"] = step.value
+		e.Evaluate(start.Add(step.after))
+		statuses := e.Statuses()
+		assert.Len(t, statuses, 1)
+		assert.Equal(t, step.want, statuses[0].State, "after %s", step.after)
+	}
+}
+
+func TestEngine_CounterRate(t *testing.T) {
+	src := &fakeSource{counters: map[string]int64{"PollCount": 10}}
+	e := NewEngine(src, mustParseRules(t, "counter PollCount rate == 0"))
+	start := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+
+	e.Evaluate(start)
+	assert.Equal(t, StateInactive, e.Statuses()[0].State)
+	assert.Nil(t, e.Statuses()[0].Value)
+
+	src.counters["PollCount"] = 30
+	e.Evaluate(start.Add(10 * time.Second))
+	status := e.Statuses()[0]
+	assert.Equal(t, StateInactive, status.State)
+	assert.Equal(t, 2.0, *status.Value)
+
+	e.Evaluate(start.Add(20 * time.Second))
+	assert.Equal(t, StateFiring, e.Statuses()[0].State)
+
+	delete(src.counters, "PollCount")
+	e.Evaluate(start.Add(30 * time.Second))
+	assert.Equal(t, StateResolved, e.Statuses()[0].State)
+}
+
+type chanNotifier chan models.AlertEvent
+
+func (c chanNotifier) Notify(event *models.AlertEvent) error {
+	c <- *event
+	return nil
+}
+
+func TestEngine_Notify(t *testing.T) {
+	src := &fakeSource{gauges: map[string]float64{"FreeMemory": 100}}
+	e := NewEngine(src, mustParseRules(t, "gauge FreeMemory < 500 for 1m"))
+	events := make(chanNotifier, 2)
+	e.SetNotifier(events)
+	start := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+
+	e.Evaluate(start)
+	e.Evaluate(start.Add(time.Minute))
+	event := <-events
+	assert.Equal(t, StateFiring, event.State)
+	assert.Equal(t, "FreeMemory", event.Name)
+	assert.Equal(t, 100.0, *event.Value)
+	assert.Equal(t, start.Add(time.Minute), event.ChangedAt)
+
+	src.gauges["FreeMemory"] = 1000
+	e.Evaluate(start.Add(2 * time.Minute))
+	event = <-events
+	assert.Equal(t, StateResolved, event.State)
+	assert.Equal(t, 1000.0, *event.Value)
+	assert.Empty(t, events)
+}
+
+func TestEngine_SameExpression(t *testing.T) {
+	src := &fakeSource{gauges: map[string]float64{"FreeMemory": 100}}
+	rule := mustParseRules(t, "gauge FreeMemory < 500 for 1m")[0]
+	first, second := rule, rule
+	first.ID, second.ID = 1, 2
+	e := NewEngine(src, []Rule{rule, first})
+	start := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+	e.Evaluate(start)
+
+	// правило с той же записью, добавленное позже, начинает со своего состояния
+	e.SetRules([]Rule{rule, first, second})
+	e.Evaluate(start.Add(time.Minute))
+	statuses := e.Statuses()
+	if assert.Len(t, statuses, 3) {
+		assert.Equal(t, StateFiring, statuses[0].State)
+		assert.Equal(t, StateFiring, statuses[1].State)
+		assert.Equal(t, StatePending, statuses[2].State)
+	}
+
+	// изменённое правило начинает заново, остальные сохраняют состояние
+	first.Threshold = 200
+	e.SetRules([]Rule{rule, first, second})
+	e.Evaluate(start.Add(2 * time.Minute))
+	statuses = e.Statuses()
+	if assert.Len(t, statuses, 3) {
+		assert.Equal(t, StateFiring, statuses[0].State)
+		assert.Equal(t, StatePending, statuses[1].State)
+		assert.Equal(t, StateFiring, statuses[2].State)
+	}
+}
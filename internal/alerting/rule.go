@@ -0,0 +1,200 @@
+package alerting
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/NikolayStrekalov/vigilant-octo-waddle.git/internal/models"
+)
+
+const (
+	gaugeKind   = "gauge"
+	counterKind = "counter"
+	rateFunc    = "rate"
+	forKeyword  = "for"
+	ruleFields  = 4 // kind, name, оператор и порог
+)
+
+var (
+	errWrongRule      = errors.New("rule format: <kind> <name> [rate] <op> <threshold> [for <duration>]")
+	errWrongKind      = errors.New("kind must be gauge or counter")
+	errRateOfGauge    = errors.New("rate is supported for counters only")
+	errWrongOperator  = errors.New("operator must be one of < <= > >= == !=")
+	errNoName         = errors.New("metric name must not be empty")
+	errWrongThreshold = errors.New("threshold must be a finite number")
+)
+
+// Множители для порогов с суффиксами размера.
+var unitMultipliers = []struct {
+	suffix     string
+	multiplier float64
+}{
+	{"KB", 1 << 10},
+	{"MB", 1 << 20},
+	{"GB", 1 << 30},
+	{"TB", 1 << 40},
+}
+
+var operators = map[string]func(a, b float64) bool{
+	"<":  func(a, b float64) bool { return a < b },
+	"<=": func(a, b float64) bool { return a <= b },
+	">":  func(a, b float64) bool { return a > b },
+	">=": func(a, b float64) bool { return a >= b },
+	"==": func(a, b float64) bool { return a == b },
+	"!=": func(a, b float64) bool { return a != b },
+}
+
+// Rule — пороговое правило над метрикой, например "gauge FreeMemory < 500MB for 2m".
+type Rule struct {
+	Expr      string        // запись правила для отображения
+	Kind      string        // gauge или counter
+	Name      string        // имя метрики
+	Op        string        // оператор сравнения
+	Threshold float64       // порог
+	For       time.Duration // сколько условие должно выполняться до срабатывания
+	ID        int64         // идентификатор правила в хранилище, 0 для правил из файла
+	Rate      bool          // сравнивать скорость роста счётчика в секунду, а не значение
+}
+
+// ParseRule разбирает правило вида "<kind> <name> [rate] <op> <threshold> [for <duration>]".
+func ParseRule(expr string) (Rule, error) {
+	fields := strings.Fields(expr)
+	r := Rule{Expr: strings.Join(fields, " ")}
+	if len(fields) >= 2 && fields[len(fields)-2] == forKeyword {
+		d, err := time.ParseDuration(fields[len(fields)-1])
+		if err != nil || d < 0 {
+			return r, fmt.Errorf("wrong duration in rule %q: %w", expr, errWrongRule)
+		}
+		r.For = d
+		fields = fields[:len(fields)-2]
+	}
+	if len(fields) == ruleFields+1 && fields[2] == rateFunc {
+		r.Rate = true
+		fields = append(fields[:2], fields[3:]...)
+	}
+	if len(fields) != ruleFields {
+		return r, fmt.Errorf("wrong rule %q: %w", expr, errWrongRule)
+	}
+	r.Kind, r.Name, r.Op = fields[0], fields[1], fields[2]
+	threshold, err := parseThreshold(fields[3])
+	if err != nil {
+		return r, fmt.Errorf("wrong threshold in rule %q: %w", expr, err)
+	}
+	r.Threshold = threshold
+	if err := r.validate(); err != nil {
+		return r, fmt.Errorf("wrong rule %q: %w", expr, err)
+	}
+	return r, nil
+}
+
+// FromModel проверяет правило, заданное через API, и приводит его к виду для проверки.
+func FromModel(m *models.AlertRule) (Rule, error) {
+	r := Rule{
+		ID:        m.ID,
+		Kind:      m.Kind,
+		Name:      m.Name,
+		Op:        m.Op,
+		Threshold: m.Threshold,
+		Rate:      m.Rate,
+	}
+	if m.For != "" {
+		d, err := time.ParseDuration(m.For)
+		if err != nil || d < 0 {
+			return r, fmt.Errorf("wrong for %q: duration like 2m expected", m.For)
+		}
+		r.For = d
+	}
+	if err := r.validate(); err != nil {
+		return r, err
+	}
+	function := ""
+	if r.Rate {
+		function = rateFunc + " "
+	}
+	r.Expr = fmt.Sprintf("%s %s %s%s %s", r.Kind, r.Name, function, r.Op, strconv.FormatFloat(r.Threshold, 'f', -1, 64))
+	if m.For != "" {
+		r.Expr += " " + forKeyword + " " + m.For
+	}
+	return r, nil
+}
+
+func (r *Rule) validate() error {
+	if r.Kind != gaugeKind && r.Kind != counterKind {
+		return errWrongKind
+	}
+	if r.Name == "" {
+		return errNoName
+	}
+	if r.Rate && r.Kind != counterKind {
+		return errRateOfGauge
+	}
+	if _, ok := operators[r.Op]; !ok {
+		return errWrongOperator
+	}
+	if math.IsNaN(r.Threshold) || math.IsInf(r.Threshold, 0) {
+		return errWrongThreshold
+	}
+	return nil
+}
+
+// ParseRules разбирает правила по одному в строке, пустые строки и строки с # пропускаются.
+func ParseRules(text string) ([]Rule, error) {
+	var rules []Rule
+	for _, line := range strings.Split(text, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		r, err := ParseRule(line)
+		if err != nil {
+			return nil, err
+		}
+		rules = append(rules, r)
+	}
+	return rules, nil
+}
+
+func parseThreshold(s string) (float64, error) {
+	multiplier := 1.0
+	for _, u := range unitMultipliers {
+		if v, ok := strings.CutSuffix(s, u.suffix); ok {
+			s, multiplier = v, u.multiplier
+			break
+		}
+	}
+	v, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return 0, fmt.Errorf("can't parse threshold %s: %w", s, err)
+	}
+	return v * multiplier, nil
+}
+
+// Key возвращает запись правила со всеми условиями, она входит в ключ состояния правила.
+func (r *Rule) Key() string {
+	function := ""
+	if r.Rate {
+		function = rateFunc + " "
+	}
+	return fmt.Sprintf("%s %s %s%s %v for %s", r.Kind, r.Name, function, r.Op, r.Threshold, r.For)
+}
+
+func (r *Rule) breached(value float64) bool {
+	return operators[r.Op](value, r.Threshold)
+}
+
+func (r *Rule) event(st *ruleState) models.AlertEvent {
+	return models.AlertEvent{
+		Value:     st.value,
+		Rule:      r.Expr,
+		Kind:      r.Kind,
+		Name:      r.Name,
+		State:     st.state,
+		Threshold: r.Threshold,
+		ChangedAt: st.changedAt,
+		RuleID:    r.ID,
+	}
+}
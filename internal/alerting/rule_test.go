@@ -0,0 +1,104 @@
+package alerting
+
+import (
+	"testing"
+	"time"
+
+	"github.com/NikolayStrekalov/vigilant-octo-waddle.git/internal/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseRule(t *testing.T) {
+	tests := []struct {
+		name    string
+		expr    string
+		want    Rule
+		wantErr bool
+	}{
+		{
+			name: "Gauge with unit and duration",
+			expr: "gauge FreeMemory < 500MB for 2m",
+			want: Rule{
+				Expr:      "gauge FreeMemory < 500MB for 2m",
+				Kind:      gaugeKind,
+				Name:      "FreeMemory",
+				Op:        "<",
+				Threshold: 500 * (1 << 20),
+				For:       2 * time.Minute,
+			},
+		},
+		{
+			name: "Counter rate",
+			expr: "counter  PollCount rate == 0 for 5m",
+			want: Rule{
+				Expr: "counter PollCount rate == 0 for 5m",
+				Kind: counterKind,
+				Name: "PollCount",
+				Op:   "==",
+				For:  5 * time.Minute,
+				Rate: true,
+			},
+		},
+		{
+			name: "Without duration",
+			expr: "gauge CPUutilization1 >= 95.5",
+			want: Rule{
+				Expr:      "gauge CPUutilization1 >= 95.5",
+				Kind:      gaugeKind,
+				Name:      "CPUutilization1",
+				Op:        ">=",
+				Threshold: 95.5,
+			},
+		},
+		{name: "Rate of gauge", expr: "gauge FreeMemory rate < 1", wantErr: true},
+		{name: "Unknown kind", expr: "bool Flag == 1", wantErr: true},
+		{name: "Unknown operator", expr: "gauge FreeMemory ~ 1", wantErr: true},
+		{name: "Wrong threshold", expr: "gauge FreeMemory < 5XB", wantErr: true},
+		{name: "Wrong duration", expr: "gauge FreeMemory < 5 for ever", wantErr: true},
+		{name: "Too short", expr: "gauge FreeMemory", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseRule(tt.expr)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.Nil(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestParseRules(t *testing.T) {
+	rules, err := ParseRules(`
+# память
+gauge FreeMemory < 500MB for 2m
+
+counter PollCount rate == 0 for 5m
+`)
+	assert.Nil(t, err)
+	assert.Len(t, rules, 2)
+
+	_, err = ParseRules("gauge FreeMemory <")
+	assert.Error(t, err)
+}
+
+func TestFromModel(t *testing.T) {
+	got, err := FromModel(&models.AlertRule{ID: 3, Kind: counterKind, Name: "PollCount", Op: "==", Rate: true, For: "5m"})
+	assert.Nil(t, err)
+	assert.Equal(t, Rule{
+		Expr: "counter PollCount rate == 0 for 5m",
+		Kind: counterKind,
+		Name: "PollCount",
+		Op:   "==",
+		For:  5 * time.Minute,
+		ID:   3,
+		Rate: true,
+	}, got)
+
+	_, err = FromModel(&models.AlertRule{Kind: gaugeKind, Name: "FreeMemory", Op: "<", For: "ever"})
+	assert.Error(t, err)
+	_, err = FromModel(&models.AlertRule{Kind: gaugeKind, Op: "<"})
+	assert.Error(t, err)
+}
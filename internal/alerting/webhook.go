@@ -0,0 +1,104 @@
+package alerting
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/NikolayStrekalov/vigilant-octo-waddle.git/internal/models"
+	"github.com/NikolayStrekalov/vigilant-octo-waddle.git/internal/sign"
+	"github.com/mailru/easyjson"
+
+	"github.com/avast/retry-go/v4"
+)
+
+const (
+	maxWebhookAttempts = 4
+	webhookTimeout     = 10 * time.Second
+)
+
+// Webhook отправляет события подписанным JSON на заданные адреса.
+type Webhook struct {
+	client *http.Client
+	key    string
+	urls   []string
+	delay  time.Duration // базовая задержка между повторами
+}
+
+func NewWebhook(urls []string, key string) *Webhook {
+	return &Webhook{
+		client: &http.Client{Timeout: webhookTimeout},
+		key:    key,
+		urls:   urls,
+		delay:  time.Second,
+	}
+}
+
+// Notify отправляет событие алертинга на все адреса.
+func (w *Webhook) Notify(event *models.AlertEvent) error {
+	return w.Send(event)
+}
+
+// Send отправляет payload на все адреса и возвращает ошибки тех, куда доставить не удалось.
+func (w *Webhook) Send(payload easyjson.Marshaler) error {
+	data, err := easyjson.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("fail to serialize webhook payload: %w", err)
+	}
+	signature := ""
+	if w.key != "" {
+		if signature, err = sign.Sign(data, w.key); err != nil {
+			return fmt.Errorf("create sign error: %w", err)
+		}
+	}
+	var errs []error
+	for _, u := range w.urls {
+		if err := w.post(u, data, signature); err != nil {
+			errs = append(errs, fmt.Errorf("webhook %s: %w", u, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func (w *Webhook) post(u string, data []byte, signature string) error {
+	err := retry.Do(
+		func() error {
+			req, err := http.NewRequest(http.MethodPost, u, bytes.NewReader(data))
+			if err != nil {
+				return retry.Unrecoverable(fmt.Errorf("create request error: %w", err))
+			}
+			req.Header.Set("Content-Type", "application/json")
+			if signature != "" {
+				req.Header.Set("HashSHA256", signature)
+			}
+			resp, err := w.client.Do(req)
+			if err != nil {
+				return fmt.Errorf("request error: %w", err)
+			}
+			defer func() {
+				_ = resp.Body.Close()
+			}()
+			_, _ = io.Copy(io.Discard, resp.Body)
+			switch {
+			case resp.StatusCode >= http.StatusInternalServerError:
+				return fmt.Errorf("wrong response code: %d", resp.StatusCode)
+			case resp.StatusCode >= http.StatusBadRequest:
+				// клиентская ошибка не исправится повтором
+				return retry.Unrecoverable(fmt.Errorf("wrong response code: %d", resp.StatusCode))
+			}
+			return nil
+		},
+		retry.Attempts(maxWebhookAttempts),
+		retry.LastErrorOnly(true),
+		retry.DelayType(func(n uint, err error, config *retry.Config) time.Duration {
+			return time.Duration(1+n*2) * w.delay
+		}),
+	)
+	if err != nil {
+		return fmt.Errorf("post error: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,71 @@
+package alerting
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/NikolayStrekalov/vigilant-octo-waddle.git/internal/models"
+	"github.com/NikolayStrekalov/vigilant-octo-waddle.git/internal/sign"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWebhook_Notify(t *testing.T) {
+	value := 100.0
+	event := models.AlertEvent{
+		Value:     &value,
+		Rule:      "gauge FreeMemory < 500",
+		Kind:      gaugeKind,
+		Name:      "FreeMemory",
+		State:     StateFiring,
+		Threshold: 500,
+		ChangedAt: time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC),
+	}
+	want := `{"value":100,"rule":"gauge FreeMemory < 500","type":"gauge","name":"FreeMemory",` +
+		`"state":"firing","threshold":500,"changedAt":"2024-06-01T12:00:00Z"}`
+
+	tests := []struct {
+		name      string
+		codes     []int
+		wantCalls int32
+		wantErr   bool
+	}{
+		{name: "Delivered", codes: []int{http.StatusOK}, wantCalls: 1},
+		{name: "Retried", codes: []int{http.StatusBadGateway, http.StatusOK}, wantCalls: 2},
+		{name: "Rejected", codes: []int{http.StatusBadRequest}, wantCalls: 1, wantErr: true},
+		{
+			name:      "Gave up",
+			codes:     []int{http.StatusBadGateway, http.StatusBadGateway, http.StatusBadGateway, http.StatusBadGateway},
+			wantCalls: maxWebhookAttempts,
+			wantErr:   true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var calls atomic.Int32
+			ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				n := calls.Add(1)
+				body, err := io.ReadAll(r.Body)
+				assert.Nil(t, err)
+				assert.JSONEq(t, want, string(body))
+				signature, err := sign.Sign(body, "secret")
+				assert.Nil(t, err)
+				assert.Equal(t, signature, r.Header.Get("HashSHA256"))
+				w.WriteHeader(tt.codes[n-1])
+			}))
+			defer ts.Close()
+			hook := NewWebhook([]string{ts.URL}, "secret")
+			hook.delay = time.Millisecond
+			err := hook.Notify(&event)
+			if tt.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.Nil(t, err)
+			}
+			assert.Equal(t, tt.wantCalls, calls.Load())
+		})
+	}
+}
@@ -0,0 +1,155 @@
+package anomaly
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/NikolayStrekalov/vigilant-octo-waddle.git/internal/models"
+)
+
+const (
+	// DefaultSigma — порог в сигмах для метрик, не попавших ни под один шаблон.
+	DefaultSigma = 3.0
+	// alpha — вес нового значения в скользящих среднем и дисперсии.
+	alpha = 0.1
+	// warmup — сколько значений накопить, прежде чем искать аномалии.
+	warmup = 10
+)
+
+var errWrongSigma = errors.New("sigma must be a positive number")
+
+// Sensitivity задаёт порог в сигмах для метрик, имена которых подходят под шаблон.
+type Sensitivity struct {
+	Pattern string
+	Sigma   float64
+}
+
+// ParseSensitivity разбирает список вида "HeapAlloc=4,CPUutilization*=2.5".
+func ParseSensitivity(s string) ([]Sensitivity, error) {
+	var ret []Sensitivity
+	if strings.TrimSpace(s) == "" {
+		return ret, nil
+	}
+	for _, part := range strings.Split(s, ",") {
+		pattern, sigma, ok := strings.Cut(strings.TrimSpace(part), "=")
+		if !ok || pattern == "" {
+			return ret, fmt.Errorf("wrong sensitivity item %q, expected pattern=sigma", part)
+		}
+		if _, err := path.Match(pattern, ""); err != nil {
+			return ret, fmt.Errorf("wrong pattern %q: %w", pattern, err)
+		}
+		k, err := strconv.ParseFloat(sigma, 64)
+		if err != nil || k <= 0 || math.IsInf(k, 0) {
+			return ret, fmt.Errorf("wrong sensitivity item %q: %w", part, errWrongSigma)
+		}
+		ret = append(ret, Sensitivity{Pattern: pattern, Sigma: k})
+	}
+	return ret, nil
+}
+
+type baseline struct {
+	mean     float64
+	variance float64
+	count    int
+}
+
+// Detector ведёт скользящие EWMA-среднее и дисперсию по каждому gauge
+// и помечает значения, отстоящие от среднего больше чем на k сигм.
+type Detector struct {
+	baselines   map[string]*baseline
+	anomalies   map[string]models.Anomaly
+	mux         *sync.Mutex
+	sensitivity []Sensitivity
+}
+
+func NewDetector(sensitivity []Sensitivity) *Detector {
+	return &Detector{
+		baselines:   make(map[string]*baseline),
+		anomalies:   make(map[string]models.Anomaly),
+		mux:         &sync.Mutex{},
+		sensitivity: sensitivity,
+	}
+}
+
+// sigma возвращает порог для метрики, выигрывает первый подошедший шаблон.
+func (d *Detector) sigma(name string) float64 {
+	for _, s := range d.sensitivity {
+		if ok, _ := path.Match(s.Pattern, name); ok {
+			return s.Sigma
+		}
+	}
+	return DefaultSigma
+}
+
+// Observe учитывает новое значение gauge и сообщает, аномально ли оно.
+func (d *Detector) Observe(name string, value float64, now time.Time) bool {
+	if math.IsNaN(value) || math.IsInf(value, 0) {
+		return false
+	}
+	d.mux.Lock()
+	defer d.mux.Unlock()
+	b, ok := d.baselines[name]
+	if !ok {
+		d.baselines[name] = &baseline{mean: value, count: 1}
+		return false
+	}
+	// значение сравнивается с базой, построенной до него
+	std := math.Sqrt(b.variance)
+	k := d.sigma(name)
+	anomalous := false
+	if b.count >= warmup && std > 0 {
+		score := (value - b.mean) / std
+		if math.Abs(score) > k {
+			anomalous = true
+			d.anomalies[name] = models.Anomaly{
+				DetectedAt: now,
+				Name:       name,
+				Value:      value,
+				Mean:       b.mean,
+				StdDev:     std,
+				Score:      score,
+				Sigma:      k,
+			}
+		}
+	}
+	if !anomalous {
+		delete(d.anomalies, name)
+	}
+	diff := value - b.mean
+	incr := alpha * diff
+	b.mean += incr
+	b.variance = (1 - alpha) * (b.variance + diff*incr)
+	b.count++
+	return anomalous
+}
+
+// Forget сбрасывает базу и аномалии gauge, для которых match возвращает true.
+func (d *Detector) Forget(match func(name string) bool) {
+	d.mux.Lock()
+	defer d.mux.Unlock()
+	for name := range d.baselines {
+		if match(name) {
+			delete(d.baselines, name)
+			delete(d.anomalies, name)
+		}
+	}
+}
+
+// Anomalies возвращает gauge, последнее значение которых аномально, по имени.
+func (d *Detector) Anomalies() models.Anomalies {
+	d.mux.Lock()
+	defer d.mux.Unlock()
+	ret := make(models.Anomalies, 0, len(d.anomalies))
+	for _, a := range d.anomalies {
+		ret = append(ret, a)
+	}
+	sort.Slice(ret, func(i, j int) bool { return ret[i].Name < ret[j].Name })
+	return ret
+}
@@ -0,0 +1,63 @@
+package anomaly
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseSensitivity(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		want    []Sensitivity
+		wantErr bool
+	}{
+		{name: "Empty", input: ""},
+		{
+			name:  "Patterns",
+			input: "HeapAlloc=4, CPUutilization*=2.5",
+			want:  []Sensitivity{{Pattern: "HeapAlloc", Sigma: 4}, {Pattern: "CPUutilization*", Sigma: 2.5}},
+		},
+		{name: "No sigma", input: "HeapAlloc", wantErr: true},
+		{name: "Wrong sigma", input: "HeapAlloc=-1", wantErr: true},
+		{name: "Wrong pattern", input: "Heap[=2", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseSensitivity(tt.input)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.Nil(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestDetector(t *testing.T) {
+	d := NewDetector([]Sensitivity{{Pattern: "Random*", Sigma: 100}})
+	now := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+	for i := 0; i < 30; i++ {
+		value := 100.0 + float64(i%2)
+		assert.False(t, d.Observe("HeapAlloc", value, now))
+		assert.False(t, d.Observe("RandomValue", value, now))
+	}
+	assert.Empty(t, d.Anomalies())
+
+	assert.True(t, d.Observe("HeapAlloc", 120, now))
+	assert.False(t, d.Observe("RandomValue", 120, now))
+	anomalies := d.Anomalies()
+	if assert.Len(t, anomalies, 1) {
+		assert.Equal(t, "HeapAlloc", anomalies[0].Name)
+		assert.Equal(t, 120.0, anomalies[0].Value)
+		assert.Equal(t, DefaultSigma, anomalies[0].Sigma)
+		assert.Greater(t, anomalies[0].Score, DefaultSigma)
+		assert.Equal(t, now, anomalies[0].DetectedAt)
+	}
+
+	assert.False(t, d.Observe("HeapAlloc", 102, now))
+	assert.Empty(t, d.Anomalies())
+}
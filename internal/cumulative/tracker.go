@@ -0,0 +1,89 @@
+// Package cumulative хранит между запросами состояние накопительных серий, которое не хранит сервер.
+package cumulative
+
+import (
+	"math"
+	"sync"
+	"time"
+)
+
+// Tracker запоминает время начала накопительных серий OTLP и дробные остатки дельта-сумм.
+// Сами накопительные значения хранит хранилище через Total, поэтому потеря записи трекера
+// при перезапуске или вытеснении не удваивает счётчик. Записи, не обновлявшиеся дольше ttl, удаляются.
+type Tracker struct {
+	swept  time.Time
+	series map[string]*state
+	mux    *sync.Mutex
+	ttl    time.Duration
+}
+
+type state struct {
+	seen     time.Time
+	fraction float64
+	start    uint64
+}
+
+func NewTracker(ttl time.Duration) *Tracker {
+	return &Tracker{
+		series: make(map[string]*state),
+		mux:    &sync.Mutex{},
+		ttl:    ttl,
+	}
+}
+
+// Restarted запоминает время начала серии key и сообщает, что серия началась позже уже известного
+// начала, то есть источник сбросил счётчик. Нулевое время начала не учитывается.
+func (t *Tracker) Restarted(key string, start uint64, now time.Time) bool {
+	if start == 0 {
+		return false
+	}
+	t.mux.Lock()
+	defer t.mux.Unlock()
+	s := t.get(key, now)
+	restarted := s.start != 0 && start > s.start
+	if start > s.start {
+		s.start = start
+	}
+	return restarted
+}
+
+// Add прибавляет к серии key приращение inc и возвращает целое приращение,
+// дробные части накапливаются между вызовами.
+func (t *Tracker) Add(key string, inc float64, now time.Time) int64 {
+	if math.IsNaN(inc) || math.IsInf(inc, 0) || inc < 0 {
+		return 0
+	}
+	t.mux.Lock()
+	defer t.mux.Unlock()
+	s := t.get(key, now)
+	whole := math.Floor(s.fraction + inc)
+	s.fraction += inc - whole
+	return int64(whole)
+}
+
+// Len возвращает число отслеживаемых серий.
+func (t *Tracker) Len() int {
+	t.mux.Lock()
+	defer t.mux.Unlock()
+	return len(t.series)
+}
+
+// get возвращает запись серии, заодно не чаще раза в ttl удаляет устаревшие записи.
+// Вызывается под блокировкой.
+func (t *Tracker) get(key string, now time.Time) *state {
+	if now.Sub(t.swept) >= t.ttl {
+		t.swept = now
+		for k, s := range t.series {
+			if now.Sub(s.seen) > t.ttl {
+				delete(t.series, k)
+			}
+		}
+	}
+	s, ok := t.series[key]
+	if !ok {
+		s = &state{}
+		t.series[key] = s
+	}
+	s.seen = now
+	return s
+}
@@ -0,0 +1,50 @@
+package cumulative
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTracker_Restarted(t *testing.T) {
+	tr := NewTracker(time.Hour)
+	now := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+	assert.False(t, tr.Restarted("a", 100, now), "the first start is not a restart")
+	assert.False(t, tr.Restarted("a", 100, now))
+	assert.False(t, tr.Restarted("a", 0, now), "zero start is ignored")
+	assert.True(t, tr.Restarted("a", 200, now))
+	assert.False(t, tr.Restarted("a", 150, now), "an older start does not move back")
+	assert.False(t, tr.Restarted("b", 200, now))
+}
+
+func TestTracker_Add(t *testing.T) {
+	tr := NewTracker(time.Hour)
+	now := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+	steps := []struct {
+		inc  float64
+		want int64
+	}{
+		{inc: 0.4, want: 0},
+		{inc: 0.8, want: 1},
+		{inc: 2.9, want: 3},
+		{inc: math.NaN(), want: 0},
+		{inc: -1, want: 0},
+		{inc: 0.9, want: 1},
+	}
+	for _, s := range steps {
+		assert.Equal(t, s.want, tr.Add("a", s.inc, now), "inc=%v", s.inc)
+	}
+}
+
+func TestTracker_Expiry(t *testing.T) {
+	tr := NewTracker(time.Minute)
+	now := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+	tr.Restarted("old", 100, now)
+	tr.Restarted("fresh", 100, now.Add(50*time.Second))
+	assert.Equal(t, 2, tr.Len())
+	tr.Restarted("fresh", 100, now.Add(90*time.Second))
+	assert.Equal(t, 1, tr.Len(), "series not seen for ttl are forgotten")
+	assert.False(t, tr.Restarted("old", 200, now.Add(90*time.Second)), "a forgotten series starts over")
+}
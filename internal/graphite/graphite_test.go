@@ -0,0 +1,123 @@
+package graphite
+
+import (
+	"net"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/NikolayStrekalov/vigilant-octo-waddle.git/internal/logger"
+	"github.com/NikolayStrekalov/vigilant-octo-waddle.git/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseLine(t *testing.T) {
+	tests := []struct {
+		name    string
+		line    string
+		want    Line
+		wantErr bool
+	}{
+		{name: "Plain", line: "jobs.backup.duration 12.5 1717243200", want: Line{Path: "jobs.backup.duration", Value: 12.5}},
+		{name: "Extra spaces", line: "  load   -1  1717243200 ", want: Line{Path: "load", Value: -1}},
+		{
+			name: "Tagged",
+			line: "disk.used;host=db1;mount.point=/var 42 1717243200",
+			want: Line{Path: "disk.used", Value: 42, Tags: map[string]string{"host": "db1", "mount_point": "/var"}},
+		},
+		{name: "No timestamp", line: "load 1", wantErr: true},
+		{name: "Wrong value", line: "load x 1717243200", wantErr: true},
+		{name: "NaN value", line: "load nan 1717243200", wantErr: true},
+		{name: "Wrong timestamp", line: "load 1 now", wantErr: true},
+		{name: "Wrong tag", line: "load;host 1 1717243200", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseLine(tt.line)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.Nil(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestMapper(t *testing.T) {
+	rules, err := ParseMapping("servers.*.cpu=CPU_$1, jobs.*.*=$2_$1,jobs.backup.size=BackupSize")
+	require.NoError(t, err)
+	m := Mapper(rules)
+	tests := []struct {
+		path string
+		want string
+	}{
+		{path: "servers.db1.cpu", want: "CPU_db1"},
+		{path: "jobs.backup.size", want: "size_backup"},
+		{path: "servers.db1.memory", want: "servers.db1.memory"},
+		{path: "servers.db1.cpu.user", want: "servers.db1.cpu.user"},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, m.Name(tt.path), tt.path)
+	}
+
+	_, err = ParseMapping("servers.*.cpu")
+	assert.Error(t, err)
+	rules, err = ParseMapping("")
+	assert.Nil(t, err)
+	assert.Empty(t, rules)
+}
+
+type fakeStorage struct {
+	mux    sync.Mutex
+	gauges map[string]float64
+}
+
+func (f *fakeStorage) BulkUpdate(metrics models.MetricsSlice) {
+	f.mux.Lock()
+	defer f.mux.Unlock()
+	for _, m := range metrics {
+		f.gauges[m.ID] = *m.Value
+	}
+}
+
+func (f *fakeStorage) get(name string) (float64, bool) {
+	f.mux.Lock()
+	defer f.mux.Unlock()
+	v, ok := f.gauges[name]
+	return v, ok
+}
+
+func TestListener(t *testing.T) {
+	_ = logger.InitLog()
+	rules, err := ParseMapping("jobs.*.duration=$1Duration")
+	require.NoError(t, err)
+	l, err := Listen("127.0.0.1:0", rules)
+	require.NoError(t, err)
+	storage := &fakeStorage{gauges: map[string]float64{}}
+	go func() { _ = l.Serve(storage) }()
+	defer func() { _ = l.Close() }()
+
+	conn, err := net.Dial("tcp", l.Addr().String())
+	require.NoError(t, err)
+	defer func() { _ = conn.Close() }()
+	_, err = conn.Write([]byte("jobs.backup.duration 12.5 1717243200\nbroken\nqueue.size 4"))
+	require.NoError(t, err)
+	// после паузы соединения принятое записывается, не дожидаясь его закрытия
+	assert.Eventually(t, func() bool {
+		v, ok := storage.get("backupDuration")
+		return ok && v == 12.5
+	}, 3*time.Second, 10*time.Millisecond)
+	_, ok := storage.get("queue.size")
+	assert.False(t, ok)
+
+	// хвост строки, пришедший после паузы, дописывается к её началу
+	_, err = conn.Write([]byte("2 1717243200\n"))
+	require.NoError(t, err)
+	require.NoError(t, conn.Close())
+	assert.Eventually(t, func() bool {
+		v, ok := storage.get("queue.size")
+		return ok && v == 42
+	}, 3*time.Second, 10*time.Millisecond)
+}
@@ -0,0 +1,161 @@
+package graphite
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"math"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/NikolayStrekalov/vigilant-octo-waddle.git/internal/logger"
+	"github.com/NikolayStrekalov/vigilant-octo-waddle.git/internal/models"
+	"github.com/NikolayStrekalov/vigilant-octo-waddle.git/internal/series"
+)
+
+const (
+	// maxBatch — сколько строк копить перед записью в хранилище.
+	maxBatch = 1000
+	// flushTimeout — через сколько молчания соединения записать накопленное.
+	flushTimeout = time.Second
+	lineFields   = 3
+)
+
+var errWrongLine = errors.New("line format: <path>[;tag=value...] <value> <timestamp>")
+
+// Storage — хранилище, в которое пишутся полученные значения.
+type Storage interface {
+	BulkUpdate(models.MetricsSlice)
+}
+
+// Line — разобранная строка Graphite.
+type Line struct {
+	Tags  map[string]string
+	Path  string
+	Value float64
+}
+
+// ParseLine разбирает строку "path value timestamp". Теги в формате path;tag=value;... становятся метками.
+// Отметка времени проверяется, но не сохраняется: значения записываются как текущие.
+func ParseLine(s string) (Line, error) {
+	var l Line
+	fields := strings.Fields(s)
+	if len(fields) != lineFields {
+		return l, fmt.Errorf("wrong line %q: %w", s, errWrongLine)
+	}
+	value, err := strconv.ParseFloat(fields[1], 64)
+	if err != nil || math.IsNaN(value) {
+		return l, fmt.Errorf("wrong value in line %q: %w", s, errWrongLine)
+	}
+	if _, err := strconv.ParseFloat(fields[2], 64); err != nil {
+		return l, fmt.Errorf("wrong timestamp in line %q: %w", s, errWrongLine)
+	}
+	parts := strings.Split(fields[0], ";")
+	l.Path, l.Value = parts[0], value
+	for _, tag := range parts[1:] {
+		k, v, ok := strings.Cut(tag, "=")
+		if !ok || k == "" || v == "" {
+			return l, fmt.Errorf("wrong tag in line %q: %w", s, errWrongLine)
+		}
+		if l.Tags == nil {
+			l.Tags = make(map[string]string, len(parts)-1)
+		}
+		l.Tags[series.Sanitize(k)] = v
+	}
+	return l, nil
+}
+
+// Listener принимает TCP-соединения и пишет каждую строку как gauge.
+type Listener struct {
+	ln     net.Listener
+	mapper Mapper
+}
+
+func Listen(addr string, rules []Rule) (*Listener, error) {
+	ln, err := net.Listen("tcp", addr)
+	if err != nil {
+		return nil, fmt.Errorf("graphite listen error: %w", err)
+	}
+	return &Listener{ln: ln, mapper: rules}, nil
+}
+
+// Addr возвращает адрес, на котором слушает сокет.
+func (l *Listener) Addr() net.Addr {
+	return l.ln.Addr()
+}
+
+// Serve принимает соединения до закрытия слушателя.
+func (l *Listener) Serve(storage Storage) error {
+	for {
+		conn, err := l.ln.Accept()
+		if errors.Is(err, net.ErrClosed) {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("graphite accept error: %w", err)
+		}
+		go l.handle(conn, storage)
+	}
+}
+
+func (l *Listener) Close() error {
+	if err := l.ln.Close(); err != nil {
+		return fmt.Errorf("graphite close error: %w", err)
+	}
+	return nil
+}
+
+// handle читает строки соединения и пишет их пачками: при заполнении пачки,
+// после секунды молчания и при закрытии соединения.
+func (l *Listener) handle(conn net.Conn, storage Storage) {
+	defer func() { _ = conn.Close() }()
+	batch := models.MetricsSlice{}
+	flush := func() {
+		if len(batch) > 0 {
+			storage.BulkUpdate(batch)
+			batch = models.MetricsSlice{}
+		}
+	}
+	defer flush()
+	reader := bufio.NewReader(conn)
+	pending := ""
+	for {
+		if err := conn.SetReadDeadline(time.Now().Add(flushTimeout)); err != nil {
+			return
+		}
+		s, err := reader.ReadString('\n')
+		s, pending = pending+s, ""
+		if errors.Is(err, os.ErrDeadlineExceeded) {
+			// недочитанная часть строки продолжится при следующем чтении
+			pending = s
+			flush()
+			continue
+		}
+		if s = strings.TrimSpace(s); s != "" {
+			l.add(&batch, s)
+		}
+		if err != nil {
+			return
+		}
+		if len(batch) >= maxBatch {
+			flush()
+		}
+	}
+}
+
+func (l *Listener) add(batch *models.MetricsSlice, s string) {
+	line, err := ParseLine(s)
+	if err != nil {
+		logger.Info("graphite:", err)
+		return
+	}
+	*batch = append(*batch, models.Metrics{
+		ID:     l.mapper.Name(line.Path),
+		Labels: line.Tags,
+		MType:  "gauge",
+		Value:  &line.Value,
+	})
+}
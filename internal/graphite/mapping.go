@@ -0,0 +1,72 @@
+// Package graphite принимает метрики по текстовому протоколу Graphite (path value timestamp).
+package graphite
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+const wildcard = "*"
+
+var errWrongRule = errors.New("mapping rule format: <path pattern>=<name template>, e.g. servers.*.cpu=cpu_$1")
+
+// Rule переименовывает пути, подходящие под шаблон. Сегмент * совпадает с любым сегментом пути,
+// в имени $1, $2... заменяются на совпавшие сегменты по порядку.
+type Rule struct {
+	Template string
+	Pattern  []string
+}
+
+// ParseMapping разбирает правила вида "servers.*.cpu=cpu_$1,jobs.*.duration=$1Duration".
+func ParseMapping(s string) ([]Rule, error) {
+	var rules []Rule
+	if strings.TrimSpace(s) == "" {
+		return rules, nil
+	}
+	for _, part := range strings.Split(s, ",") {
+		pattern, template, ok := strings.Cut(strings.TrimSpace(part), "=")
+		if !ok || pattern == "" || template == "" {
+			return rules, fmt.Errorf("wrong mapping rule %q: %w", part, errWrongRule)
+		}
+		rules = append(rules, Rule{Pattern: strings.Split(pattern, "."), Template: template})
+	}
+	return rules, nil
+}
+
+// Mapper выбирает имя метрики по первому подошедшему правилу, без совпадений путь остаётся именем.
+type Mapper []Rule
+
+func (m Mapper) Name(path string) string {
+	segments := strings.Split(path, ".")
+	for _, r := range m {
+		captured, ok := r.match(segments)
+		if !ok {
+			continue
+		}
+		name := r.Template
+		// с конца, чтобы $1 не заменил начало $10
+		for i := len(captured); i > 0; i-- {
+			name = strings.ReplaceAll(name, "$"+strconv.Itoa(i), captured[i-1])
+		}
+		return name
+	}
+	return path
+}
+
+func (r *Rule) match(segments []string) ([]string, bool) {
+	if len(segments) != len(r.Pattern) {
+		return nil, false
+	}
+	var captured []string
+	for i, p := range r.Pattern {
+		switch {
+		case p == wildcard:
+			captured = append(captured, segments[i])
+		case p != segments[i]:
+			return nil, false
+		}
+	}
+	return captured, true
+}
@@ -0,0 +1,144 @@
+// Package histogram накапливает распределения наблюдений по корзинам с заданными границами.
+//
+// Агенты передают приращения гистограмм, сервер складывает их по корзинам. Гистограммы
+// с разными границами сложить нельзя, поэтому смена границ сбрасывает накопленные данные.
+package histogram
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/NikolayStrekalov/vigilant-octo-waddle.git/internal/models"
+)
+
+var (
+	errWrongBounds = errors.New("bounds must be finite and strictly increasing")
+	errWrongCounts = errors.New("counts must have one more element than bounds")
+	errWrongCount  = errors.New("count must equal the sum of counts")
+	errWrongSum    = errors.New("sum must be finite")
+
+	// ErrBoundsMismatch возвращается при слиянии гистограмм с разными границами корзин.
+	ErrBoundsMismatch = errors.New("bounds differ from the stored histogram")
+)
+
+// DefaultBounds — границы корзин по умолчанию, как в клиентах Prometheus.
+var DefaultBounds = []float64{0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10}
+
+// New возвращает пустую гистограмму с границами bounds.
+func New(bounds []float64) models.Histogram {
+	return models.Histogram{
+		Bounds: append([]float64{}, bounds...),
+		Counts: make([]uint64, len(bounds)+1),
+	}
+}
+
+// ParseBounds разбирает границы корзин вида 0.1,0.5,1.
+func ParseBounds(s string) ([]float64, error) {
+	var bounds []float64
+	for _, part := range strings.Split(s, ",") {
+		v, err := strconv.ParseFloat(strings.TrimSpace(part), 64)
+		if err != nil {
+			return nil, fmt.Errorf("bound %q: %w", part, errWrongBounds)
+		}
+		bounds = append(bounds, v)
+	}
+	if err := validBounds(bounds); err != nil {
+		return nil, err
+	}
+	return bounds, nil
+}
+
+func validBounds(bounds []float64) error {
+	for i, b := range bounds {
+		if math.IsNaN(b) || math.IsInf(b, 0) || (i > 0 && b <= bounds[i-1]) {
+			return errWrongBounds
+		}
+	}
+	return nil
+}
+
+// Validate проверяет гистограмму, полученную от клиента.
+func Validate(h *models.Histogram) error {
+	if err := validBounds(h.Bounds); err != nil {
+		return err
+	}
+	if len(h.Counts) != len(h.Bounds)+1 {
+		return errWrongCounts
+	}
+	var total uint64
+	for _, c := range h.Counts {
+		total += c
+	}
+	if total != h.Count {
+		return errWrongCount
+	}
+	if math.IsNaN(h.Sum) || math.IsInf(h.Sum, 0) {
+		return errWrongSum
+	}
+	return nil
+}
+
+// Observe добавляет наблюдение в первую корзину, граница которой не меньше value.
+func Observe(h *models.Histogram, value float64) {
+	h.Counts[sort.SearchFloat64s(h.Bounds, value)]++
+	h.Sum += value
+	h.Count++
+}
+
+// Merge прибавляет к dst приращение delta, пустая dst становится копией delta.
+// Если границы различаются, возвращается ErrBoundsMismatch, а dst не меняется.
+func Merge(dst, delta *models.Histogram) error {
+	if len(dst.Counts) == 0 {
+		*dst = models.Histogram{
+			Bounds: append([]float64{}, delta.Bounds...),
+			Counts: append([]uint64{}, delta.Counts...),
+			Sum:    delta.Sum,
+			Count:  delta.Count,
+		}
+		return nil
+	}
+	if err := Compatible(dst, delta); err != nil {
+		return err
+	}
+	for i := range delta.Counts {
+		dst.Counts[i] += delta.Counts[i]
+	}
+	dst.Sum += delta.Sum
+	dst.Count += delta.Count
+	return nil
+}
+
+// Compatible проверяет, что delta можно прибавить к dst: границы корзин должны совпадать.
+func Compatible(dst, delta *models.Histogram) error {
+	if !sameBounds(dst.Bounds, delta.Bounds) || len(dst.Counts) != len(delta.Counts) {
+		return ErrBoundsMismatch
+	}
+	return nil
+}
+
+func sameBounds(a, b []float64) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// Cumulative возвращает накопленные числа наблюдений не выше каждой границы, последнее — для +Inf.
+func Cumulative(h *models.Histogram) []uint64 {
+	ret := make([]uint64, len(h.Counts))
+	var total uint64
+	for i, c := range h.Counts {
+		total += c
+		ret[i] = total
+	}
+	return ret
+}
@@ -0,0 +1,90 @@
+package histogram
+
+import (
+	"math"
+	"testing"
+
+	"github.com/NikolayStrekalov/vigilant-octo-waddle.git/internal/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseBounds(t *testing.T) {
+	tests := []struct {
+		name    string
+		value   string
+		want    []float64
+		wantErr bool
+	}{
+		{name: "Valid", value: "0.1, 0.5,1", want: []float64{0.1, 0.5, 1}},
+		{name: "Negative", value: "-1,0", want: []float64{-1, 0}},
+		{name: "Not increasing", value: "1,1", wantErr: true},
+		{name: "Infinite", value: "1,+Inf", wantErr: true},
+		{name: "Not a number", value: "1,x", wantErr: true},
+		{name: "Empty", value: "", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseBounds(tt.value)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.Nil(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		h       models.Histogram
+		wantErr bool
+	}{
+		{name: "Valid", h: models.Histogram{Bounds: []float64{1, 2}, Counts: []uint64{1, 0, 2}, Sum: 7, Count: 3}},
+		{name: "No bounds", h: models.Histogram{Counts: []uint64{2}, Sum: 7, Count: 2}},
+		{name: "Wrong counts", h: models.Histogram{Bounds: []float64{1, 2}, Counts: []uint64{1, 2}, Count: 3}, wantErr: true},
+		{name: "Wrong count", h: models.Histogram{Bounds: []float64{1}, Counts: []uint64{1, 2}, Count: 4}, wantErr: true},
+		{name: "Wrong bounds", h: models.Histogram{Bounds: []float64{2, 1}, Counts: []uint64{0, 0, 0}}, wantErr: true},
+		{name: "NaN sum", h: models.Histogram{Counts: []uint64{0}, Sum: math.NaN()}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := Validate(&tt.h)
+			if tt.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestObserveMerge(t *testing.T) {
+	h := New([]float64{0.1, 1})
+	for _, v := range []float64{0.05, 0.1, 0.5, 3} {
+		Observe(&h, v)
+	}
+	assert.Equal(t, []uint64{2, 1, 1}, h.Counts)
+	assert.Equal(t, uint64(4), h.Count)
+	assert.InDelta(t, 3.65, h.Sum, 1e-9)
+	assert.Equal(t, []uint64{2, 3, 4}, Cumulative(&h))
+
+	delta := New([]float64{0.1, 1})
+	Observe(&delta, 0.2)
+	assert.Nil(t, Merge(&h, &delta))
+	assert.Equal(t, []uint64{2, 2, 1}, h.Counts)
+	assert.Equal(t, uint64(5), h.Count)
+
+	other := New([]float64{5})
+	Observe(&other, 2)
+	assert.ErrorIs(t, Merge(&h, &other), ErrBoundsMismatch)
+	assert.Equal(t, []uint64{2, 2, 1}, h.Counts, "stored histogram is kept on mismatch")
+	assert.Equal(t, uint64(5), h.Count)
+
+	empty := models.Histogram{}
+	assert.Nil(t, Merge(&empty, &other))
+	assert.Equal(t, models.Histogram{Bounds: []float64{5}, Counts: []uint64{1, 0}, Sum: 2, Count: 1}, empty)
+	Observe(&other, 2)
+	assert.Equal(t, uint64(1), empty.Count, "merged histogram must not share memory with delta")
+}
@@ -0,0 +1,39 @@
+package history
+
+import (
+	"sort"
+	"time"
+
+	"github.com/NikolayStrekalov/vigilant-octo-waddle.git/internal/models"
+)
+
+// Window возвращает сырые сэмплы из (from, to] и перед ними последний сэмпл не позже from,
+// от которого считается прирост за окно.
+func (s *Series) Window(from, to time.Time) []models.HistoryPoint {
+	i := sort.Search(len(s.Raw), func(i int) bool { return s.Raw[i].Time.After(from) })
+	if i > 0 {
+		i--
+	}
+	ret := []models.HistoryPoint{}
+	for _, sample := range s.Raw[i:] {
+		if sample.Time.After(to) {
+			break
+		}
+		ret = append(ret, models.HistoryPoint{Time: sample.Time, Value: sample.Value})
+	}
+	return ret
+}
+
+// Increase возвращает прирост накопленной суммы counter по упорядоченным по времени точкам.
+// Уменьшение значения считается сбросом счётчика, тогда приростом становится всё новое значение.
+func Increase(points []models.HistoryPoint) float64 {
+	var ret float64
+	for i := 1; i < len(points); i++ {
+		if delta := points[i].Value - points[i-1].Value; delta >= 0 {
+			ret += delta
+		} else {
+			ret += points[i].Value
+		}
+	}
+	return ret
+}
@@ -0,0 +1,135 @@
+package history
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	rawLevelName = "raw"
+	day          = 24 * time.Hour
+)
+
+var (
+	errNoRawRetention   = errors.New("raw retention must be positive")
+	errRollupResolution = errors.New("rollup resolutions must be ascending and not exceed raw retention")
+	errRollupRetention  = errors.New("rollup retention must not be less than its resolution")
+)
+
+// Level описывает уровень агрегации: сэмплы сворачиваются в интервалы Resolution
+// и хранятся Retention.
+type Level struct {
+	Resolution time.Duration
+	Retention  time.Duration
+}
+
+// Key используется как ключ уровня при хранении свёрток.
+func (l Level) Key() string {
+	return strconv.FormatInt(int64(l.Resolution/time.Second), 10)
+}
+
+// Policy задаёт, сколько хранить сырые сэмплы и какие свёртки из них строить.
+type Policy struct {
+	Rollups []Level
+	Raw     time.Duration
+}
+
+// ParsePolicy разбирает политику вида "raw=24h,1m=30d,1h=365d".
+func ParsePolicy(s string) (Policy, error) {
+	var p Policy
+	for _, part := range strings.Split(s, ",") {
+		resolution, retention, ok := strings.Cut(strings.TrimSpace(part), "=")
+		if !ok {
+			return p, fmt.Errorf("wrong policy item %q, expected resolution=retention", part)
+		}
+		keep, err := parseDuration(retention)
+		if err != nil {
+			return p, err
+		}
+		if resolution == rawLevelName {
+			p.Raw = keep
+			continue
+		}
+		res, err := parseDuration(resolution)
+		if err != nil {
+			return p, err
+		}
+		p.Rollups = append(p.Rollups, Level{Resolution: res, Retention: keep})
+	}
+	return p, p.validate()
+}
+
+func (p Policy) validate() error {
+	if p.Raw <= 0 {
+		return errNoRawRetention
+	}
+	var prev time.Duration
+	for _, l := range p.Rollups {
+		if l.Resolution < time.Second || l.Resolution <= prev || l.Resolution > p.Raw {
+			return errRollupResolution
+		}
+		if l.Retention < l.Resolution {
+			return errRollupRetention
+		}
+		prev = l.Resolution
+	}
+	return nil
+}
+
+// Source выбирает разрешение данных для запроса истории начиная с from, 0 — сырые сэмплы.
+// Из уровней, которые ещё хранят from, берётся самый грубый не грубее step,
+// иначе самый подробный из хранящих, иначе самый долгоживущий.
+func (p Policy) Source(from time.Time, step time.Duration, now time.Time) time.Duration {
+	sources := append([]Level{{Retention: p.Raw}}, p.Rollups...)
+	chosen, covering := -1, -1
+	for i, src := range sources {
+		if now.Sub(from) > src.Retention {
+			continue
+		}
+		if covering < 0 {
+			covering = i
+		}
+		if step > 0 && src.Resolution <= step {
+			chosen = i
+		}
+	}
+	switch {
+	case chosen >= 0:
+		return sources[chosen].Resolution
+	case covering >= 0:
+		return sources[covering].Resolution
+	default:
+		return sources[len(sources)-1].Resolution
+	}
+}
+
+// Align округляет время вниз до границы интервала d, отсчитывая от начала эпохи unix.
+func Align(t time.Time, d time.Duration) time.Time {
+	if d <= 0 {
+		return t
+	}
+	rem := time.Duration(t.UnixNano() % int64(d))
+	if rem < 0 {
+		rem += d
+	}
+	return t.Add(-rem)
+}
+
+// parseDuration дополняет time.ParseDuration суффиксом d для суток.
+func parseDuration(s string) (time.Duration, error) {
+	if days, ok := strings.CutSuffix(s, "d"); ok {
+		n, err := strconv.Atoi(days)
+		if err != nil {
+			return 0, fmt.Errorf("can't parse duration %s: %w", s, err)
+		}
+		return time.Duration(n) * day, nil
+	}
+	d, err := time.ParseDuration(s)
+	if err != nil {
+		return 0, fmt.Errorf("can't parse duration %s: %w", s, err)
+	}
+	return d, nil
+}
@@ -0,0 +1,95 @@
+package history
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParsePolicy(t *testing.T) {
+	tests := []struct {
+		name    string
+		policy  string
+		want    Policy
+		wantErr bool
+	}{
+		{
+			name:   "Default policy",
+			policy: "raw=24h,1m=30d,1h=365d",
+			want: Policy{
+				Raw: 24 * time.Hour,
+				Rollups: []Level{
+					{Resolution: time.Minute, Retention: 30 * day},
+					{Resolution: time.Hour, Retention: 365 * day},
+				},
+			},
+		},
+		{
+			name:   "Raw only",
+			policy: "raw=2h",
+			want:   Policy{Raw: 2 * time.Hour},
+		},
+		{
+			name:    "No raw retention",
+			policy:  "1m=30d",
+			wantErr: true,
+		},
+		{
+			name:    "Descending resolutions",
+			policy:  "raw=24h,1h=365d,1m=30d",
+			wantErr: true,
+		},
+		{
+			name:    "Resolution exceeds raw retention",
+			policy:  "raw=1h,1d=365d",
+			wantErr: true,
+		},
+		{
+			name:    "Wrong item",
+			policy:  "raw:24h",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParsePolicy(tt.policy)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.Nil(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestPolicy_Source(t *testing.T) {
+	p := Policy{
+		Raw: 24 * time.Hour,
+		Rollups: []Level{
+			{Resolution: time.Minute, Retention: 30 * day},
+			{Resolution: time.Hour, Retention: 365 * day},
+		},
+	}
+	now := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name string
+		from time.Time
+		step time.Duration
+		want time.Duration
+	}{
+		{name: "Recent raw", from: now.Add(-time.Hour), want: 0},
+		{name: "Recent with step", from: now.Add(-time.Hour), step: 5 * time.Minute, want: time.Minute},
+		{name: "Recent with small step", from: now.Add(-time.Hour), step: 10 * time.Second, want: 0},
+		{name: "Week ago", from: now.Add(-7 * day), want: time.Minute},
+		{name: "Week ago with small step", from: now.Add(-7 * day), step: time.Second, want: time.Minute},
+		{name: "Half year ago", from: now.Add(-180 * day), step: time.Minute, want: time.Hour},
+		{name: "Beyond retention", from: now.Add(-500 * day), want: time.Hour},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, p.Source(tt.from, tt.step, now))
+		})
+	}
+}
@@ -0,0 +1,146 @@
+package history
+
+import (
+	"sort"
+	"time"
+
+	"github.com/NikolayStrekalov/vigilant-octo-waddle.git/internal/models"
+)
+
+// Sample — сырое значение метрики.
+type Sample struct {
+	Time  time.Time `json:"t"`
+	Value float64   `json:"v"`
+}
+
+// Rollup — агрегат сэмплов за интервал, начинающийся в Start.
+type Rollup struct {
+	Start time.Time `json:"t"`
+	Min   float64   `json:"min"`
+	Max   float64   `json:"max"`
+	Sum   float64   `json:"sum"`
+	Last  float64   `json:"last"`
+	Count int64     `json:"count"`
+}
+
+func (r *Rollup) add(v float64) {
+	if r.Count == 0 || v < r.Min {
+		r.Min = v
+	}
+	if r.Count == 0 || v > r.Max {
+		r.Max = v
+	}
+	r.Sum += v
+	r.Last = v
+	r.Count++
+}
+
+func (r *Rollup) merge(o *Rollup) {
+	if r.Count == 0 || o.Min < r.Min {
+		r.Min = o.Min
+	}
+	if r.Count == 0 || o.Max > r.Max {
+		r.Max = o.Max
+	}
+	r.Sum += o.Sum
+	r.Last = o.Last
+	r.Count += o.Count
+}
+
+func (r *Rollup) point() models.HistoryPoint {
+	avg := r.Sum / float64(r.Count)
+	return models.HistoryPoint{Time: r.Start, Value: r.Last, Min: &r.Min, Max: &r.Max, Avg: &avg}
+}
+
+// Series хранит историю одной метрики: сырые сэмплы и свёртки по ключам уровней.
+// Сэмплы и свёртки упорядочены по времени.
+type Series struct {
+	Rollups map[string][]Rollup `json:"rollups,omitempty"`
+	Raw     []Sample            `json:"raw"`
+}
+
+// Add добавляет сэмпл в конец истории.
+func (s *Series) Add(t time.Time, v float64) {
+	s.Raw = append(s.Raw, Sample{Time: t, Value: v})
+}
+
+// Compact пересчитывает свёртки за завершённые с момента since интервалы
+// и удаляет данные старше сроков хранения политики.
+func (s *Series) Compact(p Policy, since, now time.Time) {
+	rollups := make(map[string][]Rollup, len(p.Rollups))
+	for _, l := range p.Rollups {
+		start := Align(since, l.Resolution)
+		kept := s.Rollups[l.Key()]
+		kept = kept[:sort.Search(len(kept), func(i int) bool { return !kept[i].Start.Before(start) })]
+		kept = append(kept, s.rollup(l.Resolution, start, Align(now, l.Resolution))...)
+		oldest := now.Add(-l.Retention)
+		kept = kept[sort.Search(len(kept), func(i int) bool { return kept[i].Start.After(oldest) }):]
+		if len(kept) > 0 {
+			rollups[l.Key()] = kept
+		}
+	}
+	s.Rollups = rollups
+	oldest := now.Add(-p.Raw)
+	s.Raw = s.Raw[sort.Search(len(s.Raw), func(i int) bool { return s.Raw[i].Time.After(oldest) }):]
+}
+
+// Empty сообщает, что в истории не осталось данных.
+func (s *Series) Empty() bool {
+	return len(s.Raw) == 0 && len(s.Rollups) == 0
+}
+
+// rollup сворачивает сырые сэмплы из [from, to) в интервалы d.
+func (s *Series) rollup(d time.Duration, from, to time.Time) []Rollup {
+	var ret []Rollup
+	for _, sample := range s.Raw {
+		if sample.Time.Before(from) || !sample.Time.Before(to) {
+			continue
+		}
+		start := Align(sample.Time, d)
+		if len(ret) == 0 || !ret[len(ret)-1].Start.Equal(start) {
+			ret = append(ret, Rollup{Start: start})
+		}
+		ret[len(ret)-1].add(sample.Value)
+	}
+	return ret
+}
+
+// Query возвращает точки истории в [from, to] из источника, выбранного политикой.
+// При положительном step точки агрегируются по интервалам step.
+func (s *Series) Query(p Policy, from, to time.Time, step time.Duration, now time.Time) []models.HistoryPoint {
+	ret := []models.HistoryPoint{}
+	resolution := p.Source(from, step, now)
+	if resolution == 0 {
+		if step == 0 {
+			for _, sample := range s.Raw {
+				if !sample.Time.Before(from) && !sample.Time.After(to) {
+					ret = append(ret, models.HistoryPoint{Time: sample.Time, Value: sample.Value})
+				}
+			}
+			return ret
+		}
+		buckets := s.rollup(step, from, to.Add(time.Nanosecond))
+		for i := range buckets {
+			ret = append(ret, buckets[i].point())
+		}
+		return ret
+	}
+	var buckets []Rollup
+	from = Align(from, resolution)
+	for _, r := range s.Rollups[Level{Resolution: resolution}.Key()] {
+		if r.Start.Before(from) || r.Start.After(to) {
+			continue
+		}
+		if step > resolution {
+			r.Start = Align(r.Start, step)
+		}
+		if len(buckets) == 0 || !buckets[len(buckets)-1].Start.Equal(r.Start) {
+			buckets = append(buckets, Rollup{Start: r.Start})
+		}
+		buckets[len(buckets)-1].merge(&r)
+	}
+	for i := range buckets {
+		ret = append(ret, buckets[i].point())
+	}
+	return ret
+}
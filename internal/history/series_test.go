@@ -0,0 +1,77 @@
+package history
+
+import (
+	"testing"
+	"time"
+
+	"github.com/NikolayStrekalov/vigilant-octo-waddle.git/internal/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func Ptr[T any](v T) *T {
+	return &v
+}
+
+func TestSeries_CompactAndQuery(t *testing.T) {
+	p := Policy{
+		Raw:     time.Hour,
+		Rollups: []Level{{Resolution: time.Minute, Retention: 24 * time.Hour}},
+	}
+	start := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+	s := Series{}
+	for i, v := range []float64{3, 1, 2, 10, 20} {
+		s.Add(start.Add(time.Duration(i)*30*time.Second), v)
+	}
+
+	now := start.Add(2*time.Minute + 30*time.Second)
+	s.Compact(p, start.Add(-time.Hour), now)
+	assert.Equal(t, []Rollup{
+		{Start: start, Min: 1, Max: 3, Sum: 4, Last: 1, Count: 2},
+		{Start: start.Add(time.Minute), Min: 2, Max: 10, Sum: 12, Last: 10, Count: 2},
+	}, s.Rollups["60"])
+	assert.Len(t, s.Raw, 5)
+
+	assert.Equal(t, []models.HistoryPoint{
+		{Time: start.Add(time.Minute), Value: 2},
+		{Time: start.Add(90 * time.Second), Value: 10},
+	}, s.Query(p, start.Add(time.Minute), start.Add(90*time.Second), 0, now))
+
+	assert.Equal(t, []models.HistoryPoint{
+		{Time: start, Value: 10, Min: Ptr(1.0), Max: Ptr(10.0), Avg: Ptr(4.0)},
+	}, s.Query(p, start, start.Add(90*time.Second), 2*time.Minute, now))
+
+	// через два часа сырые сэмплы устаревают, история читается из свёрток
+	later := start.Add(2 * time.Hour)
+	s.Compact(p, now, later)
+	assert.Empty(t, s.Raw)
+	assert.Equal(t, []models.HistoryPoint{
+		{Time: start, Value: 1, Min: Ptr(1.0), Max: Ptr(3.0), Avg: Ptr(2.0)},
+		{Time: start.Add(time.Minute), Value: 10, Min: Ptr(2.0), Max: Ptr(10.0), Avg: Ptr(6.0)},
+		{Time: start.Add(2 * time.Minute), Value: 20, Min: Ptr(20.0), Max: Ptr(20.0), Avg: Ptr(20.0)},
+	}, s.Query(p, start, later, 0, later))
+
+	s.Compact(p, later, start.Add(48*time.Hour))
+	assert.True(t, s.Empty())
+}
+
+func TestSeries_WindowAndIncrease(t *testing.T) {
+	start := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+	s := Series{}
+	for i, v := range []float64{5, 10, 30, 4, 6, 100} {
+		s.Add(start.Add(time.Duration(i)*time.Minute), v)
+	}
+	window := s.Window(start.Add(90*time.Second), start.Add(4*time.Minute))
+	assert.Equal(t, []models.HistoryPoint{
+		{Time: start.Add(time.Minute), Value: 10},
+		{Time: start.Add(2 * time.Minute), Value: 30},
+		{Time: start.Add(3 * time.Minute), Value: 4},
+		{Time: start.Add(4 * time.Minute), Value: 6},
+	}, window)
+	assert.Equal(t, 20.0+4+2, Increase(window), "a decrease is a reset")
+
+	assert.Len(t, s.Window(start.Add(-time.Minute), start), 1)
+	assert.Empty(t, s.Window(start.Add(-time.Hour), start.Add(-time.Minute)))
+	late := s.Window(start.Add(time.Hour), start.Add(2*time.Hour))
+	assert.Len(t, late, 1, "only the last sample before the window")
+	assert.Zero(t, Increase(late))
+}
@@ -0,0 +1,178 @@
+// Package hll оценивает число различных значений с помощью HyperLogLog.
+//
+// Старшие precision бит хеша значения выбирают регистр, в регистре хранится наибольшая позиция
+// первой единицы в остальных битах. Наброски сливаются поразрядным максимумом, поэтому агенты
+// могут считать уникальные значения сами и присылать только регистры. Набросок с большей точностью
+// перед слиянием сворачивается до меньшей.
+package hll
+
+import (
+	"errors"
+	"hash/fnv"
+	"math"
+	"math/bits"
+
+	"github.com/NikolayStrekalov/vigilant-octo-waddle.git/internal/models"
+)
+
+const (
+	// DefaultPrecision даёт 16384 регистра и стандартную ошибку около 0.8%.
+	DefaultPrecision = 14
+	MinPrecision     = 4
+	MaxPrecision     = 18
+)
+
+var (
+	errWrongPrecision = errors.New("precision must be in [4, 18]")
+	errWrongRegisters = errors.New("registers must have 2^precision values not exceeding 65-precision")
+	errWrongEncoding  = errors.New("encoded sketch is too short")
+)
+
+// New возвращает пустой набросок.
+func New(precision uint8) models.HLL {
+	return models.HLL{Registers: make([]byte, 1<<precision), Precision: precision}
+}
+
+// ValidPrecision проверяет точность наброска.
+func ValidPrecision(precision uint8) error {
+	if precision < MinPrecision || precision > MaxPrecision {
+		return errWrongPrecision
+	}
+	return nil
+}
+
+// Validate проверяет набросок, полученный от клиента.
+func Validate(h *models.HLL) error {
+	if err := ValidPrecision(h.Precision); err != nil {
+		return err
+	}
+	if len(h.Registers) != 1<<h.Precision {
+		return errWrongRegisters
+	}
+	for _, r := range h.Registers {
+		if r > maxRank(h.Precision) {
+			return errWrongRegisters
+		}
+	}
+	return nil
+}
+
+func maxRank(precision uint8) byte {
+	return 64 - precision + 1
+}
+
+// hash перемешивает FNV-1a финализатором MurmurHash3, чтобы все биты хеша были равномерны.
+func hash(value string) uint64 {
+	h := fnv.New64a()
+	_, _ = h.Write([]byte(value))
+	x := h.Sum64()
+	x ^= x >> 33
+	x *= 0xff51afd7ed558ccd
+	x ^= x >> 33
+	x *= 0xc4ceb9fe1a85ec53
+	x ^= x >> 33
+	return x
+}
+
+// Add добавляет значение в набросок.
+func Add(h *models.HLL, value string) {
+	x := hash(value)
+	i := x >> (64 - h.Precision)
+	rank := byte(min(bits.LeadingZeros64(x<<h.Precision)+1, int(maxRank(h.Precision))))
+	if rank > h.Registers[i] {
+		h.Registers[i] = rank
+	}
+}
+
+// fold сворачивает набросок до меньшей точности: младшие биты номера регистра становятся
+// старшими битами остатка хеша.
+func fold(h *models.HLL, precision uint8) models.HLL {
+	ret := New(precision)
+	shift := h.Precision - precision
+	for j, r := range h.Registers {
+		if r == 0 {
+			continue
+		}
+		rank := shift + r
+		if low := uint64(j) & (1<<shift - 1); low != 0 {
+			rank = shift - byte(bits.Len64(low)) + 1
+		}
+		rank = min(rank, maxRank(precision))
+		if i := j >> shift; rank > ret.Registers[i] {
+			ret.Registers[i] = rank
+		}
+	}
+	return ret
+}
+
+// Merge добавляет в dst значения из src. Пустой dst становится копией src.
+func Merge(dst, src *models.HLL) {
+	switch {
+	case len(dst.Registers) == 0:
+		*dst = models.HLL{Registers: append([]byte{}, src.Registers...), Precision: src.Precision}
+		return
+	case dst.Precision > src.Precision:
+		*dst = fold(dst, src.Precision)
+	case dst.Precision < src.Precision:
+		folded := fold(src, dst.Precision)
+		src = &folded
+	}
+	for i, r := range src.Registers {
+		if r > dst.Registers[i] {
+			dst.Registers[i] = r
+		}
+	}
+}
+
+// Count возвращает оценку числа различных значений. Для малых оценок используется линейный счёт
+// по пустым регистрам, он точнее HyperLogLog, пока пустых регистров много.
+func Count(h *models.HLL) uint64 {
+	m := float64(len(h.Registers))
+	if m == 0 {
+		return 0
+	}
+	var (
+		sum   float64
+		zeros int
+	)
+	for _, r := range h.Registers {
+		sum += math.Ldexp(1, -int(r))
+		if r == 0 {
+			zeros++
+		}
+	}
+	estimate := alpha(len(h.Registers)) * m * m / sum
+	if estimate <= 2.5*m && zeros > 0 {
+		estimate = m * math.Log(m/float64(zeros))
+	}
+	return uint64(math.Round(estimate))
+}
+
+func alpha(m int) float64 {
+	switch m {
+	case 16:
+		return 0.673
+	case 32:
+		return 0.697
+	case 64:
+		return 0.709
+	}
+	return 0.7213 / (1 + 1.079/float64(m))
+}
+
+// Encode записывает набросок для хранения: байт точности, затем регистры.
+func Encode(h *models.HLL) []byte {
+	return append([]byte{h.Precision}, h.Registers...)
+}
+
+// Decode читает набросок, записанный Encode.
+func Decode(data []byte) (models.HLL, error) {
+	if len(data) == 0 {
+		return models.HLL{}, errWrongEncoding
+	}
+	h := models.HLL{Precision: data[0], Registers: append([]byte{}, data[1:]...)}
+	if err := Validate(&h); err != nil {
+		return h, err
+	}
+	return h, nil
+}
@@ -0,0 +1,82 @@
+package hll
+
+import (
+	"strconv"
+	"testing"
+
+	"github.com/NikolayStrekalov/vigilant-octo-waddle.git/internal/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCount(t *testing.T) {
+	for _, n := range []int{0, 1, 10, 1000, 100000} {
+		h := New(DefaultPrecision)
+		for i := 0; i < n; i++ {
+			Add(&h, "user-"+strconv.Itoa(i))
+			Add(&h, "user-"+strconv.Itoa(i))
+		}
+		if n == 0 {
+			assert.Equal(t, uint64(0), Count(&h))
+			continue
+		}
+		assert.InEpsilon(t, n, Count(&h), 0.03, "n=%d", n)
+	}
+}
+
+func TestMerge(t *testing.T) {
+	a, b, all := New(DefaultPrecision), New(DefaultPrecision), New(DefaultPrecision)
+	for i := 0; i < 5000; i++ {
+		Add(&a, strconv.Itoa(i))
+		Add(&all, strconv.Itoa(i))
+	}
+	for i := 2500; i < 10000; i++ {
+		Add(&b, strconv.Itoa(i))
+		Add(&all, strconv.Itoa(i))
+	}
+	merged := models.HLL{}
+	Merge(&merged, &a)
+	Merge(&merged, &b)
+	assert.Equal(t, all, merged)
+	assert.InEpsilon(t, 10000, Count(&merged), 0.03)
+}
+
+func TestMerge_Precision(t *testing.T) {
+	precise, coarse, want := New(16), New(10), New(10)
+	for i := 0; i < 20000; i++ {
+		Add(&precise, strconv.Itoa(i))
+		Add(&want, strconv.Itoa(i))
+	}
+	for i := 10000; i < 30000; i++ {
+		Add(&coarse, strconv.Itoa(i))
+		Add(&want, strconv.Itoa(i))
+	}
+	merged := precise
+	Merge(&merged, &coarse)
+	assert.Equal(t, want, merged, "folding must give the same registers as counting at lower precision")
+
+	merged = New(10)
+	Merge(&merged, &precise)
+	Merge(&merged, &coarse)
+	assert.Equal(t, want, merged)
+}
+
+func TestValidate(t *testing.T) {
+	h := New(DefaultPrecision)
+	assert.NoError(t, Validate(&h))
+	assert.Error(t, Validate(&models.HLL{Precision: 3, Registers: make([]byte, 8)}))
+	assert.Error(t, Validate(&models.HLL{Precision: 4, Registers: make([]byte, 8)}))
+	h.Registers[0] = 52
+	assert.Error(t, Validate(&h))
+}
+
+func TestEncode(t *testing.T) {
+	h := New(MinPrecision)
+	Add(&h, "a")
+	got, err := Decode(Encode(&h))
+	assert.Nil(t, err)
+	assert.Equal(t, h, got)
+	_, err = Decode(nil)
+	assert.Error(t, err)
+	_, err = Decode([]byte{4, 0})
+	assert.Error(t, err)
+}
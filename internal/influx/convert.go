@@ -0,0 +1,108 @@
+package influx
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"path"
+	"strings"
+
+	"github.com/NikolayStrekalov/vigilant-octo-waddle.git/internal/models"
+	"github.com/NikolayStrekalov/vigilant-octo-waddle.git/internal/series"
+)
+
+// valueField — имя поля, для которого метрика называется просто по measurement.
+const valueField = "value"
+
+// Name возвращает имя метрики для поля точки: measurement_field или measurement для поля value.
+func Name(measurement, field string) string {
+	if field == valueField {
+		return measurement
+	}
+	return measurement + "_" + field
+}
+
+// Labels возвращает теги точки как метки серии.
+func (p *Point) Labels() map[string]string {
+	if len(p.Tags) == 0 {
+		return nil
+	}
+	labels := make(map[string]string, len(p.Tags))
+	for _, t := range p.Tags {
+		labels[series.Sanitize(t.Key)] = t.Value
+	}
+	return labels
+}
+
+// counterSuffix — поля с таким окончанием имени метрики всегда считаются счётчиками.
+const counterSuffix = "_total"
+
+var errWrongPattern = errors.New("wrong counter pattern")
+
+// ParseCounters разбирает шаблоны path.Match имён метрик-счётчиков через запятую,
+// например net_bytes_*,diskio_reads.
+func ParseCounters(s string) ([]string, error) {
+	var patterns []string
+	if strings.TrimSpace(s) == "" {
+		return patterns, nil
+	}
+	for _, part := range strings.Split(s, ",") {
+		pattern := strings.TrimSpace(part)
+		if _, err := path.Match(pattern, ""); err != nil || pattern == "" {
+			return patterns, fmt.Errorf("%w %q", errWrongPattern, part)
+		}
+		patterns = append(patterns, pattern)
+	}
+	return patterns, nil
+}
+
+// isCounter сообщает, что метрика name накопительная: по суффиксу _total или по шаблонам counters.
+func isCounter(name string, counters []string) bool {
+	if strings.HasSuffix(name, counterSuffix) {
+		return true
+	}
+	for _, pattern := range counters {
+		if ok, _ := path.Match(pattern, name); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// ToMetrics переводит точки в обновления метрик, теги становятся метками серии.
+// Числа, в том числе целые (3i, 3u), и логические значения становятся gauge, строковые поля пропускаются.
+// Счётчиками считаются только метрики с суффиксом _total и подходящие под шаблоны counters,
+// они передаются накопительным значением Total, а рост считает сервер.
+func ToMetrics(points []Point, counters []string) models.MetricsSlice {
+	ret := models.MetricsSlice{}
+	index := make(map[string]int)
+	for i := range points {
+		p := &points[i]
+		labels := p.Labels()
+		for _, f := range p.Fields {
+			if f.Type == FieldString || math.IsNaN(f.Value) || math.IsInf(f.Value, 0) {
+				continue
+			}
+			name := Name(p.Measurement, f.Key)
+			if isCounter(name, counters) {
+				if f.Value < 0 {
+					continue
+				}
+				// каждое значение передаётся по порядку, чтобы сервер увидел сброс внутри запроса
+				total := int64(math.Floor(f.Value))
+				ret = append(ret, models.Metrics{ID: name, MType: "counter", Labels: labels, Total: &total})
+				continue
+			}
+			key := series.Key(name, labels)
+			j, ok := index[key]
+			if !ok {
+				j = len(ret)
+				index[key] = j
+				ret = append(ret, models.Metrics{ID: name, MType: "gauge", Labels: labels})
+			}
+			v := f.Value
+			ret[j].Value = &v
+		}
+	}
+	return ret
+}
@@ -0,0 +1,105 @@
+package influx
+
+import (
+	"testing"
+
+	"github.com/NikolayStrekalov/vigilant-octo-waddle.git/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseLine(t *testing.T) {
+	tests := []struct {
+		name    string
+		line    string
+		want    Point
+		wantErr bool
+	}{
+		{
+			name: "Tags and typed fields",
+			line: `cpu,host=db1,region=eu usage=12.5,procs=42i,ok=true,note="a b,c=d" 1717243200000000000`,
+			want: Point{
+				Measurement: "cpu",
+				Tags:        []Tag{{Key: "host", Value: "db1"}, {Key: "region", Value: "eu"}},
+				Fields: []Field{
+					{Key: "usage", Value: 12.5, Type: FieldFloat},
+					{Key: "procs", Value: 42, Type: FieldInteger},
+					{Key: "ok", Value: 1, Type: FieldBool},
+					{Key: "note", Type: FieldString},
+				},
+			},
+		},
+		{
+			name: "Escapes without timestamp",
+			line: `disk\ io,path=/var\,log bytes=7u`,
+			want: Point{
+				Measurement: "disk io",
+				Tags:        []Tag{{Key: "path", Value: "/var,log"}},
+				Fields:      []Field{{Key: "bytes", Value: 7, Type: FieldUnsigned}},
+			},
+		},
+		{name: "No fields", line: "cpu,host=db1", wantErr: true},
+		{name: "No measurement", line: ",host=db1 value=1", wantErr: true},
+		{name: "Wrong tag", line: "cpu,host value=1", wantErr: true},
+		{name: "Wrong integer", line: "cpu value=1.5i", wantErr: true},
+		{name: "Unclosed string", line: `cpu note="abc`, wantErr: true},
+		{name: "Wrong timestamp", line: "cpu value=1 now", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseLine(tt.line)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.Nil(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestParse(t *testing.T) {
+	points, err := Parse("# telegraf\nmem used=1.5\n\nnet,iface=eth0 bytes_recv=10i\n")
+	assert.Nil(t, err)
+	assert.Len(t, points, 2)
+
+	_, err = Parse("mem used=1.5\nmem used")
+	assert.ErrorContains(t, err, "line 2")
+}
+
+func TestToMetrics(t *testing.T) {
+	points, err := Parse(`temperature value=21.5
+net,iface=eth0 bytes_recv=100i,requests_total=7i
+mem available=2048i
+sensor,id=1 online=false,label="hall"
+mem available=1024i
+net,iface=eth0 bytes_recv=40i`)
+	require.NoError(t, err)
+	got := ToMetrics(points, []string{"net_bytes_*"})
+	value, online, available := 21.5, 0.0, 1024.0
+	requests, eth0, reset := int64(7), int64(100), int64(40)
+	eth0Labels := map[string]string{"iface": "eth0"}
+	assert.Equal(t, models.MetricsSlice{
+		{ID: "temperature", MType: "gauge", Value: &value},
+		{ID: "net_bytes_recv", MType: "counter", Total: &eth0, Labels: eth0Labels},
+		{ID: "net_requests_total", MType: "counter", Total: &requests, Labels: eth0Labels},
+		{ID: "mem_available", MType: "gauge", Value: &available},
+		{ID: "sensor_online", MType: "gauge", Value: &online, Labels: map[string]string{"id": "1"}},
+		{ID: "net_bytes_recv", MType: "counter", Total: &reset, Labels: eth0Labels},
+	}, got, "integer fields are gauges unless they match a counter rule")
+
+	got = ToMetrics(points[1:2], nil)
+	assert.Equal(t, "gauge", got[0].MType)
+	assert.Equal(t, "counter", got[1].MType)
+}
+
+func TestParseCounters(t *testing.T) {
+	patterns, err := ParseCounters(" net_bytes_*, diskio_reads")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"net_bytes_*", "diskio_reads"}, patterns)
+	patterns, err = ParseCounters("")
+	assert.NoError(t, err)
+	assert.Empty(t, patterns)
+	_, err = ParseCounters("net_[,")
+	assert.Error(t, err)
+}
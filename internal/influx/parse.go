@@ -0,0 +1,195 @@
+// Package influx разбирает InfluxDB line protocol и переводит точки в обновления метрик сервера.
+package influx
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Типы значений полей.
+const (
+	FieldFloat = iota
+	FieldInteger
+	FieldUnsigned
+	FieldBool
+	FieldString
+)
+
+var (
+	errNoFields      = errors.New("point must have at least one field")
+	errNoMeasurement = errors.New("measurement is empty")
+	errWrongPair     = errors.New("tags and fields must be key=value pairs")
+	errWrongValue    = errors.New("wrong field value")
+	errWrongTime     = errors.New("timestamp must be an integer")
+)
+
+type Tag struct {
+	Key   string
+	Value string
+}
+
+type Field struct {
+	Key   string
+	Value float64
+	Type  int
+}
+
+// Point — одна строка протокола: measurement,tag=v field=1.5,count=3i 1717243200000000000.
+// Отметка времени проверяется, но не сохраняется: значения записываются как текущие.
+type Point struct {
+	Measurement string
+	Tags        []Tag
+	Fields      []Field
+}
+
+// Parse разбирает все строки тела запроса, пустые строки и комментарии пропускаются.
+func Parse(data string) ([]Point, error) {
+	var points []Point
+	for i, line := range strings.Split(data, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || line[0] == '#' {
+			continue
+		}
+		p, err := ParseLine(line)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", i+1, err)
+		}
+		points = append(points, p)
+	}
+	return points, nil
+}
+
+func ParseLine(line string) (Point, error) {
+	var p Point
+	head, rest := cut(line, ' ', false)
+	fields, timestamp := cut(rest, ' ', true)
+	if timestamp = strings.TrimSpace(timestamp); timestamp != "" {
+		if _, err := strconv.ParseInt(timestamp, 10, 64); err != nil {
+			return p, fmt.Errorf("%q: %w", timestamp, errWrongTime)
+		}
+	}
+	parts := split(head, ',', false)
+	p.Measurement = unescape(parts[0])
+	if p.Measurement == "" {
+		return p, errNoMeasurement
+	}
+	for _, part := range parts[1:] {
+		key, value, err := pair(part)
+		if err != nil {
+			return p, err
+		}
+		p.Tags = append(p.Tags, Tag{Key: key, Value: unescape(value)})
+	}
+	if fields == "" {
+		return p, errNoFields
+	}
+	for _, part := range split(fields, ',', true) {
+		key, value, err := pair(part)
+		if err != nil {
+			return p, err
+		}
+		f, err := parseField(key, value)
+		if err != nil {
+			return p, err
+		}
+		p.Fields = append(p.Fields, f)
+	}
+	return p, nil
+}
+
+func pair(s string) (string, string, error) {
+	key, value := cut(s, '=', false)
+	if key == "" || value == "" {
+		return "", "", fmt.Errorf("%q: %w", s, errWrongPair)
+	}
+	return unescape(key), value, nil
+}
+
+func parseField(key, value string) (Field, error) {
+	f := Field{Key: key}
+	var err error
+	switch {
+	case value[0] == '"':
+		if len(value) == 1 || value[len(value)-1] != '"' {
+			return f, fmt.Errorf("%s=%s: %w", key, value, errWrongValue)
+		}
+		f.Type = FieldString
+		return f, nil
+	case strings.HasSuffix(value, "i"):
+		var v int64
+		v, err = strconv.ParseInt(value[:len(value)-1], 10, 64)
+		f.Type, f.Value = FieldInteger, float64(v)
+	case strings.HasSuffix(value, "u"):
+		var v uint64
+		v, err = strconv.ParseUint(value[:len(value)-1], 10, 64)
+		f.Type, f.Value = FieldUnsigned, float64(v)
+	default:
+		if b, ok := parseBool(value); ok {
+			f.Type = FieldBool
+			if b {
+				f.Value = 1
+			}
+			return f, nil
+		}
+		f.Type = FieldFloat
+		f.Value, err = strconv.ParseFloat(value, 64)
+	}
+	if err != nil {
+		return f, fmt.Errorf("%s=%s: %w", key, value, errWrongValue)
+	}
+	return f, nil
+}
+
+func parseBool(s string) (bool, bool) {
+	switch s {
+	case "t", "T", "true", "True", "TRUE":
+		return true, true
+	case "f", "F", "false", "False", "FALSE":
+		return false, true
+	}
+	return false, false
+}
+
+// cut делит строку по первому неэкранированному разделителю.
+// С quoted разделители внутри строк в двойных кавычках пропускаются.
+func cut(s string, sep byte, quoted bool) (string, string) {
+	if i := index(s, sep, quoted); i >= 0 {
+		return s[:i], s[i+1:]
+	}
+	return s, ""
+}
+
+func split(s string, sep byte, quoted bool) []string {
+	var ret []string
+	for {
+		i := index(s, sep, quoted)
+		if i < 0 {
+			return append(ret, s)
+		}
+		ret = append(ret, s[:i])
+		s = s[i+1:]
+	}
+}
+
+func index(s string, sep byte, quoted bool) int {
+	inQuotes := false
+	for i := 0; i < len(s); i++ {
+		switch {
+		case s[i] == '\\':
+			i++
+		case quoted && s[i] == '"':
+			inQuotes = !inQuotes
+		case s[i] == sep && !inQuotes:
+			return i
+		}
+	}
+	return -1
+}
+
+var unescaper = strings.NewReplacer(`\,`, ",", `\=`, "=", `\ `, " ", `\\`, `\`)
+
+func unescape(s string) string {
+	return unescaper.Replace(s)
+}
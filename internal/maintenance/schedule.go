@@ -0,0 +1,184 @@
+package maintenance
+
+import (
+	"errors"
+	"fmt"
+	"path"
+	"sync"
+	"time"
+
+	"github.com/NikolayStrekalov/vigilant-octo-waddle.git/internal/models"
+)
+
+// Режимы окна обслуживания.
+const (
+	ModeFlag   = "flag"   // обновления принимаются и помечаются
+	ModeReject = "reject" // обновления отклоняются
+)
+
+var (
+	errNoPattern  = errors.New("pattern must not be empty")
+	errWrongMode  = errors.New("mode must be flag or reject")
+	errWrongRange = errors.New("end must be after start")
+)
+
+// Validate проверяет окно и подставляет режим по умолчанию.
+func Validate(w *models.MaintenanceWindow) error {
+	if w.Pattern == "" {
+		return errNoPattern
+	}
+	if _, err := path.Match(w.Pattern, ""); err != nil {
+		return fmt.Errorf("wrong pattern %q: %w", w.Pattern, err)
+	}
+	switch w.Mode {
+	case "":
+		w.Mode = ModeFlag
+	case ModeFlag, ModeReject:
+	default:
+		return errWrongMode
+	}
+	if !w.End.After(w.Start) {
+		return errWrongRange
+	}
+	return nil
+}
+
+// Schedule хранит окна обслуживания и метрики, обновлённые во время них.
+type Schedule struct {
+	flagged map[string]struct{}
+	pending map[string]bool // изменения пометок, ещё не переданные onFlag
+	mux     *sync.RWMutex
+	persist *sync.Mutex // упорядочивает передачу изменений onFlag
+	wake    chan struct{}
+	onFlag  func(key string, flagged bool)
+	windows []models.MaintenanceWindow
+}
+
+// Key возвращает ключ пометки метрики.
+func Key(kind, name string) string {
+	return kind + "/" + name
+}
+
+func NewSchedule() *Schedule {
+	return &Schedule{
+		flagged: make(map[string]struct{}),
+		pending: make(map[string]bool),
+		mux:     &sync.RWMutex{},
+		persist: &sync.Mutex{},
+		wake:    make(chan struct{}, 1),
+	}
+}
+
+// SetWindows заменяет набор окон.
+func (s *Schedule) SetWindows(windows []models.MaintenanceWindow) {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+	s.windows = windows
+}
+
+// SetFlags восстанавливает пометки по ключам Key, например сохранённые до перезапуска.
+func (s *Schedule) SetFlags(keys []string) {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+	s.flagged = make(map[string]struct{}, len(keys))
+	for _, key := range keys {
+		s.flagged[key] = struct{}{}
+	}
+}
+
+// OnFlag задаёт функцию, которая сохраняет установку и снятие пометки, и запускает её вызовы.
+// Функция вызывается из одной горутины вне блокировки расписания, поэтому медленное сохранение
+// не задерживает приём обновлений. Изменения, накопившиеся за время вызова, схлопываются:
+// для каждой метрики передаётся последнее состояние пометки.
+func (s *Schedule) OnFlag(f func(key string, flagged bool)) {
+	s.mux.Lock()
+	started := s.onFlag != nil
+	s.onFlag = f
+	s.mux.Unlock()
+	if !started {
+		go s.persistFlags()
+	}
+}
+
+func (s *Schedule) persistFlags() {
+	for range s.wake {
+		s.Flush()
+	}
+}
+
+// Flush передаёт onFlag накопившиеся изменения пометок и дожидается их сохранения.
+func (s *Schedule) Flush() {
+	s.persist.Lock()
+	defer s.persist.Unlock()
+	s.mux.Lock()
+	pending, onFlag := s.pending, s.onFlag
+	s.pending = make(map[string]bool)
+	s.mux.Unlock()
+	if onFlag == nil {
+		return
+	}
+	for key, flagged := range pending {
+		onFlag(key, flagged)
+	}
+}
+
+// Mode возвращает режим окна, действующего для метрики в момент now, или пустую строку.
+// Если подходят несколько окон, отклонение важнее пометки.
+func (s *Schedule) Mode(name string, now time.Time) string {
+	s.mux.RLock()
+	defer s.mux.RUnlock()
+	mode := ""
+	for i := range s.windows {
+		w := &s.windows[i]
+		if now.Before(w.Start) || !now.Before(w.End) {
+			continue
+		}
+		if ok, _ := path.Match(w.Pattern, name); !ok {
+			continue
+		}
+		if w.Mode == ModeReject {
+			return ModeReject
+		}
+		mode = ModeFlag
+	}
+	return mode
+}
+
+// Admit решает, принять ли обновление метрики в момент now, и сообщает, попадает ли оно в окно с пометкой.
+// Пометку запоминает Record, когда обновление сохранено.
+func (s *Schedule) Admit(name string, now time.Time) (flagged, ok bool) {
+	mode := s.Mode(name, now)
+	return mode == ModeFlag, mode != ModeReject
+}
+
+// Record запоминает, получено ли сохранённое обновление метрики во время обслуживания.
+func (s *Schedule) Record(kind, name string, flagged bool) {
+	key := Key(kind, name)
+	s.mux.Lock()
+	defer s.mux.Unlock()
+	_, was := s.flagged[key]
+	if was == flagged {
+		return
+	}
+	if flagged {
+		s.flagged[key] = struct{}{}
+	} else {
+		delete(s.flagged, key)
+	}
+	if s.onFlag == nil {
+		return
+	}
+	s.pending[key] = flagged
+	select {
+	case s.wake <- struct{}{}:
+	default:
+	}
+}
+
+// Flagged сообщает, что последнее значение метрики получено во время обслуживания.
+func (s *Schedule) Flagged(kind, name string) bool {
+	s.mux.RLock()
+	defer s.mux.RUnlock()
+	_, ok := s.flagged[Key(kind, name)]
+	return ok
+}
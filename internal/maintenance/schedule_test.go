@@ -0,0 +1,133 @@
+package maintenance
+
+import (
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/NikolayStrekalov/vigilant-octo-waddle.git/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidate(t *testing.T) {
+	start := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name     string
+		window   models.MaintenanceWindow
+		wantMode string
+		wantErr  bool
+	}{
+		{
+			name:     "Default mode",
+			window:   models.MaintenanceWindow{Pattern: "CPUutilization*", Start: start, End: start.Add(time.Hour)},
+			wantMode: ModeFlag,
+		},
+		{
+			name: "Reject",
+			window: models.MaintenanceWindow{
+				Pattern: "FreeMemory", Mode: ModeReject, Start: start, End: start.Add(time.Hour),
+			},
+			wantMode: ModeReject,
+		},
+		{name: "No pattern", window: models.MaintenanceWindow{Start: start, End: start.Add(time.Hour)}, wantErr: true},
+		{
+			name:    "Bad pattern",
+			window:  models.MaintenanceWindow{Pattern: "CPU[", Start: start, End: start.Add(time.Hour)},
+			wantErr: true,
+		},
+		{
+			name:    "Unknown mode",
+			window:  models.MaintenanceWindow{Pattern: "*", Mode: "drop", Start: start, End: start.Add(time.Hour)},
+			wantErr: true,
+		},
+		{name: "Empty range", window: models.MaintenanceWindow{Pattern: "*", Start: start, End: start}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := Validate(&tt.window)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.Nil(t, err)
+			assert.Equal(t, tt.wantMode, tt.window.Mode)
+		})
+	}
+}
+
+func TestSchedule(t *testing.T) {
+	start := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+	s := NewSchedule()
+	s.SetWindows([]models.MaintenanceWindow{
+		{Pattern: "CPUutilization*", Mode: ModeFlag, Start: start, End: start.Add(time.Hour)},
+		{Pattern: "CPUutilization2", Mode: ModeReject, Start: start, End: start.Add(time.Hour)},
+	})
+
+	assert.Equal(t, "", s.Mode("CPUutilization1", start.Add(-time.Second)))
+	assert.Equal(t, ModeFlag, s.Mode("CPUutilization1", start))
+	assert.Equal(t, ModeReject, s.Mode("CPUutilization2", start))
+	assert.Equal(t, "", s.Mode("CPUutilization1", start.Add(time.Hour)))
+	assert.Equal(t, "", s.Mode("FreeMemory", start))
+
+	flagged, ok := s.Admit("CPUutilization1", start)
+	assert.True(t, ok)
+	assert.True(t, flagged)
+	assert.False(t, s.Flagged("gauge", "CPUutilization1"), "flag is set only by Record")
+	s.Record("gauge", "CPUutilization1", flagged)
+	assert.True(t, s.Flagged("gauge", "CPUutilization1"))
+	assert.False(t, s.Flagged("counter", "CPUutilization1"))
+	_, ok = s.Admit("CPUutilization2", start)
+	assert.False(t, ok)
+	flagged, ok = s.Admit("CPUutilization1", start.Add(2*time.Hour))
+	assert.True(t, ok)
+	assert.False(t, flagged)
+	s.Record("gauge", "CPUutilization1", flagged)
+	assert.False(t, s.Flagged("gauge", "CPUutilization1"))
+}
+
+func TestSchedule_Flags(t *testing.T) {
+	start := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+	s := NewSchedule()
+	s.SetWindows([]models.MaintenanceWindow{
+		{Pattern: "Alloc", Mode: ModeFlag, Start: start, End: start.Add(time.Hour)},
+	})
+	var (
+		mux     sync.Mutex
+		changes []string
+	)
+	s.OnFlag(func(key string, flagged bool) {
+		mux.Lock()
+		defer mux.Unlock()
+		changes = append(changes, key+" "+strconv.FormatBool(flagged))
+	})
+	record := func(name string, now time.Time) {
+		flagged, ok := s.Admit(name, now)
+		require.True(t, ok)
+		s.Record("gauge", name, flagged)
+	}
+	record("Alloc", start)
+	record("Alloc", start.Add(time.Minute))
+	s.Flush()
+	record("Alloc", start.Add(2*time.Hour))
+	record("Free", start)
+	s.Flush()
+	mux.Lock()
+	assert.Equal(t, []string{"gauge/Alloc true", "gauge/Alloc false"}, changes, "only changes are reported")
+	mux.Unlock()
+
+	// последним сохраняется последнее состояние пометки
+	s.Record("gauge", "Free", true)
+	s.Record("gauge", "Free", false)
+	s.Record("gauge", "Free", true)
+	s.Flush()
+	mux.Lock()
+	assert.Equal(t, "gauge/Free true", changes[len(changes)-1])
+	mux.Unlock()
+
+	restored := NewSchedule()
+	restored.SetFlags([]string{Key("counter", "PollCount")})
+	assert.True(t, restored.Flagged("counter", "PollCount"))
+	assert.False(t, restored.Flagged("gauge", "PollCount"))
+}
@@ -0,0 +1,33 @@
+package memstorage
+
+import (
+	"sort"
+
+	"github.com/NikolayStrekalov/vigilant-octo-waddle.git/internal/models"
+)
+
+func (m *MemStorage) GetAgents() ([]models.Agent, error) {
+	m.muxAgents.RLock()
+	ret := make([]models.Agent, 0, len(m.Agents))
+	for _, a := range m.Agents {
+		ret = append(ret, *a)
+	}
+	m.muxAgents.RUnlock()
+	sort.Slice(ret, func(i, j int) bool { return ret[i].ID < ret[j].ID })
+	return ret, nil
+}
+
+// SeenAgent записывает в реестр данные агента из последней пачки, время первого появления сохраняется.
+func (m *MemStorage) SeenAgent(agent *models.Agent) error {
+	m.muxAgents.Lock()
+	seen := *agent
+	if stored, ok := m.Agents[agent.ID]; ok {
+		seen.FirstSeen = stored.FirstSeen
+	}
+	m.Agents[agent.ID] = &seen
+	m.muxAgents.Unlock()
+	if m.sync {
+		m.dump()
+	}
+	return nil
+}
@@ -0,0 +1,76 @@
+package memstorage
+
+import (
+	"github.com/NikolayStrekalov/vigilant-octo-waddle.git/internal/models"
+)
+
+func (m *MemStorage) GetAlertRules() ([]models.AlertRule, error) {
+	m.muxRules.RLock()
+	defer m.muxRules.RUnlock()
+	ret := make([]models.AlertRule, len(m.AlertRules))
+	copy(ret, m.AlertRules)
+	return ret, nil
+}
+
+func (m *MemStorage) GetAlertRule(id int64) (models.AlertRule, error) {
+	m.muxRules.RLock()
+	defer m.muxRules.RUnlock()
+	if i := m.alertRuleIndex(id); i >= 0 {
+		return m.AlertRules[i], nil
+	}
+	return models.AlertRule{}, errNotFound
+}
+
+func (m *MemStorage) AddAlertRule(rule *models.AlertRule) (models.AlertRule, error) {
+	m.muxRules.Lock()
+	m.LastAlertRuleID++
+	added := *rule
+	added.ID = m.LastAlertRuleID
+	m.AlertRules = append(m.AlertRules, added)
+	m.muxRules.Unlock()
+	if m.sync {
+		m.dump()
+	}
+	return added, nil
+}
+
+func (m *MemStorage) UpdateAlertRule(rule *models.AlertRule) error {
+	m.muxRules.Lock()
+	i := m.alertRuleIndex(rule.ID)
+	if i >= 0 {
+		m.AlertRules[i] = *rule
+	}
+	m.muxRules.Unlock()
+	if i < 0 {
+		return errNotFound
+	}
+	if m.sync {
+		m.dump()
+	}
+	return nil
+}
+
+func (m *MemStorage) DeleteAlertRule(id int64) error {
+	m.muxRules.Lock()
+	i := m.alertRuleIndex(id)
+	if i >= 0 {
+		m.AlertRules = append(m.AlertRules[:i], m.AlertRules[i+1:]...)
+	}
+	m.muxRules.Unlock()
+	if i < 0 {
+		return errNotFound
+	}
+	if m.sync {
+		m.dump()
+	}
+	return nil
+}
+
+func (m *MemStorage) alertRuleIndex(id int64) int {
+	for i := range m.AlertRules {
+		if m.AlertRules[i].ID == id {
+			return i
+		}
+	}
+	return -1
+}
@@ -0,0 +1,103 @@
+package memstorage
+
+import (
+	"fmt"
+	"path"
+	"sync"
+	"time"
+)
+
+// DeleteMetric удаляет серию name типа kind вместе с её историей.
+func (m *MemStorage) DeleteMetric(kind, name string) error {
+	if m.deleteSeries(kind, func(key string) bool { return key == name }) == 0 {
+		return errNotFound
+	}
+	if m.sync {
+		m.dump()
+	}
+	return nil
+}
+
+// DeleteMetrics удаляет серии всех типов, ключи которых подходят под шаблон path.Match,
+// и возвращает число удалённых серий.
+func (m *MemStorage) DeleteMetrics(pattern string) (int64, error) {
+	if _, err := path.Match(pattern, ""); err != nil {
+		return 0, fmt.Errorf("wrong pattern %q: %w", pattern, err)
+	}
+	match := func(key string) bool {
+		ok, _ := path.Match(pattern, key)
+		return ok
+	}
+	var deleted int64
+	for _, kind := range []string{gaugeKind, counterKind, histogramKind, summaryKind, setKind} {
+		deleted += m.deleteSeries(kind, match)
+	}
+	if deleted > 0 && m.sync {
+		m.dump()
+	}
+	return deleted, nil
+}
+
+// ResetCounter обнуляет счётчик. Последнее накопительное значение сохраняется,
+// чтобы следующее значение источника прибавило только свой рост.
+func (m *MemStorage) ResetCounter(name string) error {
+	m.muxCounter.Lock()
+	_, ok := m.Counter[name]
+	if ok {
+		m.Counter[name] = 0
+		m.CounterUpdated[name] = time.Now()
+	}
+	m.muxCounter.Unlock()
+	if !ok {
+		return errNotFound
+	}
+	m.record(counterKind, name, 0)
+	if m.sync {
+		m.dump()
+	}
+	return nil
+}
+
+func (m *MemStorage) deleteSeries(kind string, match func(string) bool) int64 {
+	var keys []string
+	switch kind {
+	case gaugeKind:
+		keys = deleteKeys(m.muxGauge, m.Gauge, match, func(key string) { delete(m.GaugeUpdated, key) })
+	case counterKind:
+		keys = deleteKeys(m.muxCounter, m.Counter, match, func(key string) {
+			delete(m.CounterTotal, key)
+			delete(m.CounterUpdated, key)
+		})
+	case histogramKind:
+		keys = deleteKeys(m.muxHistogram, m.Histogram, match, nil)
+	case summaryKind:
+		keys = deleteKeys(m.muxSummary, m.Summary, match, nil)
+	case setKind:
+		keys = deleteKeys(m.muxSet, m.Set, match, nil)
+	}
+	m.muxHistory.Lock()
+	for _, key := range keys {
+		delete(m.History, seriesKey(kind, key))
+	}
+	m.muxHistory.Unlock()
+	return int64(len(keys))
+}
+
+// deleteKeys удаляет из values ключи, подходящие под match, и возвращает их.
+// Функция also вызывается для каждого ключа под той же блокировкой.
+func deleteKeys[T any](mux *sync.RWMutex, values map[string]T, match func(string) bool, also func(string)) []string {
+	mux.Lock()
+	defer mux.Unlock()
+	var keys []string
+	for key := range values {
+		if !match(key) {
+			continue
+		}
+		delete(values, key)
+		if also != nil {
+			also(key)
+		}
+		keys = append(keys, key)
+	}
+	return keys
+}
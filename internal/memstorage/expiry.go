@@ -0,0 +1,54 @@
+package memstorage
+
+import "time"
+
+// SetStaleTTL скрывает из списков gauge и counter, не обновлявшиеся дольше ttl. Ноль отключает скрытие.
+func (m *MemStorage) SetStaleTTL(ttl time.Duration) {
+	m.muxCounter.Lock()
+	m.muxGauge.Lock()
+	m.staleTTL = ttl
+	m.muxGauge.Unlock()
+	m.muxCounter.Unlock()
+}
+
+// freshSince возвращает время, раньше которого серия считается устаревшей.
+// Вызывается под блокировкой gauge или counter.
+func (m *MemStorage) freshSince() time.Time {
+	if m.staleTTL <= 0 {
+		return time.Time{}
+	}
+	return time.Now().Add(-m.staleTTL)
+}
+
+// ExpireMetrics удаляет вместе с историей gauge и counter, не обновлявшиеся с before,
+// и возвращает число удалённых серий.
+func (m *MemStorage) ExpireMetrics(before time.Time) (int64, error) {
+	deleted := m.deleteSeries(gaugeKind, func(key string) bool { return m.GaugeUpdated[key].Before(before) })
+	deleted += m.deleteSeries(counterKind, func(key string) bool { return m.CounterUpdated[key].Before(before) })
+	if deleted > 0 && m.sync {
+		m.dump()
+	}
+	return deleted, nil
+}
+
+// touchRestored отмечает обновлёнными в now серии из дампа без времени обновления,
+// чтобы дамп прежнего формата не устарел целиком сразу после загрузки.
+// Вызывается под блокировкой всего хранилища.
+func (m *MemStorage) touchRestored(now time.Time) {
+	if m.GaugeUpdated == nil {
+		m.GaugeUpdated = make(map[string]time.Time, len(m.Gauge))
+	}
+	if m.CounterUpdated == nil {
+		m.CounterUpdated = make(map[string]time.Time, len(m.Counter))
+	}
+	for key := range m.Gauge {
+		if _, ok := m.GaugeUpdated[key]; !ok {
+			m.GaugeUpdated[key] = now
+		}
+	}
+	for key := range m.Counter {
+		if _, ok := m.CounterUpdated[key]; !ok {
+			m.CounterUpdated[key] = now
+		}
+	}
+}
@@ -0,0 +1,77 @@
+package memstorage
+
+import (
+	"github.com/NikolayStrekalov/vigilant-octo-waddle.git/internal/models"
+)
+
+func (m *MemStorage) GetMaintenanceWindows() ([]models.MaintenanceWindow, error) {
+	m.muxMaintenance.RLock()
+	defer m.muxMaintenance.RUnlock()
+	ret := make([]models.MaintenanceWindow, len(m.MaintenanceWindows))
+	copy(ret, m.MaintenanceWindows)
+	return ret, nil
+}
+
+func (m *MemStorage) AddMaintenanceWindow(window *models.MaintenanceWindow) (models.MaintenanceWindow, error) {
+	m.muxMaintenance.Lock()
+	m.LastMaintenanceID++
+	added := *window
+	added.ID = m.LastMaintenanceID
+	m.MaintenanceWindows = append(m.MaintenanceWindows, added)
+	m.muxMaintenance.Unlock()
+	if m.sync {
+		m.dump()
+	}
+	return added, nil
+}
+
+func (m *MemStorage) DeleteMaintenanceWindow(id int64) error {
+	m.muxMaintenance.Lock()
+	i := -1
+	for j := range m.MaintenanceWindows {
+		if m.MaintenanceWindows[j].ID == id {
+			i = j
+			break
+		}
+	}
+	if i >= 0 {
+		m.MaintenanceWindows = append(m.MaintenanceWindows[:i], m.MaintenanceWindows[i+1:]...)
+	}
+	m.muxMaintenance.Unlock()
+	if i < 0 {
+		return errNotFound
+	}
+	if m.sync {
+		m.dump()
+	}
+	return nil
+}
+
+// GetMaintenanceFlags возвращает ключи метрик, помеченных во время обслуживания.
+func (m *MemStorage) GetMaintenanceFlags() ([]string, error) {
+	m.muxMaintenance.RLock()
+	defer m.muxMaintenance.RUnlock()
+	ret := make([]string, 0, len(m.MaintenanceFlags))
+	for key := range m.MaintenanceFlags {
+		ret = append(ret, key)
+	}
+	return ret, nil
+}
+
+// SetMaintenanceFlag ставит или снимает пометку метрики.
+func (m *MemStorage) SetMaintenanceFlag(key string, flagged bool) error {
+	m.muxMaintenance.Lock()
+	if m.MaintenanceFlags == nil {
+		m.MaintenanceFlags = make(map[string]bool)
+	}
+	if flagged {
+		m.MaintenanceFlags[key] = true
+	} else {
+		delete(m.MaintenanceFlags, key)
+	}
+	m.muxMaintenance.Unlock()
+	if m.sync {
+		m.dump()
+	}
+	return nil
+}
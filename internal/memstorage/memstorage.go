@@ -4,32 +4,70 @@ import (
 	"errors"
 	"fmt"
 	"os"
+	"strconv"
 	"sync"
 	"time"
 
+	"github.com/NikolayStrekalov/vigilant-octo-waddle.git/internal/histogram"
+	"github.com/NikolayStrekalov/vigilant-octo-waddle.git/internal/history"
+	"github.com/NikolayStrekalov/vigilant-octo-waddle.git/internal/hll"
 	"github.com/NikolayStrekalov/vigilant-octo-waddle.git/internal/logger"
 	"github.com/NikolayStrekalov/vigilant-octo-waddle.git/internal/models"
+	"github.com/NikolayStrekalov/vigilant-octo-waddle.git/internal/sketch"
 	"github.com/mailru/easyjson"
 )
 
-const dumpFilePermissions = 0o600
+const (
+	dumpFilePermissions = 0o600
+	historyFileSuffix   = ".history"
+)
 
 //easyjson:json
 type MemStorage struct {
-	Gauge         map[string]float64
-	Counter       map[string]int64
-	muxGauge      *sync.RWMutex
-	muxCounter    *sync.RWMutex
-	dumpFile      string
-	sync          bool
-	storeInterval time.Duration
+	Gauge              map[string]float64
+	Counter            map[string]int64
+	CounterTotal       map[string]int64             `json:",omitempty"`
+	GaugeUpdated       map[string]time.Time         `json:",omitempty"`
+	CounterUpdated     map[string]time.Time         `json:",omitempty"`
+	Histogram          map[string]*models.Histogram `json:",omitempty"`
+	Summary            map[string]*models.Sketch    `json:",omitempty"`
+	Set                map[string]*models.HLL       `json:",omitempty"`
+	History            map[string]*history.Series   `json:"-"` // хранится в отдельном файле, см. dumpHistory
+	Batches            map[string]time.Time         `json:",omitempty"`
+	Agents             map[string]*models.Agent     `json:",omitempty"`
+	muxBatches         *sync.RWMutex
+	muxGauge           *sync.RWMutex
+	muxCounter         *sync.RWMutex
+	muxHistogram       *sync.RWMutex
+	muxSummary         *sync.RWMutex
+	muxSet             *sync.RWMutex
+	muxHistory         *sync.RWMutex
+	muxRules           *sync.RWMutex
+	muxMaintenance     *sync.RWMutex
+	muxAgents          *sync.RWMutex
+	policy             *history.Policy
+	staleTTL           time.Duration
+	dumpFile           string
+	AlertRules         []models.AlertRule         `json:",omitempty"`
+	MaintenanceWindows []models.MaintenanceWindow `json:",omitempty"`
+	MaintenanceFlags   map[string]bool            `json:",omitempty"`
+	LastAlertRuleID    int64                      `json:",omitempty"`
+	LastMaintenanceID  int64                      `json:",omitempty"`
+	sync               bool
+	storeInterval      time.Duration
 }
 
 var errNotFound = errors.New("not found")
 
+// ErrNotSupported возвращается, если история не включена вызовом SetHistoryPolicy.
+var ErrNotSupported = errors.New("history is not enabled for memory storage")
+
 const (
-	gaugeKind   = "gauge"
-	counterKind = "counter"
+	gaugeKind     = "gauge"
+	counterKind   = "counter"
+	histogramKind = "histogram"
+	summaryKind   = "summary"
+	setKind       = "set"
 )
 
 type GaugeListItem = struct {
@@ -46,13 +84,30 @@ type MetricsSlice = []struct {
 
 func NewMemStorage(dumpPath string, restore bool, storeInterval int) (*MemStorage, func() error, error) {
 	storage := MemStorage{
-		Gauge:         make(map[string]float64),
-		Counter:       make(map[string]int64),
-		muxGauge:      &sync.RWMutex{},
-		muxCounter:    &sync.RWMutex{},
-		sync:          dumpPath != "" && storeInterval == 0,
-		dumpFile:      dumpPath,
-		storeInterval: time.Duration(storeInterval) * time.Second,
+		Gauge:          make(map[string]float64),
+		Counter:        make(map[string]int64),
+		CounterTotal:   make(map[string]int64),
+		GaugeUpdated:   make(map[string]time.Time),
+		CounterUpdated: make(map[string]time.Time),
+		Histogram:      make(map[string]*models.Histogram),
+		Summary:        make(map[string]*models.Sketch),
+		Set:            make(map[string]*models.HLL),
+		History:        make(map[string]*history.Series),
+		Batches:        make(map[string]time.Time),
+		Agents:         make(map[string]*models.Agent),
+		muxBatches:     &sync.RWMutex{},
+		muxGauge:       &sync.RWMutex{},
+		muxCounter:     &sync.RWMutex{},
+		muxHistogram:   &sync.RWMutex{},
+		muxSummary:     &sync.RWMutex{},
+		muxSet:         &sync.RWMutex{},
+		muxHistory:     &sync.RWMutex{},
+		muxRules:       &sync.RWMutex{},
+		muxMaintenance: &sync.RWMutex{},
+		muxAgents:      &sync.RWMutex{},
+		sync:           dumpPath != "" && storeInterval == 0,
+		dumpFile:       dumpPath,
+		storeInterval:  time.Duration(storeInterval) * time.Second,
 	}
 	if restore {
 		storage.restore()
@@ -62,6 +117,7 @@ func NewMemStorage(dumpPath string, restore bool, storeInterval int) (*MemStorag
 	}
 	var closeStorage = func() error {
 		storage.dump()
+		storage.dumpHistory()
 		return nil
 	}
 	return &storage, closeStorage, nil
@@ -71,7 +127,11 @@ func (m *MemStorage) GetGaugeList() []GaugeListItem {
 	m.muxGauge.RLock()
 	defer m.muxGauge.RUnlock()
 	items := make([]GaugeListItem, 0, len(m.Gauge))
+	since := m.freshSince()
 	for name, value := range m.Gauge {
+		if m.GaugeUpdated[name].Before(since) {
+			continue
+		}
 		items = append(items, GaugeListItem{Name: name, Value: value})
 	}
 	return items
@@ -86,12 +146,61 @@ func (m *MemStorage) GetCounterList() []CounterListItem {
 	m.muxCounter.RLock()
 	defer m.muxCounter.RUnlock()
 	items := make([]CounterListItem, 0, len(m.Counter))
+	since := m.freshSince()
 	for name, value := range m.Counter {
+		if m.CounterUpdated[name].Before(since) {
+			continue
+		}
 		items = append(items, CounterListItem{Name: name, Value: value})
 	}
 	return items
 }
 
+type HistogramListItem = struct {
+	Name  string
+	Value models.Histogram
+}
+
+func (m *MemStorage) GetHistogramList() []HistogramListItem {
+	m.muxHistogram.RLock()
+	defer m.muxHistogram.RUnlock()
+	items := make([]HistogramListItem, 0, len(m.Histogram))
+	for name, h := range m.Histogram {
+		items = append(items, HistogramListItem{Name: name, Value: copyHistogram(h)})
+	}
+	return items
+}
+
+type SummaryListItem = struct {
+	Name  string
+	Value models.Sketch
+}
+
+func (m *MemStorage) GetSummaryList() []SummaryListItem {
+	m.muxSummary.RLock()
+	defer m.muxSummary.RUnlock()
+	items := make([]SummaryListItem, 0, len(m.Summary))
+	for name, s := range m.Summary {
+		items = append(items, SummaryListItem{Name: name, Value: sketch.Copy(s)})
+	}
+	return items
+}
+
+type SetListItem = struct {
+	Name  string
+	Value models.HLL
+}
+
+func (m *MemStorage) GetSetList() []SetListItem {
+	m.muxSet.RLock()
+	defer m.muxSet.RUnlock()
+	items := make([]SetListItem, 0, len(m.Set))
+	for name, h := range m.Set {
+		items = append(items, SetListItem{Name: name, Value: copyHLL(h)})
+	}
+	return items
+}
+
 func (m *MemStorage) GetGauge(name string) (float64, error) {
 	m.muxGauge.RLock()
 	defer m.muxGauge.RUnlock()
@@ -110,10 +219,53 @@ func (m *MemStorage) GetCounter(name string) (int64, error) {
 	return 0, errNotFound
 }
 
+func (m *MemStorage) GetHistogram(name string) (models.Histogram, error) {
+	m.muxHistogram.RLock()
+	defer m.muxHistogram.RUnlock()
+	if h, ok := m.Histogram[name]; ok {
+		return copyHistogram(h), nil
+	}
+	return models.Histogram{}, errNotFound
+}
+
+func (m *MemStorage) GetSummary(name string) (models.Sketch, error) {
+	m.muxSummary.RLock()
+	defer m.muxSummary.RUnlock()
+	if s, ok := m.Summary[name]; ok {
+		return sketch.Copy(s), nil
+	}
+	return models.Sketch{}, errNotFound
+}
+
+func (m *MemStorage) GetSet(name string) (models.HLL, error) {
+	m.muxSet.RLock()
+	defer m.muxSet.RUnlock()
+	if h, ok := m.Set[name]; ok {
+		return copyHLL(h), nil
+	}
+	return models.HLL{}, errNotFound
+}
+
+func copyHLL(h *models.HLL) models.HLL {
+	return models.HLL{Registers: append([]byte{}, h.Registers...), Precision: h.Precision}
+}
+
+// copyHistogram копирует гистограмму, чтобы её можно было читать без блокировки.
+func copyHistogram(h *models.Histogram) models.Histogram {
+	return models.Histogram{
+		Bounds: append([]float64{}, h.Bounds...),
+		Counts: append([]uint64{}, h.Counts...),
+		Sum:    h.Sum,
+		Count:  h.Count,
+	}
+}
+
 func (m *MemStorage) UpdateGauge(name string, value float64) {
 	m.muxGauge.Lock()
 	m.Gauge[name] = value
+	m.GaugeUpdated[name] = time.Now()
 	m.muxGauge.Unlock()
+	m.record(gaugeKind, name, value)
 	if m.sync {
 		m.dump()
 	}
@@ -122,49 +274,292 @@ func (m *MemStorage) UpdateGauge(name string, value float64) {
 func (m *MemStorage) IncrementCounter(name string, value int64) {
 	m.muxCounter.Lock()
 	m.Counter[name] += value
+	m.CounterUpdated[name] = time.Now()
+	total := m.Counter[name]
 	m.muxCounter.Unlock()
+	m.record(counterKind, name, float64(total))
 	if m.sync {
 		m.dump()
 	}
 }
 
+// AddCounterTotal прибавляет к счётчику рост накопительного значения total с прошлого раза.
+func (m *MemStorage) AddCounterTotal(name string, total int64) {
+	m.muxCounter.Lock()
+	m.addTotal(name, total)
+	m.CounterUpdated[name] = time.Now()
+	value := m.Counter[name]
+	m.muxCounter.Unlock()
+	m.record(counterKind, name, float64(value))
+	if m.sync {
+		m.dump()
+	}
+}
+
+// addTotal запоминает накопительное значение серии и прибавляет к счётчику его рост.
+// Уменьшение значения считается сбросом источника, тогда приращением становится всё значение,
+// как и для первого значения серии. Повтор того же значения ничего не добавляет.
+func (m *MemStorage) addTotal(name string, total int64) {
+	last, ok := m.CounterTotal[name]
+	m.CounterTotal[name] = total
+	if ok && total >= last {
+		total -= last
+	}
+	m.Counter[name] += total
+}
+
+// UpdateHistogram прибавляет к гистограмме приращение delta.
+// При других границах корзин возвращается histogram.ErrBoundsMismatch.
+func (m *MemStorage) UpdateHistogram(name string, delta *models.Histogram) error {
+	m.muxHistogram.Lock()
+	err := m.mergeHistogram(name, delta)
+	m.muxHistogram.Unlock()
+	if err != nil {
+		return fmt.Errorf("failed to update histogram %s: %w", name, err)
+	}
+	if m.sync {
+		m.dump()
+	}
+	return nil
+}
+
+func (m *MemStorage) mergeHistogram(name string, delta *models.Histogram) error {
+	h, ok := m.Histogram[name]
+	if !ok {
+		h = &models.Histogram{}
+		m.Histogram[name] = h
+	}
+	return histogram.Merge(h, delta)
+}
+
+// UpdateSummary сливает набросок summary с присланным наброском.
+// При другой точности возвращается sketch.ErrAlphaMismatch.
+func (m *MemStorage) UpdateSummary(name string, s *models.Sketch) error {
+	m.muxSummary.Lock()
+	err := m.mergeSummary(name, s)
+	m.muxSummary.Unlock()
+	if err != nil {
+		return fmt.Errorf("failed to update summary %s: %w", name, err)
+	}
+	if m.sync {
+		m.dump()
+	}
+	return nil
+}
+
+func (m *MemStorage) mergeSummary(name string, s *models.Sketch) error {
+	stored, ok := m.Summary[name]
+	if !ok {
+		stored = &models.Sketch{}
+		m.Summary[name] = stored
+	}
+	return sketch.Merge(stored, s)
+}
+
+// UpdateSet добавляет в set значения из наброска h.
+func (m *MemStorage) UpdateSet(name string, h *models.HLL) {
+	m.muxSet.Lock()
+	m.mergeSet(name, h)
+	m.muxSet.Unlock()
+	if m.sync {
+		m.dump()
+	}
+}
+
+func (m *MemStorage) mergeSet(name string, h *models.HLL) {
+	stored, ok := m.Set[name]
+	if !ok {
+		stored = &models.HLL{}
+		m.Set[name] = stored
+	}
+	hll.Merge(stored, h)
+}
+
 func (m *MemStorage) BulkUpdate(metrics models.MetricsSlice) {
+	m.bulkUpdate(metrics)
+	if m.sync {
+		m.dump()
+	}
+}
+
+// BulkUpdateOnce применяет пачку с ключом key и номером seq, если она ещё не применялась.
+// Возвращает false для повтора.
+func (m *MemStorage) BulkUpdateOnce(key string, seq int64, metrics models.MetricsSlice) (bool, error) {
+	id := key + "/" + strconv.FormatInt(seq, 10)
+	m.muxBatches.Lock()
+	if _, ok := m.Batches[id]; ok {
+		m.muxBatches.Unlock()
+		return false, nil
+	}
+	m.Batches[id] = time.Now()
+	m.bulkUpdate(metrics)
+	m.muxBatches.Unlock()
+	if m.sync {
+		m.dump()
+	}
+	return true, nil
+}
+
+// ForgetBatches удаляет ключи пачек, применённых раньше before.
+func (m *MemStorage) ForgetBatches(before time.Time) error {
+	m.muxBatches.Lock()
+	defer m.muxBatches.Unlock()
+	for id, applied := range m.Batches {
+		if applied.Before(before) {
+			delete(m.Batches, id)
+		}
+	}
+	return nil
+}
+
+func (m *MemStorage) bulkUpdate(metrics models.MetricsSlice) {
 	m.muxCounter.Lock()
 	m.muxGauge.Lock()
+	m.muxHistogram.Lock()
+	m.muxSummary.Lock()
+	m.muxSet.Lock()
+	now := time.Now()
 	for _, metric := range metrics {
 		switch metric.MType {
 		case counterKind:
-			if metric.Delta == nil {
+			key := metric.Key()
+			switch {
+			case metric.Total != nil:
+				m.addTotal(key, *metric.Total)
+			case metric.Delta != nil:
+				m.Counter[key] += *metric.Delta
+			default:
 				continue
 			}
-			m.Counter[metric.ID] += *metric.Delta
+			m.CounterUpdated[key] = now
+			m.record(counterKind, key, float64(m.Counter[key]))
 
 		case gaugeKind:
 			if metric.Value == nil {
 				continue
 			}
-			m.Gauge[metric.ID] = *metric.Value
+			key := metric.Key()
+			m.Gauge[key] = *metric.Value
+			m.GaugeUpdated[key] = now
+			m.record(gaugeKind, key, *metric.Value)
+		case histogramKind:
+			if metric.Histogram == nil {
+				continue
+			}
+			if err := m.mergeHistogram(metric.Key(), metric.Histogram); err != nil {
+				logger.Info("skip histogram update:", metric.Key(), err)
+			}
+		case summaryKind:
+			if metric.Sketch == nil {
+				continue
+			}
+			if err := m.mergeSummary(metric.Key(), metric.Sketch); err != nil {
+				logger.Info("skip summary update:", metric.Key(), err)
+			}
+		case setKind:
+			if metric.HLL == nil {
+				continue
+			}
+			m.mergeSet(metric.Key(), metric.HLL)
 		default:
 			continue
 		}
 	}
+	m.muxSet.Unlock()
+	m.muxSummary.Unlock()
+	m.muxHistogram.Unlock()
 	m.muxGauge.Unlock()
 	m.muxCounter.Unlock()
+}
+
+// SetHistoryPolicy включает запись истории значений с указанной политикой хранения.
+func (m *MemStorage) SetHistoryPolicy(p history.Policy) {
+	m.muxHistory.Lock()
+	defer m.muxHistory.Unlock()
+	m.policy = &p
+}
+
+func (m *MemStorage) record(kind, name string, value float64) {
+	m.muxHistory.Lock()
+	defer m.muxHistory.Unlock()
+	if m.policy == nil {
+		return
+	}
+	key := seriesKey(kind, name)
+	series, ok := m.History[key]
+	if !ok {
+		series = &history.Series{}
+		m.History[key] = series
+	}
+	series.Add(time.Now(), value)
+}
+
+func (m *MemStorage) GetHistory(
+	kind, name string, from, to time.Time, step time.Duration,
+) ([]models.HistoryPoint, error) {
+	m.muxHistory.RLock()
+	defer m.muxHistory.RUnlock()
+	if m.policy == nil {
+		return nil, ErrNotSupported
+	}
+	series, ok := m.History[seriesKey(kind, name)]
+	if !ok {
+		return []models.HistoryPoint{}, nil
+	}
+	return series.Query(*m.policy, from, to, step, time.Now()), nil
+}
+
+// GetCounterSamples возвращает сырые сэмплы counter за окно (from, to] вместе с последним сэмплом до окна.
+func (m *MemStorage) GetCounterSamples(name string, from, to time.Time) ([]models.HistoryPoint, error) {
+	m.muxHistory.RLock()
+	defer m.muxHistory.RUnlock()
+	if m.policy == nil {
+		return nil, ErrNotSupported
+	}
+	series, ok := m.History[seriesKey(counterKind, name)]
+	if !ok {
+		return []models.HistoryPoint{}, nil
+	}
+	return series.Window(from, to), nil
+}
+
+// CompactHistory строит свёртки за интервалы, завершившиеся после since, и удаляет устаревшие данные.
+// В синхронном режиме после сжатия история записывается в файл.
+func (m *MemStorage) CompactHistory(since, now time.Time) error {
+	if !m.compactHistory(since, now) {
+		return nil
+	}
 	if m.sync {
-		m.dump()
+		m.dumpHistory()
+	}
+	return nil
+}
+
+func (m *MemStorage) compactHistory(since, now time.Time) bool {
+	m.muxHistory.Lock()
+	defer m.muxHistory.Unlock()
+	if m.policy == nil {
+		return false
+	}
+	for key, series := range m.History {
+		series.Compact(*m.policy, since, now)
+		if series.Empty() {
+			delete(m.History, key)
+		}
 	}
+	return true
+}
+
+func seriesKey(kind, name string) string {
+	return kind + "/" + name
 }
 
 func (m *MemStorage) dump() {
 	if m.dumpFile == "" {
 		return
 	}
-	m.muxCounter.RLock()
-	m.muxGauge.RLock()
-	defer func() {
-		m.muxCounter.RUnlock()
-		m.muxGauge.RUnlock()
-	}()
+	m.rLockAll()
+	defer m.rUnlockAll()
 	data, err := easyjson.Marshal(m)
 	if err != nil {
 		logger.Info("Error converting storage to json.", err)
@@ -177,16 +572,58 @@ func (m *MemStorage) dump() {
 	}
 }
 
+// historyDump — история значений, которая хранится в отдельном файле рядом с дампом.
+//
+//easyjson:json
+type historyDump struct {
+	History map[string]*history.Series
+}
+
+// dumpHistory записывает историю в файл дампа с суффиксом .history. История не пишется при каждом
+// обновлении в синхронном режиме, а только при периодическом дампе, сжатии истории и остановке.
+func (m *MemStorage) dumpHistory() {
+	if m.dumpFile == "" {
+		return
+	}
+	m.muxHistory.RLock()
+	data, err := easyjson.Marshal(historyDump{History: m.History})
+	m.muxHistory.RUnlock()
+	if err != nil {
+		logger.Info("Error converting history to json.", err)
+		return
+	}
+	err = os.WriteFile(m.dumpFile+historyFileSuffix, data, dumpFilePermissions)
+	if err != nil {
+		logger.Info("Error writing history dump.", err)
+	}
+}
+
+// restoreHistory читает историю из файла. Вызывается под блокировкой.
+func (m *MemStorage) restoreHistory() {
+	data, err := os.ReadFile(m.dumpFile + historyFileSuffix)
+	if errors.Is(err, os.ErrNotExist) {
+		return
+	}
+	if err != nil {
+		logger.Info("Error reading history dump.", err)
+		return
+	}
+	d := historyDump{}
+	if err = easyjson.Unmarshal(data, &d); err != nil {
+		logger.Info("Error unmarshalling history dump.", err)
+		return
+	}
+	if d.History != nil {
+		m.History = d.History
+	}
+}
+
 func (m *MemStorage) restore() {
 	if m.dumpFile == "" {
 		return
 	}
-	m.muxCounter.Lock()
-	m.muxGauge.Lock()
-	defer func() {
-		m.muxCounter.Unlock()
-		m.muxGauge.Unlock()
-	}()
+	m.lockAll()
+	defer m.unlockAll()
 	data, err := os.ReadFile(m.dumpFile)
 	if err != nil {
 		logger.Info("Error reading dump.", err)
@@ -197,12 +634,67 @@ func (m *MemStorage) restore() {
 		logger.Info("Error unmarshalling dump.", err)
 		return
 	}
+	m.touchRestored(time.Now())
+	m.restoreHistory()
+}
+
+func (m *MemStorage) lockAll() {
+	m.muxBatches.Lock()
+	m.muxCounter.Lock()
+	m.muxGauge.Lock()
+	m.muxHistogram.Lock()
+	m.muxSummary.Lock()
+	m.muxSet.Lock()
+	m.muxHistory.Lock()
+	m.muxRules.Lock()
+	m.muxMaintenance.Lock()
+	m.muxAgents.Lock()
+}
+
+func (m *MemStorage) unlockAll() {
+	m.muxBatches.Unlock()
+	m.muxCounter.Unlock()
+	m.muxGauge.Unlock()
+	m.muxHistogram.Unlock()
+	m.muxSummary.Unlock()
+	m.muxSet.Unlock()
+	m.muxHistory.Unlock()
+	m.muxRules.Unlock()
+	m.muxMaintenance.Unlock()
+	m.muxAgents.Unlock()
+}
+
+func (m *MemStorage) rLockAll() {
+	m.muxBatches.RLock()
+	m.muxCounter.RLock()
+	m.muxGauge.RLock()
+	m.muxHistogram.RLock()
+	m.muxSummary.RLock()
+	m.muxSet.RLock()
+	m.muxHistory.RLock()
+	m.muxRules.RLock()
+	m.muxMaintenance.RLock()
+	m.muxAgents.RLock()
+}
+
+func (m *MemStorage) rUnlockAll() {
+	m.muxBatches.RUnlock()
+	m.muxCounter.RUnlock()
+	m.muxGauge.RUnlock()
+	m.muxHistogram.RUnlock()
+	m.muxSummary.RUnlock()
+	m.muxSet.RUnlock()
+	m.muxHistory.RUnlock()
+	m.muxRules.RUnlock()
+	m.muxMaintenance.RUnlock()
+	m.muxAgents.RUnlock()
 }
 
 func (m *MemStorage) periodicDump() {
 	for {
 		time.Sleep(m.storeInterval)
 		m.dump()
+		m.dumpHistory()
 	}
 }
 
@@ -4,9 +4,12 @@ package memstorage
 
 import (
 	json "encoding/json"
+	history "github.com/NikolayStrekalov/vigilant-octo-waddle.git/internal/history"
+	models "github.com/NikolayStrekalov/vigilant-octo-waddle.git/internal/models"
 	easyjson "github.com/mailru/easyjson"
 	jlexer "github.com/mailru/easyjson/jlexer"
 	jwriter "github.com/mailru/easyjson/jwriter"
+	time "time"
 )
 
 // suppress unused package warning
@@ -17,7 +20,396 @@ var (
 	_ easyjson.Marshaler
 )
 
-func easyjson8bb13b26DecodeGithubComNikolayStrekalovVigilantOctoWaddleGitInternalMemstorage(in *jlexer.Lexer, out *MemStorage) {
+func easyjson8bb13b26DecodeGithubComNikolayStrekalovVigilantOctoWaddleGitInternalMemstorage(in *jlexer.Lexer, out *historyDump) {
+	isTopLevel := in.IsStart()
+	if in.IsNull() {
+		if isTopLevel {
+			in.Consumed()
+		}
+		in.Skip()
+		return
+	}
+	in.Delim('{')
+	for !in.IsDelim('}') {
+		key := in.UnsafeFieldName(false)
+		in.WantColon()
+		if in.IsNull() {
+			in.Skip()
+			in.WantComma()
+			continue
+		}
+		switch key {
+		case "History":
+			if in.IsNull() {
+				in.Skip()
+			} else {
+				in.Delim('{')
+				out.History = make(map[string]*history.Series)
+				for !in.IsDelim('}') {
+					key := string(in.String())
+					in.WantColon()
+					var v1 *history.Series
+					if in.IsNull() {
+						in.Skip()
+						v1 = nil
+					} else {
+						if v1 == nil {
+							v1 = new(history.Series)
+						}
+						easyjson8bb13b26DecodeGithubComNikolayStrekalovVigilantOctoWaddleGitInternalHistory(in, v1)
+					}
+					(out.History)[key] = v1
+					in.WantComma()
+				}
+				in.Delim('}')
+			}
+		default:
+			in.SkipRecursive()
+		}
+		in.WantComma()
+	}
+	in.Delim('}')
+	if isTopLevel {
+		in.Consumed()
+	}
+}
+func easyjson8bb13b26EncodeGithubComNikolayStrekalovVigilantOctoWaddleGitInternalMemstorage(out *jwriter.Writer, in historyDump) {
+	out.RawByte('{')
+	first := true
+	_ = first
+	{
+		const prefix string = ",\"History\":"
+		out.RawString(prefix[1:])
+		if in.History == nil && (out.Flags&jwriter.NilMapAsEmpty) == 0 {
+			out.RawString(`null`)
+		} else {
+			out.RawByte('{')
+			v2First := true
+			for v2Name, v2Value := range in.History {
+				if v2First {
+					v2First = false
+				} else {
+					out.RawByte(',')
+				}
+				out.String(string(v2Name))
+				out.RawByte(':')
+				if v2Value == nil {
+					out.RawString("null")
+				} else {
+					easyjson8bb13b26EncodeGithubComNikolayStrekalovVigilantOctoWaddleGitInternalHistory(out, *v2Value)
+				}
+			}
+			out.RawByte('}')
+		}
+	}
+	out.RawByte('}')
+}
+
+// MarshalJSON supports json.Marshaler interface
+func (v historyDump) MarshalJSON() ([]byte, error) {
+	w := jwriter.Writer{}
+	easyjson8bb13b26EncodeGithubComNikolayStrekalovVigilantOctoWaddleGitInternalMemstorage(&w, v)
+	return w.Buffer.BuildBytes(), w.Error
+}
+
+// MarshalEasyJSON supports easyjson.Marshaler interface
+func (v historyDump) MarshalEasyJSON(w *jwriter.Writer) {
+	easyjson8bb13b26EncodeGithubComNikolayStrekalovVigilantOctoWaddleGitInternalMemstorage(w, v)
+}
+
+// UnmarshalJSON supports json.Unmarshaler interface
+func (v *historyDump) UnmarshalJSON(data []byte) error {
+	r := jlexer.Lexer{Data: data}
+	easyjson8bb13b26DecodeGithubComNikolayStrekalovVigilantOctoWaddleGitInternalMemstorage(&r, v)
+	return r.Error()
+}
+
+// UnmarshalEasyJSON supports easyjson.Unmarshaler interface
+func (v *historyDump) UnmarshalEasyJSON(l *jlexer.Lexer) {
+	easyjson8bb13b26DecodeGithubComNikolayStrekalovVigilantOctoWaddleGitInternalMemstorage(l, v)
+}
+func easyjson8bb13b26DecodeGithubComNikolayStrekalovVigilantOctoWaddleGitInternalHistory(in *jlexer.Lexer, out *history.Series) {
+	isTopLevel := in.IsStart()
+	if in.IsNull() {
+		if isTopLevel {
+			in.Consumed()
+		}
+		in.Skip()
+		return
+	}
+	in.Delim('{')
+	for !in.IsDelim('}') {
+		key := in.UnsafeFieldName(false)
+		in.WantColon()
+		if in.IsNull() {
+			in.Skip()
+			in.WantComma()
+			continue
+		}
+		switch key {
+		case "rollups":
+			if in.IsNull() {
+				in.Skip()
+			} else {
+				in.Delim('{')
+				if !in.IsDelim('}') {
+					out.Rollups = make(map[string][]history.Rollup)
+				} else {
+					out.Rollups = nil
+				}
+				for !in.IsDelim('}') {
+					key := string(in.String())
+					in.WantColon()
+					var v3 []history.Rollup
+					if in.IsNull() {
+						in.Skip()
+						v3 = nil
+					} else {
+						in.Delim('[')
+						if v3 == nil {
+							if !in.IsDelim(']') {
+								v3 = make([]history.Rollup, 0, 1)
+							} else {
+								v3 = []history.Rollup{}
+							}
+						} else {
+							v3 = (v3)[:0]
+						}
+						for !in.IsDelim(']') {
+							var v4 history.Rollup
+							easyjson8bb13b26DecodeGithubComNikolayStrekalovVigilantOctoWaddleGitInternalHistory1(in, &v4)
+							v3 = append(v3, v4)
+							in.WantComma()
+						}
+						in.Delim(']')
+					}
+					(out.Rollups)[key] = v3
+					in.WantComma()
+				}
+				in.Delim('}')
+			}
+		case "raw":
+			if in.IsNull() {
+				in.Skip()
+				out.Raw = nil
+			} else {
+				in.Delim('[')
+				if out.Raw == nil {
+					if !in.IsDelim(']') {
+						out.Raw = make([]history.Sample, 0, 2)
+					} else {
+						out.Raw = []history.Sample{}
+					}
+				} else {
+					out.Raw = (out.Raw)[:0]
+				}
+				for !in.IsDelim(']') {
+					var v5 history.Sample
+					easyjson8bb13b26DecodeGithubComNikolayStrekalovVigilantOctoWaddleGitInternalHistory2(in, &v5)
+					out.Raw = append(out.Raw, v5)
+					in.WantComma()
+				}
+				in.Delim(']')
+			}
+		default:
+			in.SkipRecursive()
+		}
+		in.WantComma()
+	}
+	in.Delim('}')
+	if isTopLevel {
+		in.Consumed()
+	}
+}
+func easyjson8bb13b26EncodeGithubComNikolayStrekalovVigilantOctoWaddleGitInternalHistory(out *jwriter.Writer, in history.Series) {
+	out.RawByte('{')
+	first := true
+	_ = first
+	if len(in.Rollups) != 0 {
+		const prefix string = ",\"rollups\":"
+		first = false
+		out.RawString(prefix[1:])
+		{
+			out.RawByte('{')
+			v6First := true
+			for v6Name, v6Value := range in.Rollups {
+				if v6First {
+					v6First = false
+				} else {
+					out.RawByte(',')
+				}
+				out.String(string(v6Name))
+				out.RawByte(':')
+				if v6Value == nil && (out.Flags&jwriter.NilSliceAsEmpty) == 0 {
+					out.RawString("null")
+				} else {
+					out.RawByte('[')
+					for v7, v8 := range v6Value {
+						if v7 > 0 {
+							out.RawByte(',')
+						}
+						easyjson8bb13b26EncodeGithubComNikolayStrekalovVigilantOctoWaddleGitInternalHistory1(out, v8)
+					}
+					out.RawByte(']')
+				}
+			}
+			out.RawByte('}')
+		}
+	}
+	{
+		const prefix string = ",\"raw\":"
+		if first {
+			first = false
+			out.RawString(prefix[1:])
+		} else {
+			out.RawString(prefix)
+		}
+		if in.Raw == nil && (out.Flags&jwriter.NilSliceAsEmpty) == 0 {
+			out.RawString("null")
+		} else {
+			out.RawByte('[')
+			for v9, v10 := range in.Raw {
+				if v9 > 0 {
+					out.RawByte(',')
+				}
+				easyjson8bb13b26EncodeGithubComNikolayStrekalovVigilantOctoWaddleGitInternalHistory2(out, v10)
+			}
+			out.RawByte(']')
+		}
+	}
+	out.RawByte('}')
+}
+func easyjson8bb13b26DecodeGithubComNikolayStrekalovVigilantOctoWaddleGitInternalHistory2(in *jlexer.Lexer, out *history.Sample) {
+	isTopLevel := in.IsStart()
+	if in.IsNull() {
+		if isTopLevel {
+			in.Consumed()
+		}
+		in.Skip()
+		return
+	}
+	in.Delim('{')
+	for !in.IsDelim('}') {
+		key := in.UnsafeFieldName(false)
+		in.WantColon()
+		if in.IsNull() {
+			in.Skip()
+			in.WantComma()
+			continue
+		}
+		switch key {
+		case "t":
+			if data := in.Raw(); in.Ok() {
+				in.AddError((out.Time).UnmarshalJSON(data))
+			}
+		case "v":
+			out.Value = float64(in.Float64())
+		default:
+			in.SkipRecursive()
+		}
+		in.WantComma()
+	}
+	in.Delim('}')
+	if isTopLevel {
+		in.Consumed()
+	}
+}
+func easyjson8bb13b26EncodeGithubComNikolayStrekalovVigilantOctoWaddleGitInternalHistory2(out *jwriter.Writer, in history.Sample) {
+	out.RawByte('{')
+	first := true
+	_ = first
+	{
+		const prefix string = ",\"t\":"
+		out.RawString(prefix[1:])
+		out.Raw((in.Time).MarshalJSON())
+	}
+	{
+		const prefix string = ",\"v\":"
+		out.RawString(prefix)
+		out.Float64(float64(in.Value))
+	}
+	out.RawByte('}')
+}
+func easyjson8bb13b26DecodeGithubComNikolayStrekalovVigilantOctoWaddleGitInternalHistory1(in *jlexer.Lexer, out *history.Rollup) {
+	isTopLevel := in.IsStart()
+	if in.IsNull() {
+		if isTopLevel {
+			in.Consumed()
+		}
+		in.Skip()
+		return
+	}
+	in.Delim('{')
+	for !in.IsDelim('}') {
+		key := in.UnsafeFieldName(false)
+		in.WantColon()
+		if in.IsNull() {
+			in.Skip()
+			in.WantComma()
+			continue
+		}
+		switch key {
+		case "t":
+			if data := in.Raw(); in.Ok() {
+				in.AddError((out.Start).UnmarshalJSON(data))
+			}
+		case "min":
+			out.Min = float64(in.Float64())
+		case "max":
+			out.Max = float64(in.Float64())
+		case "sum":
+			out.Sum = float64(in.Float64())
+		case "last":
+			out.Last = float64(in.Float64())
+		case "count":
+			out.Count = int64(in.Int64())
+		default:
+			in.SkipRecursive()
+		}
+		in.WantComma()
+	}
+	in.Delim('}')
+	if isTopLevel {
+		in.Consumed()
+	}
+}
+func easyjson8bb13b26EncodeGithubComNikolayStrekalovVigilantOctoWaddleGitInternalHistory1(out *jwriter.Writer, in history.Rollup) {
+	out.RawByte('{')
+	first := true
+	_ = first
+	{
+		const prefix string = ",\"t\":"
+		out.RawString(prefix[1:])
+		out.Raw((in.Start).MarshalJSON())
+	}
+	{
+		const prefix string = ",\"min\":"
+		out.RawString(prefix)
+		out.Float64(float64(in.Min))
+	}
+	{
+		const prefix string = ",\"max\":"
+		out.RawString(prefix)
+		out.Float64(float64(in.Max))
+	}
+	{
+		const prefix string = ",\"sum\":"
+		out.RawString(prefix)
+		out.Float64(float64(in.Sum))
+	}
+	{
+		const prefix string = ",\"last\":"
+		out.RawString(prefix)
+		out.Float64(float64(in.Last))
+	}
+	{
+		const prefix string = ",\"count\":"
+		out.RawString(prefix)
+		out.Int64(int64(in.Count))
+	}
+	out.RawByte('}')
+}
+func easyjson8bb13b26DecodeGithubComNikolayStrekalovVigilantOctoWaddleGitInternalMemstorage1(in *jlexer.Lexer, out *MemStorage) {
 	isTopLevel := in.IsStart()
 	if in.IsNull() {
 		if isTopLevel {
@@ -45,9 +437,9 @@ func easyjson8bb13b26DecodeGithubComNikolayStrekalovVigilantOctoWaddleGitInterna
 				for !in.IsDelim('}') {
 					key := string(in.String())
 					in.WantColon()
-					var v1 float64
-					v1 = float64(in.Float64())
-					(out.Gauge)[key] = v1
+					var v11 float64
+					v11 = float64(in.Float64())
+					(out.Gauge)[key] = v11
 					in.WantComma()
 				}
 				in.Delim('}')
@@ -61,13 +453,281 @@ func easyjson8bb13b26DecodeGithubComNikolayStrekalovVigilantOctoWaddleGitInterna
 				for !in.IsDelim('}') {
 					key := string(in.String())
 					in.WantColon()
-					var v2 int64
-					v2 = int64(in.Int64())
-					(out.Counter)[key] = v2
+					var v12 int64
+					v12 = int64(in.Int64())
+					(out.Counter)[key] = v12
 					in.WantComma()
 				}
 				in.Delim('}')
 			}
+		case "CounterTotal":
+			if in.IsNull() {
+				in.Skip()
+			} else {
+				in.Delim('{')
+				if !in.IsDelim('}') {
+					out.CounterTotal = make(map[string]int64)
+				} else {
+					out.CounterTotal = nil
+				}
+				for !in.IsDelim('}') {
+					key := string(in.String())
+					in.WantColon()
+					var v13 int64
+					v13 = int64(in.Int64())
+					(out.CounterTotal)[key] = v13
+					in.WantComma()
+				}
+				in.Delim('}')
+			}
+		case "GaugeUpdated":
+			if in.IsNull() {
+				in.Skip()
+			} else {
+				in.Delim('{')
+				if !in.IsDelim('}') {
+					out.GaugeUpdated = make(map[string]time.Time)
+				} else {
+					out.GaugeUpdated = nil
+				}
+				for !in.IsDelim('}') {
+					key := string(in.String())
+					in.WantColon()
+					var v14 time.Time
+					if data := in.Raw(); in.Ok() {
+						in.AddError((v14).UnmarshalJSON(data))
+					}
+					(out.GaugeUpdated)[key] = v14
+					in.WantComma()
+				}
+				in.Delim('}')
+			}
+		case "CounterUpdated":
+			if in.IsNull() {
+				in.Skip()
+			} else {
+				in.Delim('{')
+				if !in.IsDelim('}') {
+					out.CounterUpdated = make(map[string]time.Time)
+				} else {
+					out.CounterUpdated = nil
+				}
+				for !in.IsDelim('}') {
+					key := string(in.String())
+					in.WantColon()
+					var v15 time.Time
+					if data := in.Raw(); in.Ok() {
+						in.AddError((v15).UnmarshalJSON(data))
+					}
+					(out.CounterUpdated)[key] = v15
+					in.WantComma()
+				}
+				in.Delim('}')
+			}
+		case "Histogram":
+			if in.IsNull() {
+				in.Skip()
+			} else {
+				in.Delim('{')
+				if !in.IsDelim('}') {
+					out.Histogram = make(map[string]*models.Histogram)
+				} else {
+					out.Histogram = nil
+				}
+				for !in.IsDelim('}') {
+					key := string(in.String())
+					in.WantColon()
+					var v16 *models.Histogram
+					if in.IsNull() {
+						in.Skip()
+						v16 = nil
+					} else {
+						if v16 == nil {
+							v16 = new(models.Histogram)
+						}
+						(*v16).UnmarshalEasyJSON(in)
+					}
+					(out.Histogram)[key] = v16
+					in.WantComma()
+				}
+				in.Delim('}')
+			}
+		case "Summary":
+			if in.IsNull() {
+				in.Skip()
+			} else {
+				in.Delim('{')
+				if !in.IsDelim('}') {
+					out.Summary = make(map[string]*models.Sketch)
+				} else {
+					out.Summary = nil
+				}
+				for !in.IsDelim('}') {
+					key := string(in.String())
+					in.WantColon()
+					var v17 *models.Sketch
+					if in.IsNull() {
+						in.Skip()
+						v17 = nil
+					} else {
+						if v17 == nil {
+							v17 = new(models.Sketch)
+						}
+						(*v17).UnmarshalEasyJSON(in)
+					}
+					(out.Summary)[key] = v17
+					in.WantComma()
+				}
+				in.Delim('}')
+			}
+		case "Set":
+			if in.IsNull() {
+				in.Skip()
+			} else {
+				in.Delim('{')
+				if !in.IsDelim('}') {
+					out.Set = make(map[string]*models.HLL)
+				} else {
+					out.Set = nil
+				}
+				for !in.IsDelim('}') {
+					key := string(in.String())
+					in.WantColon()
+					var v18 *models.HLL
+					if in.IsNull() {
+						in.Skip()
+						v18 = nil
+					} else {
+						if v18 == nil {
+							v18 = new(models.HLL)
+						}
+						(*v18).UnmarshalEasyJSON(in)
+					}
+					(out.Set)[key] = v18
+					in.WantComma()
+				}
+				in.Delim('}')
+			}
+		case "Batches":
+			if in.IsNull() {
+				in.Skip()
+			} else {
+				in.Delim('{')
+				if !in.IsDelim('}') {
+					out.Batches = make(map[string]time.Time)
+				} else {
+					out.Batches = nil
+				}
+				for !in.IsDelim('}') {
+					key := string(in.String())
+					in.WantColon()
+					var v19 time.Time
+					if data := in.Raw(); in.Ok() {
+						in.AddError((v19).UnmarshalJSON(data))
+					}
+					(out.Batches)[key] = v19
+					in.WantComma()
+				}
+				in.Delim('}')
+			}
+		case "Agents":
+			if in.IsNull() {
+				in.Skip()
+			} else {
+				in.Delim('{')
+				if !in.IsDelim('}') {
+					out.Agents = make(map[string]*models.Agent)
+				} else {
+					out.Agents = nil
+				}
+				for !in.IsDelim('}') {
+					key := string(in.String())
+					in.WantColon()
+					var v20 *models.Agent
+					if in.IsNull() {
+						in.Skip()
+						v20 = nil
+					} else {
+						if v20 == nil {
+							v20 = new(models.Agent)
+						}
+						(*v20).UnmarshalEasyJSON(in)
+					}
+					(out.Agents)[key] = v20
+					in.WantComma()
+				}
+				in.Delim('}')
+			}
+		case "AlertRules":
+			if in.IsNull() {
+				in.Skip()
+				out.AlertRules = nil
+			} else {
+				in.Delim('[')
+				if out.AlertRules == nil {
+					if !in.IsDelim(']') {
+						out.AlertRules = make([]models.AlertRule, 0, 0)
+					} else {
+						out.AlertRules = []models.AlertRule{}
+					}
+				} else {
+					out.AlertRules = (out.AlertRules)[:0]
+				}
+				for !in.IsDelim(']') {
+					var v21 models.AlertRule
+					(v21).UnmarshalEasyJSON(in)
+					out.AlertRules = append(out.AlertRules, v21)
+					in.WantComma()
+				}
+				in.Delim(']')
+			}
+		case "MaintenanceWindows":
+			if in.IsNull() {
+				in.Skip()
+				out.MaintenanceWindows = nil
+			} else {
+				in.Delim('[')
+				if out.MaintenanceWindows == nil {
+					if !in.IsDelim(']') {
+						out.MaintenanceWindows = make([]models.MaintenanceWindow, 0, 0)
+					} else {
+						out.MaintenanceWindows = []models.MaintenanceWindow{}
+					}
+				} else {
+					out.MaintenanceWindows = (out.MaintenanceWindows)[:0]
+				}
+				for !in.IsDelim(']') {
+					var v22 models.MaintenanceWindow
+					(v22).UnmarshalEasyJSON(in)
+					out.MaintenanceWindows = append(out.MaintenanceWindows, v22)
+					in.WantComma()
+				}
+				in.Delim(']')
+			}
+		case "MaintenanceFlags":
+			if in.IsNull() {
+				in.Skip()
+			} else {
+				in.Delim('{')
+				if !in.IsDelim('}') {
+					out.MaintenanceFlags = make(map[string]bool)
+				} else {
+					out.MaintenanceFlags = nil
+				}
+				for !in.IsDelim('}') {
+					key := string(in.String())
+					in.WantColon()
+					var v23 bool
+					v23 = bool(in.Bool())
+					(out.MaintenanceFlags)[key] = v23
+					in.WantComma()
+				}
+				in.Delim('}')
+			}
+		case "LastAlertRuleID":
+			out.LastAlertRuleID = int64(in.Int64())
+		case "LastMaintenanceID":
+			out.LastMaintenanceID = int64(in.Int64())
 		default:
 			in.SkipRecursive()
 		}
@@ -78,7 +738,7 @@ func easyjson8bb13b26DecodeGithubComNikolayStrekalovVigilantOctoWaddleGitInterna
 		in.Consumed()
 	}
 }
-func easyjson8bb13b26EncodeGithubComNikolayStrekalovVigilantOctoWaddleGitInternalMemstorage(out *jwriter.Writer, in MemStorage) {
+func easyjson8bb13b26EncodeGithubComNikolayStrekalovVigilantOctoWaddleGitInternalMemstorage1(out *jwriter.Writer, in MemStorage) {
 	out.RawByte('{')
 	first := true
 	_ = first
@@ -89,16 +749,16 @@ func easyjson8bb13b26EncodeGithubComNikolayStrekalovVigilantOctoWaddleGitInterna
 			out.RawString(`null`)
 		} else {
 			out.RawByte('{')
-			v3First := true
-			for v3Name, v3Value := range in.Gauge {
-				if v3First {
-					v3First = false
+			v24First := true
+			for v24Name, v24Value := range in.Gauge {
+				if v24First {
+					v24First = false
 				} else {
 					out.RawByte(',')
 				}
-				out.String(string(v3Name))
+				out.String(string(v24Name))
 				out.RawByte(':')
-				out.Float64(float64(v3Value))
+				out.Float64(float64(v24Value))
 			}
 			out.RawByte('}')
 		}
@@ -110,43 +770,268 @@ func easyjson8bb13b26EncodeGithubComNikolayStrekalovVigilantOctoWaddleGitInterna
 			out.RawString(`null`)
 		} else {
 			out.RawByte('{')
-			v4First := true
-			for v4Name, v4Value := range in.Counter {
-				if v4First {
-					v4First = false
+			v25First := true
+			for v25Name, v25Value := range in.Counter {
+				if v25First {
+					v25First = false
+				} else {
+					out.RawByte(',')
+				}
+				out.String(string(v25Name))
+				out.RawByte(':')
+				out.Int64(int64(v25Value))
+			}
+			out.RawByte('}')
+		}
+	}
+	if len(in.CounterTotal) != 0 {
+		const prefix string = ",\"CounterTotal\":"
+		out.RawString(prefix)
+		{
+			out.RawByte('{')
+			v26First := true
+			for v26Name, v26Value := range in.CounterTotal {
+				if v26First {
+					v26First = false
+				} else {
+					out.RawByte(',')
+				}
+				out.String(string(v26Name))
+				out.RawByte(':')
+				out.Int64(int64(v26Value))
+			}
+			out.RawByte('}')
+		}
+	}
+	if len(in.GaugeUpdated) != 0 {
+		const prefix string = ",\"GaugeUpdated\":"
+		out.RawString(prefix)
+		{
+			out.RawByte('{')
+			v27First := true
+			for v27Name, v27Value := range in.GaugeUpdated {
+				if v27First {
+					v27First = false
+				} else {
+					out.RawByte(',')
+				}
+				out.String(string(v27Name))
+				out.RawByte(':')
+				out.Raw((v27Value).MarshalJSON())
+			}
+			out.RawByte('}')
+		}
+	}
+	if len(in.CounterUpdated) != 0 {
+		const prefix string = ",\"CounterUpdated\":"
+		out.RawString(prefix)
+		{
+			out.RawByte('{')
+			v28First := true
+			for v28Name, v28Value := range in.CounterUpdated {
+				if v28First {
+					v28First = false
+				} else {
+					out.RawByte(',')
+				}
+				out.String(string(v28Name))
+				out.RawByte(':')
+				out.Raw((v28Value).MarshalJSON())
+			}
+			out.RawByte('}')
+		}
+	}
+	if len(in.Histogram) != 0 {
+		const prefix string = ",\"Histogram\":"
+		out.RawString(prefix)
+		{
+			out.RawByte('{')
+			v29First := true
+			for v29Name, v29Value := range in.Histogram {
+				if v29First {
+					v29First = false
+				} else {
+					out.RawByte(',')
+				}
+				out.String(string(v29Name))
+				out.RawByte(':')
+				if v29Value == nil {
+					out.RawString("null")
+				} else {
+					(*v29Value).MarshalEasyJSON(out)
+				}
+			}
+			out.RawByte('}')
+		}
+	}
+	if len(in.Summary) != 0 {
+		const prefix string = ",\"Summary\":"
+		out.RawString(prefix)
+		{
+			out.RawByte('{')
+			v30First := true
+			for v30Name, v30Value := range in.Summary {
+				if v30First {
+					v30First = false
+				} else {
+					out.RawByte(',')
+				}
+				out.String(string(v30Name))
+				out.RawByte(':')
+				if v30Value == nil {
+					out.RawString("null")
+				} else {
+					(*v30Value).MarshalEasyJSON(out)
+				}
+			}
+			out.RawByte('}')
+		}
+	}
+	if len(in.Set) != 0 {
+		const prefix string = ",\"Set\":"
+		out.RawString(prefix)
+		{
+			out.RawByte('{')
+			v31First := true
+			for v31Name, v31Value := range in.Set {
+				if v31First {
+					v31First = false
+				} else {
+					out.RawByte(',')
+				}
+				out.String(string(v31Name))
+				out.RawByte(':')
+				if v31Value == nil {
+					out.RawString("null")
+				} else {
+					(*v31Value).MarshalEasyJSON(out)
+				}
+			}
+			out.RawByte('}')
+		}
+	}
+	if len(in.Batches) != 0 {
+		const prefix string = ",\"Batches\":"
+		out.RawString(prefix)
+		{
+			out.RawByte('{')
+			v32First := true
+			for v32Name, v32Value := range in.Batches {
+				if v32First {
+					v32First = false
+				} else {
+					out.RawByte(',')
+				}
+				out.String(string(v32Name))
+				out.RawByte(':')
+				out.Raw((v32Value).MarshalJSON())
+			}
+			out.RawByte('}')
+		}
+	}
+	if len(in.Agents) != 0 {
+		const prefix string = ",\"Agents\":"
+		out.RawString(prefix)
+		{
+			out.RawByte('{')
+			v33First := true
+			for v33Name, v33Value := range in.Agents {
+				if v33First {
+					v33First = false
+				} else {
+					out.RawByte(',')
+				}
+				out.String(string(v33Name))
+				out.RawByte(':')
+				if v33Value == nil {
+					out.RawString("null")
+				} else {
+					(*v33Value).MarshalEasyJSON(out)
+				}
+			}
+			out.RawByte('}')
+		}
+	}
+	if len(in.AlertRules) != 0 {
+		const prefix string = ",\"AlertRules\":"
+		out.RawString(prefix)
+		{
+			out.RawByte('[')
+			for v34, v35 := range in.AlertRules {
+				if v34 > 0 {
+					out.RawByte(',')
+				}
+				(v35).MarshalEasyJSON(out)
+			}
+			out.RawByte(']')
+		}
+	}
+	if len(in.MaintenanceWindows) != 0 {
+		const prefix string = ",\"MaintenanceWindows\":"
+		out.RawString(prefix)
+		{
+			out.RawByte('[')
+			for v36, v37 := range in.MaintenanceWindows {
+				if v36 > 0 {
+					out.RawByte(',')
+				}
+				(v37).MarshalEasyJSON(out)
+			}
+			out.RawByte(']')
+		}
+	}
+	if len(in.MaintenanceFlags) != 0 {
+		const prefix string = ",\"MaintenanceFlags\":"
+		out.RawString(prefix)
+		{
+			out.RawByte('{')
+			v38First := true
+			for v38Name, v38Value := range in.MaintenanceFlags {
+				if v38First {
+					v38First = false
 				} else {
 					out.RawByte(',')
 				}
-				out.String(string(v4Name))
+				out.String(string(v38Name))
 				out.RawByte(':')
-				out.Int64(int64(v4Value))
+				out.Bool(bool(v38Value))
 			}
 			out.RawByte('}')
 		}
 	}
+	if in.LastAlertRuleID != 0 {
+		const prefix string = ",\"LastAlertRuleID\":"
+		out.RawString(prefix)
+		out.Int64(int64(in.LastAlertRuleID))
+	}
+	if in.LastMaintenanceID != 0 {
+		const prefix string = ",\"LastMaintenanceID\":"
+		out.RawString(prefix)
+		out.Int64(int64(in.LastMaintenanceID))
+	}
 	out.RawByte('}')
 }
 
 // MarshalJSON supports json.Marshaler interface
 func (v MemStorage) MarshalJSON() ([]byte, error) {
 	w := jwriter.Writer{}
-	easyjson8bb13b26EncodeGithubComNikolayStrekalovVigilantOctoWaddleGitInternalMemstorage(&w, v)
+	easyjson8bb13b26EncodeGithubComNikolayStrekalovVigilantOctoWaddleGitInternalMemstorage1(&w, v)
 	return w.Buffer.BuildBytes(), w.Error
 }
 
 // MarshalEasyJSON supports easyjson.Marshaler interface
 func (v MemStorage) MarshalEasyJSON(w *jwriter.Writer) {
-	easyjson8bb13b26EncodeGithubComNikolayStrekalovVigilantOctoWaddleGitInternalMemstorage(w, v)
+	easyjson8bb13b26EncodeGithubComNikolayStrekalovVigilantOctoWaddleGitInternalMemstorage1(w, v)
 }
 
 // UnmarshalJSON supports json.Unmarshaler interface
 func (v *MemStorage) UnmarshalJSON(data []byte) error {
 	r := jlexer.Lexer{Data: data}
-	easyjson8bb13b26DecodeGithubComNikolayStrekalovVigilantOctoWaddleGitInternalMemstorage(&r, v)
+	easyjson8bb13b26DecodeGithubComNikolayStrekalovVigilantOctoWaddleGitInternalMemstorage1(&r, v)
 	return r.Error()
 }
 
 // UnmarshalEasyJSON supports easyjson.Unmarshaler interface
 func (v *MemStorage) UnmarshalEasyJSON(l *jlexer.Lexer) {
-	easyjson8bb13b26DecodeGithubComNikolayStrekalovVigilantOctoWaddleGitInternalMemstorage(l, v)
+	easyjson8bb13b26DecodeGithubComNikolayStrekalovVigilantOctoWaddleGitInternalMemstorage1(l, v)
 }
@@ -2,10 +2,17 @@ package memstorage
 
 import (
 	"os"
+	"path/filepath"
 	"reflect"
 	"sync"
 	"testing"
+	"time"
 
+	"github.com/NikolayStrekalov/vigilant-octo-waddle.git/internal/histogram"
+	"github.com/NikolayStrekalov/vigilant-octo-waddle.git/internal/history"
+	"github.com/NikolayStrekalov/vigilant-octo-waddle.git/internal/logger"
+	"github.com/NikolayStrekalov/vigilant-octo-waddle.git/internal/models"
+	"github.com/NikolayStrekalov/vigilant-octo-waddle.git/internal/sketch"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -58,11 +65,14 @@ func TestMemStorage_UpdateGauge(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			m := MemStorage{
-				Gauge:      tt.fields.gauge,
-				Counter:    tt.fields.counter,
-				muxGauge:   &sync.RWMutex{},
-				muxCounter: &sync.RWMutex{},
-				sync:       false,
+				Gauge:          tt.fields.gauge,
+				Counter:        tt.fields.counter,
+				GaugeUpdated:   map[string]time.Time{},
+				CounterUpdated: map[string]time.Time{},
+				muxGauge:       &sync.RWMutex{},
+				muxCounter:     &sync.RWMutex{},
+				muxHistory:     &sync.RWMutex{},
+				sync:           false,
 			}
 			m.UpdateGauge(tt.args.name, tt.args.value)
 			assert.True(t, reflect.DeepEqual(m.Gauge, tt.wantFields.gauge))
@@ -120,10 +130,13 @@ func TestMemStorage_IncrementCounter(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			m := MemStorage{
-				Gauge:      tt.fields.gauge,
-				Counter:    tt.fields.counter,
-				muxGauge:   &sync.RWMutex{},
-				muxCounter: &sync.RWMutex{},
+				Gauge:          tt.fields.gauge,
+				Counter:        tt.fields.counter,
+				GaugeUpdated:   map[string]time.Time{},
+				CounterUpdated: map[string]time.Time{},
+				muxGauge:       &sync.RWMutex{},
+				muxCounter:     &sync.RWMutex{},
+				muxHistory:     &sync.RWMutex{},
 			}
 			m.IncrementCounter(tt.args.name, tt.args.value)
 			assert.True(t, reflect.DeepEqual(m.Gauge, tt.wantFields.gauge))
@@ -370,7 +383,8 @@ func TestMemStorageSync(t *testing.T) {
 		t.Errorf("reading temp file error: %v", err)
 		return
 	}
-	assert.Equal(t, `{"Gauge":{"any":3.1415},"Counter":{"some":10}}`, string(data))
+	assert.Regexp(t, `^\{"Gauge":\{"any":3.1415\},"Counter":\{"some":10\},`+
+		`"GaugeUpdated":\{"any":"[^"]+"\},"CounterUpdated":\{"some":"[^"]+"\}\}$`, string(data))
 }
 
 func TestMemStorageRestore(t *testing.T) {
@@ -394,4 +408,330 @@ func TestMemStorageRestore(t *testing.T) {
 	storage.restore()
 	assert.Equal(t, 3.1415, storage.Gauge["any"])
 	assert.Equal(t, int64(10), storage.Counter["some"])
+	assert.False(t, storage.GaugeUpdated["any"].IsZero(), "series from an old dump are updated on restore")
+	assert.False(t, storage.CounterUpdated["some"].IsZero())
+}
+
+func TestMemStorageHistory(t *testing.T) {
+	storage, _, _ := NewMemStorage("", false, 300)
+	from := time.Now().Add(-time.Minute)
+	_, err := storage.GetHistory(gaugeKind, "any", from, time.Now(), 0)
+	assert.ErrorIs(t, err, ErrNotSupported)
+
+	storage.SetHistoryPolicy(history.Policy{
+		Raw:     time.Hour,
+		Rollups: []history.Level{{Resolution: time.Minute, Retention: 24 * time.Hour}},
+	})
+	storage.UpdateGauge("any", 3.1415)
+	storage.IncrementCounter("some", 10)
+	storage.IncrementCounter("some", 5)
+	points, err := storage.GetHistory(counterKind, "some", from, time.Now(), 0)
+	assert.Nil(t, err)
+	if assert.Len(t, points, 2) {
+		assert.Equal(t, 10.0, points[0].Value)
+		assert.Equal(t, 15.0, points[1].Value)
+	}
+	samples, err := storage.GetCounterSamples("some", time.Now(), time.Now())
+	assert.Nil(t, err)
+	assert.Equal(t, points[1:], samples, "only the last sample before the window")
+	points, err = storage.GetHistory(gaugeKind, "none", from, time.Now(), 0)
+	assert.Nil(t, err)
+	assert.Empty(t, points)
+
+	assert.Nil(t, storage.CompactHistory(from, time.Now().Add(48*time.Hour)))
+	assert.Empty(t, storage.History)
+}
+
+func TestMemStorageHistoryDump(t *testing.T) {
+	dump := filepath.Join(t.TempDir(), "metrics.json")
+	policy := history.Policy{Raw: time.Hour}
+	storage, closeStorage, _ := NewMemStorage(dump, false, 0)
+	storage.SetHistoryPolicy(policy)
+	storage.UpdateGauge("any", 3.1415)
+	data, err := os.ReadFile(dump)
+	assert.Nil(t, err)
+	assert.NotContains(t, string(data), "History", "sync dump does not rewrite history")
+	_, err = os.Stat(dump + historyFileSuffix)
+	assert.ErrorIs(t, err, os.ErrNotExist)
+
+	assert.Nil(t, storage.CompactHistory(time.Now().Add(-time.Hour), time.Now()))
+	_, err = os.Stat(dump + historyFileSuffix)
+	assert.Nil(t, err, "history is written on compaction in sync mode")
+	storage.UpdateGauge("any", 2.71)
+	assert.Nil(t, closeStorage())
+
+	restored, _, _ := NewMemStorage(dump, true, 0)
+	restored.SetHistoryPolicy(policy)
+	points, err := restored.GetHistory(gaugeKind, "any", time.Now().Add(-time.Minute), time.Now(), 0)
+	assert.Nil(t, err)
+	if assert.Len(t, points, 2) {
+		assert.Equal(t, 3.1415, points[0].Value)
+		assert.Equal(t, 2.71, points[1].Value)
+	}
+}
+
+func TestMemStorageAlertRules(t *testing.T) {
+	f, err := os.CreateTemp("", "tmpfile-")
+	if err != nil {
+		t.Errorf("create temp file error: %v", err)
+		return
+	}
+	defer func() {
+		_ = f.Close()
+	}()
+	defer func() {
+		_ = os.Remove(f.Name())
+	}()
+	storage, _, _ := NewMemStorage(f.Name(), false, 0)
+	added, err := storage.AddAlertRule(&models.AlertRule{Kind: gaugeKind, Name: "any", Op: ">", Threshold: 1})
+	assert.Nil(t, err)
+	assert.Equal(t, int64(1), added.ID)
+	added.Threshold = 2
+	assert.Nil(t, storage.UpdateAlertRule(&added))
+	assert.Error(t, storage.UpdateAlertRule(&models.AlertRule{ID: 5}))
+
+	restored, _, _ := NewMemStorage(f.Name(), true, 300)
+	rule, err := restored.GetAlertRule(1)
+	assert.Nil(t, err)
+	assert.Equal(t, added, rule)
+	next, _ := restored.AddAlertRule(&models.AlertRule{Kind: counterKind, Name: "some", Op: "==", Rate: true})
+	assert.Equal(t, int64(2), next.ID)
+
+	assert.Nil(t, storage.DeleteAlertRule(1))
+	assert.Error(t, storage.DeleteAlertRule(1))
+	rules, err := storage.GetAlertRules()
+	assert.Nil(t, err)
+	assert.Empty(t, rules)
+}
+
+func TestMemStorageMaintenanceWindows(t *testing.T) {
+	f, err := os.CreateTemp("", "tmpfile-")
+	if err != nil {
+		t.Errorf("create temp file error: %v", err)
+		return
+	}
+	defer func() {
+		_ = f.Close()
+	}()
+	defer func() {
+		_ = os.Remove(f.Name())
+	}()
+	storage, _, _ := NewMemStorage(f.Name(), false, 0)
+	start := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+	added, err := storage.AddMaintenanceWindow(&models.MaintenanceWindow{
+		Pattern: "CPUutilization*", Mode: "flag", Start: start, End: start.Add(time.Hour),
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, int64(1), added.ID)
+	assert.Nil(t, storage.SetMaintenanceFlag("gauge/CPUutilization1", true))
+	assert.Nil(t, storage.SetMaintenanceFlag("gauge/CPUutilization2", true))
+	assert.Nil(t, storage.SetMaintenanceFlag("gauge/CPUutilization2", false))
+
+	restored, _, _ := NewMemStorage(f.Name(), true, 300)
+	windows, err := restored.GetMaintenanceWindows()
+	assert.Nil(t, err)
+	assert.Equal(t, []models.MaintenanceWindow{added}, windows)
+	flags, err := restored.GetMaintenanceFlags()
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"gauge/CPUutilization1"}, flags)
+
+	assert.Nil(t, storage.DeleteMaintenanceWindow(1))
+	assert.Error(t, storage.DeleteMaintenanceWindow(1))
+	windows, err = storage.GetMaintenanceWindows()
+	assert.Nil(t, err)
+	assert.Empty(t, windows)
+}
+
+func TestMemStorageHistogram(t *testing.T) {
+	f, err := os.CreateTemp("", "tmpfile-")
+	if err != nil {
+		t.Errorf("create temp file error: %v", err)
+		return
+	}
+	defer func() {
+		_ = f.Close()
+	}()
+	defer func() {
+		_ = os.Remove(f.Name())
+	}()
+	storage, _, _ := NewMemStorage(f.Name(), false, 0)
+	_, err = storage.GetHistogram("latency")
+	assert.Error(t, err)
+
+	assert.Nil(t, storage.UpdateHistogram("latency",
+		&models.Histogram{Bounds: []float64{1}, Counts: []uint64{1, 0}, Sum: 0.5, Count: 1}))
+	storage.BulkUpdate(models.MetricsSlice{{
+		ID:        "latency",
+		MType:     histogramKind,
+		Histogram: &models.Histogram{Bounds: []float64{1}, Counts: []uint64{0, 2}, Sum: 5, Count: 2},
+	}})
+	assert.ErrorIs(t, storage.UpdateHistogram("latency",
+		&models.Histogram{Bounds: []float64{2}, Counts: []uint64{1, 0}, Sum: 1, Count: 1}), histogram.ErrBoundsMismatch)
+	want := models.Histogram{Bounds: []float64{1}, Counts: []uint64{1, 2}, Sum: 5.5, Count: 3}
+	got, err := storage.GetHistogram("latency")
+	assert.Nil(t, err)
+	assert.Equal(t, want, got)
+	got.Counts[0] = 100
+	assert.Equal(t, []HistogramListItem{{Name: "latency", Value: want}}, storage.GetHistogramList())
+
+	restored, _, _ := NewMemStorage(f.Name(), true, 300)
+	got, err = restored.GetHistogram("latency")
+	assert.Nil(t, err)
+	assert.Equal(t, want, got)
+}
+
+func TestMemStorageSummary(t *testing.T) {
+	_ = logger.InitLog()
+	storage, _, _ := NewMemStorage("", false, 300)
+	_, err := storage.GetSummary("latency")
+	assert.Error(t, err)
+
+	a, b := models.Sketch{Alpha: 0.01}, models.Sketch{Alpha: 0.01}
+	a.Zero, a.Count = 1, 1
+	b.Positive = models.SketchBins{Offset: 10, Counts: []uint64{2}}
+	b.Count, b.Sum, b.Min, b.Max = 2, 2.2, 1.1, 1.1
+	assert.Nil(t, storage.UpdateSummary("latency", &a))
+	storage.BulkUpdate(models.MetricsSlice{{ID: "latency", MType: summaryKind, Sketch: &b}})
+	other := models.Sketch{Alpha: 0.05, Zero: 1, Count: 1}
+	assert.ErrorIs(t, storage.UpdateSummary("latency", &other), sketch.ErrAlphaMismatch)
+	storage.BulkUpdate(models.MetricsSlice{{ID: "latency", MType: summaryKind, Sketch: &other}})
+	got, err := storage.GetSummary("latency")
+	assert.Nil(t, err)
+	assert.Equal(t, models.Sketch{
+		Alpha:    0.01,
+		Positive: models.SketchBins{Offset: 10, Counts: []uint64{2}},
+		Zero:     1,
+		Count:    3,
+		Sum:      2.2,
+		Min:      0,
+		Max:      1.1,
+	}, got)
+	assert.Len(t, storage.GetSummaryList(), 1)
+}
+
+func TestMemStorage_AddCounterTotal(t *testing.T) {
+	storage, _, _ := NewMemStorage("", false, 300)
+	storage.IncrementCounter("requests", 3)
+	for _, total := range []int64{10, 12, 12, 5, 7} {
+		storage.AddCounterTotal("requests", total)
+	}
+	got, err := storage.GetCounter("requests")
+	assert.Nil(t, err)
+	assert.Equal(t, int64(3+12+7), got)
+
+	storage.BulkUpdate(models.MetricsSlice{{ID: "requests", MType: counterKind, Total: new(int64)}})
+	got, _ = storage.GetCounter("requests")
+	assert.Equal(t, int64(22), got, "reset to zero adds nothing")
+}
+
+func TestMemStorage_BulkUpdateOnce(t *testing.T) {
+	storage, _, _ := NewMemStorage("", false, 300)
+	delta := int64(2)
+	batch := models.MetricsSlice{{ID: "PollCount", MType: counterKind, Delta: &delta}}
+	for _, want := range []bool{true, false, false} {
+		applied, err := storage.BulkUpdateOnce("agent", 1, batch)
+		assert.NoError(t, err)
+		assert.Equal(t, want, applied)
+	}
+	got, _ := storage.GetCounter("PollCount")
+	assert.Equal(t, int64(2), got)
+
+	assert.NoError(t, storage.ForgetBatches(time.Now().Add(-time.Hour)))
+	applied, _ := storage.BulkUpdateOnce("agent", 1, batch)
+	assert.False(t, applied, "recent batches are kept")
+	assert.NoError(t, storage.ForgetBatches(time.Now().Add(time.Second)))
+	applied, _ = storage.BulkUpdateOnce("agent", 1, batch)
+	assert.True(t, applied)
+}
+
+func TestMemStorageAgents(t *testing.T) {
+	storage, _, _ := NewMemStorage("", false, 300)
+	first := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	assert.NoError(t, storage.SeenAgent(&models.Agent{ID: "b", FirstSeen: first, LastSeen: first}))
+	assert.NoError(t, storage.SeenAgent(&models.Agent{ID: "a", FirstSeen: first, LastSeen: first, Version: "1"}))
+	later := first.Add(time.Minute)
+	assert.NoError(t, storage.SeenAgent(&models.Agent{ID: "a", FirstSeen: later, LastSeen: later, Version: "2"}))
+	agents, err := storage.GetAgents()
+	assert.NoError(t, err)
+	assert.Equal(t, []models.Agent{
+		{ID: "a", FirstSeen: first, LastSeen: later, Version: "2"},
+		{ID: "b", FirstSeen: first, LastSeen: first},
+	}, agents)
+}
+
+func TestMemStorageSet(t *testing.T) {
+	storage, _, _ := NewMemStorage("", false, 300)
+	_, err := storage.GetSet("users")
+	assert.Error(t, err)
+
+	a, b := models.HLL{Precision: 4, Registers: make([]byte, 16)}, models.HLL{Precision: 4, Registers: make([]byte, 16)}
+	a.Registers[0], a.Registers[1] = 3, 1
+	b.Registers[1], b.Registers[2] = 2, 5
+	storage.UpdateSet("users", &a)
+	storage.BulkUpdate(models.MetricsSlice{{ID: "users", MType: setKind, HLL: &b}})
+	got, err := storage.GetSet("users")
+	assert.Nil(t, err)
+	assert.Equal(t, []byte{3, 2, 5, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0}, got.Registers)
+	got.Registers[0] = 0
+	again, _ := storage.GetSet("users")
+	assert.Equal(t, byte(3), again.Registers[0], "storage must return a copy")
+	assert.Len(t, storage.GetSetList(), 1)
+}
+
+func TestMemStorageDelete(t *testing.T) {
+	storage, _, _ := NewMemStorage("", false, 300)
+	storage.SetHistoryPolicy(history.Policy{Raw: time.Hour})
+	storage.UpdateGauge(`Alloc{host="old"}`, 1)
+	storage.UpdateGauge(`Alloc{host="new"}`, 2)
+	storage.IncrementCounter(`PollCount{host="old"}`, 5)
+	storage.AddCounterTotal("requests", 10)
+
+	assert.ErrorIs(t, storage.DeleteMetric(counterKind, `Alloc{host="old"}`), errNotFound)
+	assert.NoError(t, storage.DeleteMetric(gaugeKind, `Alloc{host="old"}`))
+	assert.ErrorIs(t, storage.DeleteMetric(gaugeKind, `Alloc{host="old"}`), errNotFound)
+	assert.NotContains(t, storage.History, seriesKey(gaugeKind, `Alloc{host="old"}`))
+
+	_, err := storage.DeleteMetrics("[")
+	assert.Error(t, err)
+	deleted, err := storage.DeleteMetrics(`*{host="old"}`)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), deleted)
+	assert.Len(t, storage.GetGaugeList(), 1)
+	assert.Len(t, storage.GetCounterList(), 1)
+
+	assert.ErrorIs(t, storage.ResetCounter("unknown"), errNotFound)
+	assert.NoError(t, storage.ResetCounter("requests"))
+	got, _ := storage.GetCounter("requests")
+	assert.Equal(t, int64(0), got)
+	storage.AddCounterTotal("requests", 12)
+	got, _ = storage.GetCounter("requests")
+	assert.Equal(t, int64(2), got, "reset keeps the last total")
+}
+
+func TestMemStorageExpiry(t *testing.T) {
+	storage, _, _ := NewMemStorage("", false, 300)
+	storage.SetHistoryPolicy(history.Policy{Raw: time.Hour})
+	storage.UpdateGauge("old", 1)
+	storage.IncrementCounter("old", 1)
+	storage.GaugeUpdated["old"] = time.Now().Add(-time.Hour)
+	storage.CounterUpdated["old"] = time.Now().Add(-time.Hour)
+	storage.UpdateGauge("new", 2)
+	storage.BulkUpdate(models.MetricsSlice{{ID: "new", MType: counterKind, Delta: new(int64)}})
+
+	assert.Len(t, storage.GetGaugeList(), 2)
+	storage.SetStaleTTL(time.Minute)
+	assert.Equal(t, []GaugeListItem{{Name: "new", Value: 2}}, storage.GetGaugeList())
+	assert.Equal(t, []CounterListItem{{Name: "new", Value: 0}}, storage.GetCounterList())
+	got, err := storage.GetGauge("old")
+	assert.NoError(t, err, "stale series are only hidden from listings")
+	assert.Equal(t, 1.0, got)
+
+	deleted, err := storage.ExpireMetrics(time.Now().Add(-30 * time.Minute))
+	assert.NoError(t, err)
+	assert.Equal(t, int64(2), deleted)
+	_, err = storage.GetCounter("old")
+	assert.ErrorIs(t, err, errNotFound)
+	assert.NotContains(t, storage.GaugeUpdated, "old")
+	assert.NotContains(t, storage.History, seriesKey(gaugeKind, "old"))
+	assert.Len(t, storage.GetGaugeList(), 1)
 }
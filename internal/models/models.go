@@ -1,12 +1,185 @@
 package models
 
+import (
+	"time"
+
+	"github.com/NikolayStrekalov/vigilant-octo-waddle.git/internal/series"
+)
+
 //easyjson:json
 type Metrics struct {
-	Delta *int64   `json:"delta,omitempty"` // значение метрики в случае передачи counter
-	Value *float64 `json:"value,omitempty"` // значение метрики в случае передачи gauge
-	ID    string   `json:"id"`              // имя метрики
-	MType string   `json:"type"`            // параметр, принимающий значение gauge или counter
+	Delta       *int64            `json:"delta,omitempty"`       // значение метрики в случае передачи counter
+	Total       *int64            `json:"total,omitempty"`       // накопительное значение counter вместо приращения
+	Value       *float64          `json:"value,omitempty"`       // значение метрики в случае передачи gauge
+	Histogram   *Histogram        `json:"histogram,omitempty"`   // наблюдения в случае передачи histogram
+	Sketch      *Sketch           `json:"sketch,omitempty"`      // набросок распределения в случае передачи summary
+	HLL         *HLL              `json:"hll,omitempty"`         // набросок HyperLogLog в случае передачи set
+	Cardinality *uint64           `json:"cardinality,omitempty"` // оценка числа различных значений set в ответах
+	Members     []string          `json:"members,omitempty"`     // значения, добавляемые в set
+	Labels      map[string]string `json:"labels,omitempty"`      // метки, вместе с именем задают серию
+	ID          string            `json:"id"`                    // имя метрики
+	MType       string            `json:"type"`                  // gauge, counter, histogram, summary или set
+	Maintenance bool              `json:"maintenance,omitempty"` // значение получено во время технических работ
+}
+
+//easyjson:json
+type Histogram struct {
+	Bounds []float64 `json:"bounds"` // верхние границы корзин по возрастанию, корзина +Inf не указывается
+	Counts []uint64  `json:"counts"` // число наблюдений в каждой корзине, последняя — выше всех границ
+	Sum    float64   `json:"sum"`    // сумма наблюдений
+	Count  uint64    `json:"count"`  // число наблюдений
+}
+
+// Key возвращает ключ серии, под которым метрика хранится.
+func (m *Metrics) Key() string {
+	return series.Key(m.ID, m.Labels)
+}
+
+//easyjson:json
+type Sketch struct {
+	Positive SketchBins `json:"positive"` // корзины положительных значений
+	Negative SketchBins `json:"negative"` // корзины модулей отрицательных значений
+	Alpha    float64    `json:"alpha"`    // относительная точность квантилей
+	Sum      float64    `json:"sum"`      // сумма значений
+	Min      float64    `json:"min"`      // наименьшее значение
+	Max      float64    `json:"max"`      // наибольшее значение
+	Zero     uint64     `json:"zero"`     // число значений около нуля
+	Count    uint64     `json:"count"`    // число значений
+}
+
+type SketchBins struct {
+	Counts []uint64 `json:"counts,omitempty"` // число значений в корзинах подряд, начиная с Offset
+	Offset int      `json:"offset"`           // индекс первой корзины
+}
+
+//easyjson:json
+type HLL struct {
+	Registers []byte `json:"registers"` // 2^precision регистров, в JSON — base64
+	Precision uint8  `json:"precision"` // число бит хеша, задающих номер регистра
 }
 
 //easyjson:json
 type MetricsSlice []Metrics
+
+//easyjson:json
+type HistoryPoint struct {
+	Time  time.Time `json:"ts"`            // время записи значения или начало интервала агрегации
+	Min   *float64  `json:"min,omitempty"` // минимум за интервал агрегации
+	Max   *float64  `json:"max,omitempty"` // максимум за интервал агрегации
+	Avg   *float64  `json:"avg,omitempty"` // среднее за интервал агрегации
+	Value float64   `json:"value"`         // значение метрики (последнее за интервал), для counter — накопленная сумма
+}
+
+//easyjson:json
+type MetricHistory struct {
+	Labels map[string]string `json:"labels,omitempty"` // метки серии
+	ID     string            `json:"id"`               // имя метрики
+	MType  string            `json:"type"`             // gauge или counter
+	Points []HistoryPoint    `json:"points"`           // значения в порядке возрастания времени
+}
+
+//easyjson:json
+type AlertStatus struct {
+	ActiveSince *time.Time `json:"activeSince,omitempty"` // с какого момента выполняется условие
+	Value       *float64   `json:"value,omitempty"`       // последнее вычисленное значение
+	Rule        string     `json:"rule"`                  // правило
+	State       string     `json:"state"`                 // inactive, pending, firing или resolved
+	ChangedAt   time.Time  `json:"changedAt"`             // время последней смены состояния
+	RuleID      int64      `json:"ruleId,omitempty"`      // идентификатор правила, заданного через API
+}
+
+//easyjson:json
+type AlertStatuses []AlertStatus
+
+//easyjson:json
+type AlertRule struct {
+	Kind      string  `json:"type"`          // gauge или counter
+	Name      string  `json:"name"`          // имя метрики
+	Op        string  `json:"op"`            // оператор сравнения: < <= > >= == !=
+	For       string  `json:"for,omitempty"` // сколько условие должно выполняться до срабатывания, например 2m
+	Threshold float64 `json:"threshold"`     // порог
+	ID        int64   `json:"id"`            // идентификатор, назначается сервером
+	Rate      bool    `json:"rate"`          // сравнивать скорость роста счётчика в секунду
+}
+
+//easyjson:json
+type AlertRules []AlertRule
+
+//easyjson:json
+type AlertEvent struct {
+	Value     *float64  `json:"value,omitempty"`  // значение, на котором сменилось состояние
+	Rule      string    `json:"rule"`             // правило
+	Kind      string    `json:"type"`             // тип метрики
+	Name      string    `json:"name"`             // имя метрики
+	State     string    `json:"state"`            // firing или resolved
+	Threshold float64   `json:"threshold"`        // порог
+	ChangedAt time.Time `json:"changedAt"`        // время смены состояния
+	RuleID    int64     `json:"ruleId,omitempty"` // идентификатор правила, заданного через API
+}
+
+//easyjson:json
+type MaintenanceWindow struct {
+	Start   time.Time `json:"start"`             // начало окна
+	End     time.Time `json:"end"`               // конец окна
+	Pattern string    `json:"pattern"`           // шаблон имени метрики, например CPUutilization*
+	Mode    string    `json:"mode,omitempty"`    // flag — помечать обновления, reject — отклонять их
+	Comment string    `json:"comment,omitempty"` // описание работ
+	ID      int64     `json:"id"`                // идентификатор, назначается сервером
+}
+
+//easyjson:json
+type MaintenanceWindows []MaintenanceWindow
+
+//easyjson:json
+type Anomaly struct {
+	DetectedAt time.Time `json:"detectedAt"` // время получения аномального значения
+	Name       string    `json:"name"`       // имя gauge
+	Value      float64   `json:"value"`      // аномальное значение
+	Mean       float64   `json:"mean"`       // ожидаемое значение по скользящему среднему
+	StdDev     float64   `json:"stddev"`     // скользящее стандартное отклонение
+	Score      float64   `json:"score"`      // отклонение от среднего в сигмах
+	Sigma      float64   `json:"sigma"`      // порог чувствительности в сигмах
+}
+
+//easyjson:json
+type Anomalies []Anomaly
+
+//easyjson:json
+type Absence struct {
+	LastSeen  time.Time `json:"lastSeen"`  // время последнего обновления
+	ChangedAt time.Time `json:"changedAt"` // когда метрика или агент пропали или вернулись
+	Kind      string    `json:"type"`      // gauge, counter или agent
+	Name      string    `json:"name"`      // имя метрики или адрес агента
+	State     string    `json:"state"`     // absent или present
+}
+
+//easyjson:json
+type Absences []Absence
+
+//easyjson:json
+type Agent struct {
+	FirstSeen time.Time `json:"firstSeen"` // время первой пачки от агента
+	LastSeen  time.Time `json:"lastSeen"`  // время последней пачки
+	ID        string    `json:"id"`        // идентификатор, постоянный между запусками агента
+	Hostname  string    `json:"hostname"`  // имя хоста агента
+	Platform  string    `json:"platform"`  // ОС и архитектура, например linux/amd64
+	Version   string    `json:"version"`   // версия агента
+	Address   string    `json:"address"`   // адрес, с которого пришла последняя пачка
+	Metrics   int       `json:"metrics"`   // число метрик в последней пачке
+}
+
+//easyjson:json
+type Agents []Agent
+
+//easyjson:json
+type Deleted struct {
+	Deleted int64 `json:"deleted"` // число удалённых серий
+}
+
+//easyjson:json
+type CounterRate struct {
+	Labels map[string]string `json:"labels,omitempty"` // метки серии
+	ID     string            `json:"id"`               // имя counter
+	Window float64           `json:"window"`           // окно в секундах
+	Value  float64           `json:"value"`            // прирост за окно или средний прирост в секунду
+}
@@ -7,6 +7,7 @@ import (
 	easyjson "github.com/mailru/easyjson"
 	jlexer "github.com/mailru/easyjson/jlexer"
 	jwriter "github.com/mailru/easyjson/jwriter"
+	time "time"
 )
 
 // suppress unused package warning
@@ -17,7 +18,207 @@ var (
 	_ easyjson.Marshaler
 )
 
-func easyjsonD2b7633eDecodeGithubComNikolayStrekalovVigilantOctoWaddleGitInternalModels(in *jlexer.Lexer, out *MetricsSlice) {
+func easyjsonD2b7633eDecodeGithubComNikolayStrekalovVigilantOctoWaddleGitInternalModels(in *jlexer.Lexer, out *Sketch) {
+	isTopLevel := in.IsStart()
+	if in.IsNull() {
+		if isTopLevel {
+			in.Consumed()
+		}
+		in.Skip()
+		return
+	}
+	in.Delim('{')
+	for !in.IsDelim('}') {
+		key := in.UnsafeFieldName(false)
+		in.WantColon()
+		if in.IsNull() {
+			in.Skip()
+			in.WantComma()
+			continue
+		}
+		switch key {
+		case "positive":
+			easyjsonD2b7633eDecodeGithubComNikolayStrekalovVigilantOctoWaddleGitInternalModels1(in, &out.Positive)
+		case "negative":
+			easyjsonD2b7633eDecodeGithubComNikolayStrekalovVigilantOctoWaddleGitInternalModels1(in, &out.Negative)
+		case "alpha":
+			out.Alpha = float64(in.Float64())
+		case "sum":
+			out.Sum = float64(in.Float64())
+		case "min":
+			out.Min = float64(in.Float64())
+		case "max":
+			out.Max = float64(in.Float64())
+		case "zero":
+			out.Zero = uint64(in.Uint64())
+		case "count":
+			out.Count = uint64(in.Uint64())
+		default:
+			in.SkipRecursive()
+		}
+		in.WantComma()
+	}
+	in.Delim('}')
+	if isTopLevel {
+		in.Consumed()
+	}
+}
+func easyjsonD2b7633eEncodeGithubComNikolayStrekalovVigilantOctoWaddleGitInternalModels(out *jwriter.Writer, in Sketch) {
+	out.RawByte('{')
+	first := true
+	_ = first
+	{
+		const prefix string = ",\"positive\":"
+		out.RawString(prefix[1:])
+		easyjsonD2b7633eEncodeGithubComNikolayStrekalovVigilantOctoWaddleGitInternalModels1(out, in.Positive)
+	}
+	{
+		const prefix string = ",\"negative\":"
+		out.RawString(prefix)
+		easyjsonD2b7633eEncodeGithubComNikolayStrekalovVigilantOctoWaddleGitInternalModels1(out, in.Negative)
+	}
+	{
+		const prefix string = ",\"alpha\":"
+		out.RawString(prefix)
+		out.Float64(float64(in.Alpha))
+	}
+	{
+		const prefix string = ",\"sum\":"
+		out.RawString(prefix)
+		out.Float64(float64(in.Sum))
+	}
+	{
+		const prefix string = ",\"min\":"
+		out.RawString(prefix)
+		out.Float64(float64(in.Min))
+	}
+	{
+		const prefix string = ",\"max\":"
+		out.RawString(prefix)
+		out.Float64(float64(in.Max))
+	}
+	{
+		const prefix string = ",\"zero\":"
+		out.RawString(prefix)
+		out.Uint64(uint64(in.Zero))
+	}
+	{
+		const prefix string = ",\"count\":"
+		out.RawString(prefix)
+		out.Uint64(uint64(in.Count))
+	}
+	out.RawByte('}')
+}
+
+// MarshalJSON supports json.Marshaler interface
+func (v Sketch) MarshalJSON() ([]byte, error) {
+	w := jwriter.Writer{}
+	easyjsonD2b7633eEncodeGithubComNikolayStrekalovVigilantOctoWaddleGitInternalModels(&w, v)
+	return w.Buffer.BuildBytes(), w.Error
+}
+
+// MarshalEasyJSON supports easyjson.Marshaler interface
+func (v Sketch) MarshalEasyJSON(w *jwriter.Writer) {
+	easyjsonD2b7633eEncodeGithubComNikolayStrekalovVigilantOctoWaddleGitInternalModels(w, v)
+}
+
+// UnmarshalJSON supports json.Unmarshaler interface
+func (v *Sketch) UnmarshalJSON(data []byte) error {
+	r := jlexer.Lexer{Data: data}
+	easyjsonD2b7633eDecodeGithubComNikolayStrekalovVigilantOctoWaddleGitInternalModels(&r, v)
+	return r.Error()
+}
+
+// UnmarshalEasyJSON supports easyjson.Unmarshaler interface
+func (v *Sketch) UnmarshalEasyJSON(l *jlexer.Lexer) {
+	easyjsonD2b7633eDecodeGithubComNikolayStrekalovVigilantOctoWaddleGitInternalModels(l, v)
+}
+func easyjsonD2b7633eDecodeGithubComNikolayStrekalovVigilantOctoWaddleGitInternalModels1(in *jlexer.Lexer, out *SketchBins) {
+	isTopLevel := in.IsStart()
+	if in.IsNull() {
+		if isTopLevel {
+			in.Consumed()
+		}
+		in.Skip()
+		return
+	}
+	in.Delim('{')
+	for !in.IsDelim('}') {
+		key := in.UnsafeFieldName(false)
+		in.WantColon()
+		if in.IsNull() {
+			in.Skip()
+			in.WantComma()
+			continue
+		}
+		switch key {
+		case "counts":
+			if in.IsNull() {
+				in.Skip()
+				out.Counts = nil
+			} else {
+				in.Delim('[')
+				if out.Counts == nil {
+					if !in.IsDelim(']') {
+						out.Counts = make([]uint64, 0, 8)
+					} else {
+						out.Counts = []uint64{}
+					}
+				} else {
+					out.Counts = (out.Counts)[:0]
+				}
+				for !in.IsDelim(']') {
+					var v1 uint64
+					v1 = uint64(in.Uint64())
+					out.Counts = append(out.Counts, v1)
+					in.WantComma()
+				}
+				in.Delim(']')
+			}
+		case "offset":
+			out.Offset = int(in.Int())
+		default:
+			in.SkipRecursive()
+		}
+		in.WantComma()
+	}
+	in.Delim('}')
+	if isTopLevel {
+		in.Consumed()
+	}
+}
+func easyjsonD2b7633eEncodeGithubComNikolayStrekalovVigilantOctoWaddleGitInternalModels1(out *jwriter.Writer, in SketchBins) {
+	out.RawByte('{')
+	first := true
+	_ = first
+	if len(in.Counts) != 0 {
+		const prefix string = ",\"counts\":"
+		first = false
+		out.RawString(prefix[1:])
+		{
+			out.RawByte('[')
+			for v2, v3 := range in.Counts {
+				if v2 > 0 {
+					out.RawByte(',')
+				}
+				out.Uint64(uint64(v3))
+			}
+			out.RawByte(']')
+		}
+	}
+	{
+		const prefix string = ",\"offset\":"
+		if first {
+			first = false
+			out.RawString(prefix[1:])
+		} else {
+			out.RawString(prefix)
+		}
+		out.Int(int(in.Offset))
+	}
+	out.RawByte('}')
+}
+func easyjsonD2b7633eDecodeGithubComNikolayStrekalovVigilantOctoWaddleGitInternalModels2(in *jlexer.Lexer, out *MetricsSlice) {
 	isTopLevel := in.IsStart()
 	if in.IsNull() {
 		in.Skip()
@@ -26,7 +227,7 @@ func easyjsonD2b7633eDecodeGithubComNikolayStrekalovVigilantOctoWaddleGitInterna
 		in.Delim('[')
 		if *out == nil {
 			if !in.IsDelim(']') {
-				*out = make(MetricsSlice, 0, 1)
+				*out = make(MetricsSlice, 0, 0)
 			} else {
 				*out = MetricsSlice{}
 			}
@@ -34,9 +235,9 @@ func easyjsonD2b7633eDecodeGithubComNikolayStrekalovVigilantOctoWaddleGitInterna
 			*out = (*out)[:0]
 		}
 		for !in.IsDelim(']') {
-			var v1 Metrics
-			(v1).UnmarshalEasyJSON(in)
-			*out = append(*out, v1)
+			var v4 Metrics
+			(v4).UnmarshalEasyJSON(in)
+			*out = append(*out, v4)
 			in.WantComma()
 		}
 		in.Delim(']')
@@ -45,16 +246,16 @@ func easyjsonD2b7633eDecodeGithubComNikolayStrekalovVigilantOctoWaddleGitInterna
 		in.Consumed()
 	}
 }
-func easyjsonD2b7633eEncodeGithubComNikolayStrekalovVigilantOctoWaddleGitInternalModels(out *jwriter.Writer, in MetricsSlice) {
+func easyjsonD2b7633eEncodeGithubComNikolayStrekalovVigilantOctoWaddleGitInternalModels2(out *jwriter.Writer, in MetricsSlice) {
 	if in == nil && (out.Flags&jwriter.NilSliceAsEmpty) == 0 {
 		out.RawString("null")
 	} else {
 		out.RawByte('[')
-		for v2, v3 := range in {
-			if v2 > 0 {
+		for v5, v6 := range in {
+			if v5 > 0 {
 				out.RawByte(',')
 			}
-			(v3).MarshalEasyJSON(out)
+			(v6).MarshalEasyJSON(out)
 		}
 		out.RawByte(']')
 	}
@@ -63,27 +264,27 @@ func easyjsonD2b7633eEncodeGithubComNikolayStrekalovVigilantOctoWaddleGitInterna
 // MarshalJSON supports json.Marshaler interface
 func (v MetricsSlice) MarshalJSON() ([]byte, error) {
 	w := jwriter.Writer{}
-	easyjsonD2b7633eEncodeGithubComNikolayStrekalovVigilantOctoWaddleGitInternalModels(&w, v)
+	easyjsonD2b7633eEncodeGithubComNikolayStrekalovVigilantOctoWaddleGitInternalModels2(&w, v)
 	return w.Buffer.BuildBytes(), w.Error
 }
 
 // MarshalEasyJSON supports easyjson.Marshaler interface
 func (v MetricsSlice) MarshalEasyJSON(w *jwriter.Writer) {
-	easyjsonD2b7633eEncodeGithubComNikolayStrekalovVigilantOctoWaddleGitInternalModels(w, v)
+	easyjsonD2b7633eEncodeGithubComNikolayStrekalovVigilantOctoWaddleGitInternalModels2(w, v)
 }
 
 // UnmarshalJSON supports json.Unmarshaler interface
 func (v *MetricsSlice) UnmarshalJSON(data []byte) error {
 	r := jlexer.Lexer{Data: data}
-	easyjsonD2b7633eDecodeGithubComNikolayStrekalovVigilantOctoWaddleGitInternalModels(&r, v)
+	easyjsonD2b7633eDecodeGithubComNikolayStrekalovVigilantOctoWaddleGitInternalModels2(&r, v)
 	return r.Error()
 }
 
 // UnmarshalEasyJSON supports easyjson.Unmarshaler interface
 func (v *MetricsSlice) UnmarshalEasyJSON(l *jlexer.Lexer) {
-	easyjsonD2b7633eDecodeGithubComNikolayStrekalovVigilantOctoWaddleGitInternalModels(l, v)
+	easyjsonD2b7633eDecodeGithubComNikolayStrekalovVigilantOctoWaddleGitInternalModels2(l, v)
 }
-func easyjsonD2b7633eDecodeGithubComNikolayStrekalovVigilantOctoWaddleGitInternalModels1(in *jlexer.Lexer, out *Metrics) {
+func easyjsonD2b7633eDecodeGithubComNikolayStrekalovVigilantOctoWaddleGitInternalModels3(in *jlexer.Lexer, out *Metrics) {
 	isTopLevel := in.IsStart()
 	if in.IsNull() {
 		if isTopLevel {
@@ -112,6 +313,16 @@ func easyjsonD2b7633eDecodeGithubComNikolayStrekalovVigilantOctoWaddleGitInterna
 				}
 				*out.Delta = int64(in.Int64())
 			}
+		case "total":
+			if in.IsNull() {
+				in.Skip()
+				out.Total = nil
+			} else {
+				if out.Total == nil {
+					out.Total = new(int64)
+				}
+				*out.Total = int64(in.Int64())
+			}
 		case "value":
 			if in.IsNull() {
 				in.Skip()
@@ -122,10 +333,95 @@ func easyjsonD2b7633eDecodeGithubComNikolayStrekalovVigilantOctoWaddleGitInterna
 				}
 				*out.Value = float64(in.Float64())
 			}
+		case "histogram":
+			if in.IsNull() {
+				in.Skip()
+				out.Histogram = nil
+			} else {
+				if out.Histogram == nil {
+					out.Histogram = new(Histogram)
+				}
+				(*out.Histogram).UnmarshalEasyJSON(in)
+			}
+		case "sketch":
+			if in.IsNull() {
+				in.Skip()
+				out.Sketch = nil
+			} else {
+				if out.Sketch == nil {
+					out.Sketch = new(Sketch)
+				}
+				(*out.Sketch).UnmarshalEasyJSON(in)
+			}
+		case "hll":
+			if in.IsNull() {
+				in.Skip()
+				out.HLL = nil
+			} else {
+				if out.HLL == nil {
+					out.HLL = new(HLL)
+				}
+				(*out.HLL).UnmarshalEasyJSON(in)
+			}
+		case "cardinality":
+			if in.IsNull() {
+				in.Skip()
+				out.Cardinality = nil
+			} else {
+				if out.Cardinality == nil {
+					out.Cardinality = new(uint64)
+				}
+				*out.Cardinality = uint64(in.Uint64())
+			}
+		case "members":
+			if in.IsNull() {
+				in.Skip()
+				out.Members = nil
+			} else {
+				in.Delim('[')
+				if out.Members == nil {
+					if !in.IsDelim(']') {
+						out.Members = make([]string, 0, 4)
+					} else {
+						out.Members = []string{}
+					}
+				} else {
+					out.Members = (out.Members)[:0]
+				}
+				for !in.IsDelim(']') {
+					var v7 string
+					v7 = string(in.String())
+					out.Members = append(out.Members, v7)
+					in.WantComma()
+				}
+				in.Delim(']')
+			}
+		case "labels":
+			if in.IsNull() {
+				in.Skip()
+			} else {
+				in.Delim('{')
+				if !in.IsDelim('}') {
+					out.Labels = make(map[string]string)
+				} else {
+					out.Labels = nil
+				}
+				for !in.IsDelim('}') {
+					key := string(in.String())
+					in.WantColon()
+					var v8 string
+					v8 = string(in.String())
+					(out.Labels)[key] = v8
+					in.WantComma()
+				}
+				in.Delim('}')
+			}
 		case "id":
 			out.ID = string(in.String())
 		case "type":
 			out.MType = string(in.String())
+		case "maintenance":
+			out.Maintenance = bool(in.Bool())
 		default:
 			in.SkipRecursive()
 		}
@@ -136,7 +432,7 @@ func easyjsonD2b7633eDecodeGithubComNikolayStrekalovVigilantOctoWaddleGitInterna
 		in.Consumed()
 	}
 }
-func easyjsonD2b7633eEncodeGithubComNikolayStrekalovVigilantOctoWaddleGitInternalModels1(out *jwriter.Writer, in Metrics) {
+func easyjsonD2b7633eEncodeGithubComNikolayStrekalovVigilantOctoWaddleGitInternalModels3(out *jwriter.Writer, in Metrics) {
 	out.RawByte('{')
 	first := true
 	_ = first
@@ -146,6 +442,16 @@ func easyjsonD2b7633eEncodeGithubComNikolayStrekalovVigilantOctoWaddleGitInterna
 		out.RawString(prefix[1:])
 		out.Int64(int64(*in.Delta))
 	}
+	if in.Total != nil {
+		const prefix string = ",\"total\":"
+		if first {
+			first = false
+			out.RawString(prefix[1:])
+		} else {
+			out.RawString(prefix)
+		}
+		out.Int64(int64(*in.Total))
+	}
 	if in.Value != nil {
 		const prefix string = ",\"value\":"
 		if first {
@@ -156,6 +462,89 @@ func easyjsonD2b7633eEncodeGithubComNikolayStrekalovVigilantOctoWaddleGitInterna
 		}
 		out.Float64(float64(*in.Value))
 	}
+	if in.Histogram != nil {
+		const prefix string = ",\"histogram\":"
+		if first {
+			first = false
+			out.RawString(prefix[1:])
+		} else {
+			out.RawString(prefix)
+		}
+		(*in.Histogram).MarshalEasyJSON(out)
+	}
+	if in.Sketch != nil {
+		const prefix string = ",\"sketch\":"
+		if first {
+			first = false
+			out.RawString(prefix[1:])
+		} else {
+			out.RawString(prefix)
+		}
+		(*in.Sketch).MarshalEasyJSON(out)
+	}
+	if in.HLL != nil {
+		const prefix string = ",\"hll\":"
+		if first {
+			first = false
+			out.RawString(prefix[1:])
+		} else {
+			out.RawString(prefix)
+		}
+		(*in.HLL).MarshalEasyJSON(out)
+	}
+	if in.Cardinality != nil {
+		const prefix string = ",\"cardinality\":"
+		if first {
+			first = false
+			out.RawString(prefix[1:])
+		} else {
+			out.RawString(prefix)
+		}
+		out.Uint64(uint64(*in.Cardinality))
+	}
+	if len(in.Members) != 0 {
+		const prefix string = ",\"members\":"
+		if first {
+			first = false
+			out.RawString(prefix[1:])
+		} else {
+			out.RawString(prefix)
+		}
+		{
+			out.RawByte('[')
+			for v9, v10 := range in.Members {
+				if v9 > 0 {
+					out.RawByte(',')
+				}
+				out.String(string(v10))
+			}
+			out.RawByte(']')
+		}
+	}
+	if len(in.Labels) != 0 {
+		const prefix string = ",\"labels\":"
+		if first {
+			first = false
+			out.RawString(prefix[1:])
+		} else {
+			out.RawString(prefix)
+		}
+		{
+			out.RawByte('{')
+			v11First := true
+			for v11Name, v11Value := range in.Labels {
+				if v11First {
+					v11First = false
+				} else {
+					out.RawByte(',')
+				}
+				out.String(string(v11Name))
+				out.RawByte(':')
+				out.String(string(v11Value))
+			}
+			out.RawByte('}')
+		}
+	}
 	{
 		const prefix string = ",\"id\":"
 		if first {
@@ -171,29 +560,1930 @@ func easyjsonD2b7633eEncodeGithubComNikolayStrekalovVigilantOctoWaddleGitInterna
 		out.RawString(prefix)
 		out.String(string(in.MType))
 	}
+	if in.Maintenance {
+		const prefix string = ",\"maintenance\":"
+		out.RawString(prefix)
+		out.Bool(bool(in.Maintenance))
+	}
 	out.RawByte('}')
 }
 
 // MarshalJSON supports json.Marshaler interface
 func (v Metrics) MarshalJSON() ([]byte, error) {
 	w := jwriter.Writer{}
-	easyjsonD2b7633eEncodeGithubComNikolayStrekalovVigilantOctoWaddleGitInternalModels1(&w, v)
+	easyjsonD2b7633eEncodeGithubComNikolayStrekalovVigilantOctoWaddleGitInternalModels3(&w, v)
 	return w.Buffer.BuildBytes(), w.Error
 }
 
 // MarshalEasyJSON supports easyjson.Marshaler interface
 func (v Metrics) MarshalEasyJSON(w *jwriter.Writer) {
-	easyjsonD2b7633eEncodeGithubComNikolayStrekalovVigilantOctoWaddleGitInternalModels1(w, v)
+	easyjsonD2b7633eEncodeGithubComNikolayStrekalovVigilantOctoWaddleGitInternalModels3(w, v)
 }
 
 // UnmarshalJSON supports json.Unmarshaler interface
 func (v *Metrics) UnmarshalJSON(data []byte) error {
 	r := jlexer.Lexer{Data: data}
-	easyjsonD2b7633eDecodeGithubComNikolayStrekalovVigilantOctoWaddleGitInternalModels1(&r, v)
+	easyjsonD2b7633eDecodeGithubComNikolayStrekalovVigilantOctoWaddleGitInternalModels3(&r, v)
 	return r.Error()
 }
 
 // UnmarshalEasyJSON supports easyjson.Unmarshaler interface
 func (v *Metrics) UnmarshalEasyJSON(l *jlexer.Lexer) {
-	easyjsonD2b7633eDecodeGithubComNikolayStrekalovVigilantOctoWaddleGitInternalModels1(l, v)
+	easyjsonD2b7633eDecodeGithubComNikolayStrekalovVigilantOctoWaddleGitInternalModels3(l, v)
+}
+func easyjsonD2b7633eDecodeGithubComNikolayStrekalovVigilantOctoWaddleGitInternalModels4(in *jlexer.Lexer, out *MetricHistory) {
+	isTopLevel := in.IsStart()
+	if in.IsNull() {
+		if isTopLevel {
+			in.Consumed()
+		}
+		in.Skip()
+		return
+	}
+	in.Delim('{')
+	for !in.IsDelim('}') {
+		key := in.UnsafeFieldName(false)
+		in.WantColon()
+		if in.IsNull() {
+			in.Skip()
+			in.WantComma()
+			continue
+		}
+		switch key {
+		case "labels":
+			if in.IsNull() {
+				in.Skip()
+			} else {
+				in.Delim('{')
+				if !in.IsDelim('}') {
+					out.Labels = make(map[string]string)
+				} else {
+					out.Labels = nil
+				}
+				for !in.IsDelim('}') {
+					key := string(in.String())
+					in.WantColon()
+					var v12 string
+					v12 = string(in.String())
+					(out.Labels)[key] = v12
+					in.WantComma()
+				}
+				in.Delim('}')
+			}
+		case "id":
+			out.ID = string(in.String())
+		case "type":
+			out.MType = string(in.String())
+		case "points":
+			if in.IsNull() {
+				in.Skip()
+				out.Points = nil
+			} else {
+				in.Delim('[')
+				if out.Points == nil {
+					if !in.IsDelim(']') {
+						out.Points = make([]HistoryPoint, 0, 1)
+					} else {
+						out.Points = []HistoryPoint{}
+					}
+				} else {
+					out.Points = (out.Points)[:0]
+				}
+				for !in.IsDelim(']') {
+					var v13 HistoryPoint
+					(v13).UnmarshalEasyJSON(in)
+					out.Points = append(out.Points, v13)
+					in.WantComma()
+				}
+				in.Delim(']')
+			}
+		default:
+			in.SkipRecursive()
+		}
+		in.WantComma()
+	}
+	in.Delim('}')
+	if isTopLevel {
+		in.Consumed()
+	}
+}
+func easyjsonD2b7633eEncodeGithubComNikolayStrekalovVigilantOctoWaddleGitInternalModels4(out *jwriter.Writer, in MetricHistory) {
+	out.RawByte('{')
+	first := true
+	_ = first
+	if len(in.Labels) != 0 {
+		const prefix string = ",\"labels\":"
+		first = false
+		out.RawString(prefix[1:])
+		{
+			out.RawByte('{')
+			v14First := true
+			for v14Name, v14Value := range in.Labels {
+				if v14First {
+					v14First = false
+				} else {
+					out.RawByte(',')
+				}
+				out.String(string(v14Name))
+				out.RawByte(':')
+				out.String(string(v14Value))
+			}
+			out.RawByte('}')
+		}
+	}
+	{
+		const prefix string = ",\"id\":"
+		if first {
+			first = false
+			out.RawString(prefix[1:])
+		} else {
+			out.RawString(prefix)
+		}
+		out.String(string(in.ID))
+	}
+	{
+		const prefix string = ",\"type\":"
+		out.RawString(prefix)
+		out.String(string(in.MType))
+	}
+	{
+		const prefix string = ",\"points\":"
+		out.RawString(prefix)
+		if in.Points == nil && (out.Flags&jwriter.NilSliceAsEmpty) == 0 {
+			out.RawString("null")
+		} else {
+			out.RawByte('[')
+			for v15, v16 := range in.Points {
+				if v15 > 0 {
+					out.RawByte(',')
+				}
+				(v16).MarshalEasyJSON(out)
+			}
+			out.RawByte(']')
+		}
+	}
+	out.RawByte('}')
+}
+
+// MarshalJSON supports json.Marshaler interface
+func (v MetricHistory) MarshalJSON() ([]byte, error) {
+	w := jwriter.Writer{}
+	easyjsonD2b7633eEncodeGithubComNikolayStrekalovVigilantOctoWaddleGitInternalModels4(&w, v)
+	return w.Buffer.BuildBytes(), w.Error
+}
+
+// MarshalEasyJSON supports easyjson.Marshaler interface
+func (v MetricHistory) MarshalEasyJSON(w *jwriter.Writer) {
+	easyjsonD2b7633eEncodeGithubComNikolayStrekalovVigilantOctoWaddleGitInternalModels4(w, v)
+}
+
+// UnmarshalJSON supports json.Unmarshaler interface
+func (v *MetricHistory) UnmarshalJSON(data []byte) error {
+	r := jlexer.Lexer{Data: data}
+	easyjsonD2b7633eDecodeGithubComNikolayStrekalovVigilantOctoWaddleGitInternalModels4(&r, v)
+	return r.Error()
+}
+
+// UnmarshalEasyJSON supports easyjson.Unmarshaler interface
+func (v *MetricHistory) UnmarshalEasyJSON(l *jlexer.Lexer) {
+	easyjsonD2b7633eDecodeGithubComNikolayStrekalovVigilantOctoWaddleGitInternalModels4(l, v)
+}
+func easyjsonD2b7633eDecodeGithubComNikolayStrekalovVigilantOctoWaddleGitInternalModels5(in *jlexer.Lexer, out *MaintenanceWindows) {
+	isTopLevel := in.IsStart()
+	if in.IsNull() {
+		in.Skip()
+		*out = nil
+	} else {
+		in.Delim('[')
+		if *out == nil {
+			if !in.IsDelim(']') {
+				*out = make(MaintenanceWindows, 0, 0)
+			} else {
+				*out = MaintenanceWindows{}
+			}
+		} else {
+			*out = (*out)[:0]
+		}
+		for !in.IsDelim(']') {
+			var v17 MaintenanceWindow
+			(v17).UnmarshalEasyJSON(in)
+			*out = append(*out, v17)
+			in.WantComma()
+		}
+		in.Delim(']')
+	}
+	if isTopLevel {
+		in.Consumed()
+	}
+}
+func easyjsonD2b7633eEncodeGithubComNikolayStrekalovVigilantOctoWaddleGitInternalModels5(out *jwriter.Writer, in MaintenanceWindows) {
+	if in == nil && (out.Flags&jwriter.NilSliceAsEmpty) == 0 {
+		out.RawString("null")
+	} else {
+		out.RawByte('[')
+		for v18, v19 := range in {
+			if v18 > 0 {
+				out.RawByte(',')
+			}
+			(v19).MarshalEasyJSON(out)
+		}
+		out.RawByte(']')
+	}
+}
+
+// MarshalJSON supports json.Marshaler interface
+func (v MaintenanceWindows) MarshalJSON() ([]byte, error) {
+	w := jwriter.Writer{}
+	easyjsonD2b7633eEncodeGithubComNikolayStrekalovVigilantOctoWaddleGitInternalModels5(&w, v)
+	return w.Buffer.BuildBytes(), w.Error
+}
+
+// MarshalEasyJSON supports easyjson.Marshaler interface
+func (v MaintenanceWindows) MarshalEasyJSON(w *jwriter.Writer) {
+	easyjsonD2b7633eEncodeGithubComNikolayStrekalovVigilantOctoWaddleGitInternalModels5(w, v)
+}
+
+// UnmarshalJSON supports json.Unmarshaler interface
+func (v *MaintenanceWindows) UnmarshalJSON(data []byte) error {
+	r := jlexer.Lexer{Data: data}
+	easyjsonD2b7633eDecodeGithubComNikolayStrekalovVigilantOctoWaddleGitInternalModels5(&r, v)
+	return r.Error()
+}
+
+// UnmarshalEasyJSON supports easyjson.Unmarshaler interface
+func (v *MaintenanceWindows) UnmarshalEasyJSON(l *jlexer.Lexer) {
+	easyjsonD2b7633eDecodeGithubComNikolayStrekalovVigilantOctoWaddleGitInternalModels5(l, v)
+}
+func easyjsonD2b7633eDecodeGithubComNikolayStrekalovVigilantOctoWaddleGitInternalModels6(in *jlexer.Lexer, out *MaintenanceWindow) {
+	isTopLevel := in.IsStart()
+	if in.IsNull() {
+		if isTopLevel {
+			in.Consumed()
+		}
+		in.Skip()
+		return
+	}
+	in.Delim('{')
+	for !in.IsDelim('}') {
+		key := in.UnsafeFieldName(false)
+		in.WantColon()
+		if in.IsNull() {
+			in.Skip()
+			in.WantComma()
+			continue
+		}
+		switch key {
+		case "start":
+			if data := in.Raw(); in.Ok() {
+				in.AddError((out.Start).UnmarshalJSON(data))
+			}
+		case "end":
+			if data := in.Raw(); in.Ok() {
+				in.AddError((out.End).UnmarshalJSON(data))
+			}
+		case "pattern":
+			out.Pattern = string(in.String())
+		case "mode":
+			out.Mode = string(in.String())
+		case "comment":
+			out.Comment = string(in.String())
+		case "id":
+			out.ID = int64(in.Int64())
+		default:
+			in.SkipRecursive()
+		}
+		in.WantComma()
+	}
+	in.Delim('}')
+	if isTopLevel {
+		in.Consumed()
+	}
+}
+func easyjsonD2b7633eEncodeGithubComNikolayStrekalovVigilantOctoWaddleGitInternalModels6(out *jwriter.Writer, in MaintenanceWindow) {
+	out.RawByte('{')
+	first := true
+	_ = first
+	{
+		const prefix string = ",\"start\":"
+		out.RawString(prefix[1:])
+		out.Raw((in.Start).MarshalJSON())
+	}
+	{
+		const prefix string = ",\"end\":"
+		out.RawString(prefix)
+		out.Raw((in.End).MarshalJSON())
+	}
+	{
+		const prefix string = ",\"pattern\":"
+		out.RawString(prefix)
+		out.String(string(in.Pattern))
+	}
+	if in.Mode != "" {
+		const prefix string = ",\"mode\":"
+		out.RawString(prefix)
+		out.String(string(in.Mode))
+	}
+	if in.Comment != "" {
+		const prefix string = ",\"comment\":"
+		out.RawString(prefix)
+		out.String(string(in.Comment))
+	}
+	{
+		const prefix string = ",\"id\":"
+		out.RawString(prefix)
+		out.Int64(int64(in.ID))
+	}
+	out.RawByte('}')
+}
+
+// MarshalJSON supports json.Marshaler interface
+func (v MaintenanceWindow) MarshalJSON() ([]byte, error) {
+	w := jwriter.Writer{}
+	easyjsonD2b7633eEncodeGithubComNikolayStrekalovVigilantOctoWaddleGitInternalModels6(&w, v)
+	return w.Buffer.BuildBytes(), w.Error
+}
+
+// MarshalEasyJSON supports easyjson.Marshaler interface
+func (v MaintenanceWindow) MarshalEasyJSON(w *jwriter.Writer) {
+	easyjsonD2b7633eEncodeGithubComNikolayStrekalovVigilantOctoWaddleGitInternalModels6(w, v)
+}
+
+// UnmarshalJSON supports json.Unmarshaler interface
+func (v *MaintenanceWindow) UnmarshalJSON(data []byte) error {
+	r := jlexer.Lexer{Data: data}
+	easyjsonD2b7633eDecodeGithubComNikolayStrekalovVigilantOctoWaddleGitInternalModels6(&r, v)
+	return r.Error()
+}
+
+// UnmarshalEasyJSON supports easyjson.Unmarshaler interface
+func (v *MaintenanceWindow) UnmarshalEasyJSON(l *jlexer.Lexer) {
+	easyjsonD2b7633eDecodeGithubComNikolayStrekalovVigilantOctoWaddleGitInternalModels6(l, v)
+}
+func easyjsonD2b7633eDecodeGithubComNikolayStrekalovVigilantOctoWaddleGitInternalModels7(in *jlexer.Lexer, out *HistoryPoint) {
+	isTopLevel := in.IsStart()
+	if in.IsNull() {
+		if isTopLevel {
+			in.Consumed()
+		}
+		in.Skip()
+		return
+	}
+	in.Delim('{')
+	for !in.IsDelim('}') {
+		key := in.UnsafeFieldName(false)
+		in.WantColon()
+		if in.IsNull() {
+			in.Skip()
+			in.WantComma()
+			continue
+		}
+		switch key {
+		case "ts":
+			if data := in.Raw(); in.Ok() {
+				in.AddError((out.Time).UnmarshalJSON(data))
+			}
+		case "min":
+			if in.IsNull() {
+				in.Skip()
+				out.Min = nil
+			} else {
+				if out.Min == nil {
+					out.Min = new(float64)
+				}
+				*out.Min = float64(in.Float64())
+			}
+		case "max":
+			if in.IsNull() {
+				in.Skip()
+				out.Max = nil
+			} else {
+				if out.Max == nil {
+					out.Max = new(float64)
+				}
+				*out.Max = float64(in.Float64())
+			}
+		case "avg":
+			if in.IsNull() {
+				in.Skip()
+				out.Avg = nil
+			} else {
+				if out.Avg == nil {
+					out.Avg = new(float64)
+				}
+				*out.Avg = float64(in.Float64())
+			}
+		case "value":
+			out.Value = float64(in.Float64())
+		default:
+			in.SkipRecursive()
+		}
+		in.WantComma()
+	}
+	in.Delim('}')
+	if isTopLevel {
+		in.Consumed()
+	}
+}
+func easyjsonD2b7633eEncodeGithubComNikolayStrekalovVigilantOctoWaddleGitInternalModels7(out *jwriter.Writer, in HistoryPoint) {
+	out.RawByte('{')
+	first := true
+	_ = first
+	{
+		const prefix string = ",\"ts\":"
+		out.RawString(prefix[1:])
+		out.Raw((in.Time).MarshalJSON())
+	}
+	if in.Min != nil {
+		const prefix string = ",\"min\":"
+		out.RawString(prefix)
+		out.Float64(float64(*in.Min))
+	}
+	if in.Max != nil {
+		const prefix string = ",\"max\":"
+		out.RawString(prefix)
+		out.Float64(float64(*in.Max))
+	}
+	if in.Avg != nil {
+		const prefix string = ",\"avg\":"
+		out.RawString(prefix)
+		out.Float64(float64(*in.Avg))
+	}
+	{
+		const prefix string = ",\"value\":"
+		out.RawString(prefix)
+		out.Float64(float64(in.Value))
+	}
+	out.RawByte('}')
+}
+
+// MarshalJSON supports json.Marshaler interface
+func (v HistoryPoint) MarshalJSON() ([]byte, error) {
+	w := jwriter.Writer{}
+	easyjsonD2b7633eEncodeGithubComNikolayStrekalovVigilantOctoWaddleGitInternalModels7(&w, v)
+	return w.Buffer.BuildBytes(), w.Error
+}
+
+// MarshalEasyJSON supports easyjson.Marshaler interface
+func (v HistoryPoint) MarshalEasyJSON(w *jwriter.Writer) {
+	easyjsonD2b7633eEncodeGithubComNikolayStrekalovVigilantOctoWaddleGitInternalModels7(w, v)
+}
+
+// UnmarshalJSON supports json.Unmarshaler interface
+func (v *HistoryPoint) UnmarshalJSON(data []byte) error {
+	r := jlexer.Lexer{Data: data}
+	easyjsonD2b7633eDecodeGithubComNikolayStrekalovVigilantOctoWaddleGitInternalModels7(&r, v)
+	return r.Error()
+}
+
+// UnmarshalEasyJSON supports easyjson.Unmarshaler interface
+func (v *HistoryPoint) UnmarshalEasyJSON(l *jlexer.Lexer) {
+	easyjsonD2b7633eDecodeGithubComNikolayStrekalovVigilantOctoWaddleGitInternalModels7(l, v)
+}
+func easyjsonD2b7633eDecodeGithubComNikolayStrekalovVigilantOctoWaddleGitInternalModels8(in *jlexer.Lexer, out *Histogram) {
+	isTopLevel := in.IsStart()
+	if in.IsNull() {
+		if isTopLevel {
+			in.Consumed()
+		}
+		in.Skip()
+		return
+	}
+	in.Delim('{')
+	for !in.IsDelim('}') {
+		key := in.UnsafeFieldName(false)
+		in.WantColon()
+		if in.IsNull() {
+			in.Skip()
+			in.WantComma()
+			continue
+		}
+		switch key {
+		case "bounds":
+			if in.IsNull() {
+				in.Skip()
+				out.Bounds = nil
+			} else {
+				in.Delim('[')
+				if out.Bounds == nil {
+					if !in.IsDelim(']') {
+						out.Bounds = make([]float64, 0, 8)
+					} else {
+						out.Bounds = []float64{}
+					}
+				} else {
+					out.Bounds = (out.Bounds)[:0]
+				}
+				for !in.IsDelim(']') {
+					var v20 float64
+					v20 = float64(in.Float64())
+					out.Bounds = append(out.Bounds, v20)
+					in.WantComma()
+				}
+				in.Delim(']')
+			}
+		case "counts":
+			if in.IsNull() {
+				in.Skip()
+				out.Counts = nil
+			} else {
+				in.Delim('[')
+				if out.Counts == nil {
+					if !in.IsDelim(']') {
+						out.Counts = make([]uint64, 0, 8)
+					} else {
+						out.Counts = []uint64{}
+					}
+				} else {
+					out.Counts = (out.Counts)[:0]
+				}
+				for !in.IsDelim(']') {
+					var v21 uint64
+					v21 = uint64(in.Uint64())
+					out.Counts = append(out.Counts, v21)
+					in.WantComma()
+				}
+				in.Delim(']')
+			}
+		case "sum":
+			out.Sum = float64(in.Float64())
+		case "count":
+			out.Count = uint64(in.Uint64())
+		default:
+			in.SkipRecursive()
+		}
+		in.WantComma()
+	}
+	in.Delim('}')
+	if isTopLevel {
+		in.Consumed()
+	}
+}
+func easyjsonD2b7633eEncodeGithubComNikolayStrekalovVigilantOctoWaddleGitInternalModels8(out *jwriter.Writer, in Histogram) {
+	out.RawByte('{')
+	first := true
+	_ = first
+	{
+		const prefix string = ",\"bounds\":"
+		out.RawString(prefix[1:])
+		if in.Bounds == nil && (out.Flags&jwriter.NilSliceAsEmpty) == 0 {
+			out.RawString("null")
+		} else {
+			out.RawByte('[')
+			for v22, v23 := range in.Bounds {
+				if v22 > 0 {
+					out.RawByte(',')
+				}
+				out.Float64(float64(v23))
+			}
+			out.RawByte(']')
+		}
+	}
+	{
+		const prefix string = ",\"counts\":"
+		out.RawString(prefix)
+		if in.Counts == nil && (out.Flags&jwriter.NilSliceAsEmpty) == 0 {
+			out.RawString("null")
+		} else {
+			out.RawByte('[')
+			for v24, v25 := range in.Counts {
+				if v24 > 0 {
+					out.RawByte(',')
+				}
+				out.Uint64(uint64(v25))
+			}
+			out.RawByte(']')
+		}
+	}
+	{
+		const prefix string = ",\"sum\":"
+		out.RawString(prefix)
+		out.Float64(float64(in.Sum))
+	}
+	{
+		const prefix string = ",\"count\":"
+		out.RawString(prefix)
+		out.Uint64(uint64(in.Count))
+	}
+	out.RawByte('}')
+}
+
+// MarshalJSON supports json.Marshaler interface
+func (v Histogram) MarshalJSON() ([]byte, error) {
+	w := jwriter.Writer{}
+	easyjsonD2b7633eEncodeGithubComNikolayStrekalovVigilantOctoWaddleGitInternalModels8(&w, v)
+	return w.Buffer.BuildBytes(), w.Error
+}
+
+// MarshalEasyJSON supports easyjson.Marshaler interface
+func (v Histogram) MarshalEasyJSON(w *jwriter.Writer) {
+	easyjsonD2b7633eEncodeGithubComNikolayStrekalovVigilantOctoWaddleGitInternalModels8(w, v)
+}
+
+// UnmarshalJSON supports json.Unmarshaler interface
+func (v *Histogram) UnmarshalJSON(data []byte) error {
+	r := jlexer.Lexer{Data: data}
+	easyjsonD2b7633eDecodeGithubComNikolayStrekalovVigilantOctoWaddleGitInternalModels8(&r, v)
+	return r.Error()
+}
+
+// UnmarshalEasyJSON supports easyjson.Unmarshaler interface
+func (v *Histogram) UnmarshalEasyJSON(l *jlexer.Lexer) {
+	easyjsonD2b7633eDecodeGithubComNikolayStrekalovVigilantOctoWaddleGitInternalModels8(l, v)
+}
+func easyjsonD2b7633eDecodeGithubComNikolayStrekalovVigilantOctoWaddleGitInternalModels9(in *jlexer.Lexer, out *HLL) {
+	isTopLevel := in.IsStart()
+	if in.IsNull() {
+		if isTopLevel {
+			in.Consumed()
+		}
+		in.Skip()
+		return
+	}
+	in.Delim('{')
+	for !in.IsDelim('}') {
+		key := in.UnsafeFieldName(false)
+		in.WantColon()
+		if in.IsNull() {
+			in.Skip()
+			in.WantComma()
+			continue
+		}
+		switch key {
+		case "registers":
+			if in.IsNull() {
+				in.Skip()
+				out.Registers = nil
+			} else {
+				out.Registers = in.Bytes()
+			}
+		case "precision":
+			out.Precision = uint8(in.Uint8())
+		default:
+			in.SkipRecursive()
+		}
+		in.WantComma()
+	}
+	in.Delim('}')
+	if isTopLevel {
+		in.Consumed()
+	}
+}
+func easyjsonD2b7633eEncodeGithubComNikolayStrekalovVigilantOctoWaddleGitInternalModels9(out *jwriter.Writer, in HLL) {
+	out.RawByte('{')
+	first := true
+	_ = first
+	{
+		const prefix string = ",\"registers\":"
+		out.RawString(prefix[1:])
+		out.Base64Bytes(in.Registers)
+	}
+	{
+		const prefix string = ",\"precision\":"
+		out.RawString(prefix)
+		out.Uint8(uint8(in.Precision))
+	}
+	out.RawByte('}')
+}
+
+// MarshalJSON supports json.Marshaler interface
+func (v HLL) MarshalJSON() ([]byte, error) {
+	w := jwriter.Writer{}
+	easyjsonD2b7633eEncodeGithubComNikolayStrekalovVigilantOctoWaddleGitInternalModels9(&w, v)
+	return w.Buffer.BuildBytes(), w.Error
+}
+
+// MarshalEasyJSON supports easyjson.Marshaler interface
+func (v HLL) MarshalEasyJSON(w *jwriter.Writer) {
+	easyjsonD2b7633eEncodeGithubComNikolayStrekalovVigilantOctoWaddleGitInternalModels9(w, v)
+}
+
+// UnmarshalJSON supports json.Unmarshaler interface
+func (v *HLL) UnmarshalJSON(data []byte) error {
+	r := jlexer.Lexer{Data: data}
+	easyjsonD2b7633eDecodeGithubComNikolayStrekalovVigilantOctoWaddleGitInternalModels9(&r, v)
+	return r.Error()
+}
+
+// UnmarshalEasyJSON supports easyjson.Unmarshaler interface
+func (v *HLL) UnmarshalEasyJSON(l *jlexer.Lexer) {
+	easyjsonD2b7633eDecodeGithubComNikolayStrekalovVigilantOctoWaddleGitInternalModels9(l, v)
+}
+func easyjsonD2b7633eDecodeGithubComNikolayStrekalovVigilantOctoWaddleGitInternalModels10(in *jlexer.Lexer, out *Deleted) {
+	isTopLevel := in.IsStart()
+	if in.IsNull() {
+		if isTopLevel {
+			in.Consumed()
+		}
+		in.Skip()
+		return
+	}
+	in.Delim('{')
+	for !in.IsDelim('}') {
+		key := in.UnsafeFieldName(false)
+		in.WantColon()
+		if in.IsNull() {
+			in.Skip()
+			in.WantComma()
+			continue
+		}
+		switch key {
+		case "deleted":
+			out.Deleted = int64(in.Int64())
+		default:
+			in.SkipRecursive()
+		}
+		in.WantComma()
+	}
+	in.Delim('}')
+	if isTopLevel {
+		in.Consumed()
+	}
+}
+func easyjsonD2b7633eEncodeGithubComNikolayStrekalovVigilantOctoWaddleGitInternalModels10(out *jwriter.Writer, in Deleted) {
+	out.RawByte('{')
+	first := true
+	_ = first
+	{
+		const prefix string = ",\"deleted\":"
+		out.RawString(prefix[1:])
+		out.Int64(int64(in.Deleted))
+	}
+	out.RawByte('}')
+}
+
+// MarshalJSON supports json.Marshaler interface
+func (v Deleted) MarshalJSON() ([]byte, error) {
+	w := jwriter.Writer{}
+	easyjsonD2b7633eEncodeGithubComNikolayStrekalovVigilantOctoWaddleGitInternalModels10(&w, v)
+	return w.Buffer.BuildBytes(), w.Error
+}
+
+// MarshalEasyJSON supports easyjson.Marshaler interface
+func (v Deleted) MarshalEasyJSON(w *jwriter.Writer) {
+	easyjsonD2b7633eEncodeGithubComNikolayStrekalovVigilantOctoWaddleGitInternalModels10(w, v)
+}
+
+// UnmarshalJSON supports json.Unmarshaler interface
+func (v *Deleted) UnmarshalJSON(data []byte) error {
+	r := jlexer.Lexer{Data: data}
+	easyjsonD2b7633eDecodeGithubComNikolayStrekalovVigilantOctoWaddleGitInternalModels10(&r, v)
+	return r.Error()
+}
+
+// UnmarshalEasyJSON supports easyjson.Unmarshaler interface
+func (v *Deleted) UnmarshalEasyJSON(l *jlexer.Lexer) {
+	easyjsonD2b7633eDecodeGithubComNikolayStrekalovVigilantOctoWaddleGitInternalModels10(l, v)
+}
+func easyjsonD2b7633eDecodeGithubComNikolayStrekalovVigilantOctoWaddleGitInternalModels11(in *jlexer.Lexer, out *CounterRate) {
+	isTopLevel := in.IsStart()
+	if in.IsNull() {
+		if isTopLevel {
+			in.Consumed()
+		}
+		in.Skip()
+		return
+	}
+	in.Delim('{')
+	for !in.IsDelim('}') {
+		key := in.UnsafeFieldName(false)
+		in.WantColon()
+		if in.IsNull() {
+			in.Skip()
+			in.WantComma()
+			continue
+		}
+		switch key {
+		case "labels":
+			if in.IsNull() {
+				in.Skip()
+			} else {
+				in.Delim('{')
+				if !in.IsDelim('}') {
+					out.Labels = make(map[string]string)
+				} else {
+					out.Labels = nil
+				}
+				for !in.IsDelim('}') {
+					key := string(in.String())
+					in.WantColon()
+					var v29 string
+					v29 = string(in.String())
+					(out.Labels)[key] = v29
+					in.WantComma()
+				}
+				in.Delim('}')
+			}
+		case "id":
+			out.ID = string(in.String())
+		case "window":
+			out.Window = float64(in.Float64())
+		case "value":
+			out.Value = float64(in.Float64())
+		default:
+			in.SkipRecursive()
+		}
+		in.WantComma()
+	}
+	in.Delim('}')
+	if isTopLevel {
+		in.Consumed()
+	}
+}
+func easyjsonD2b7633eEncodeGithubComNikolayStrekalovVigilantOctoWaddleGitInternalModels11(out *jwriter.Writer, in CounterRate) {
+	out.RawByte('{')
+	first := true
+	_ = first
+	if len(in.Labels) != 0 {
+		const prefix string = ",\"labels\":"
+		first = false
+		out.RawString(prefix[1:])
+		{
+			out.RawByte('{')
+			v30First := true
+			for v30Name, v30Value := range in.Labels {
+				if v30First {
+					v30First = false
+				} else {
+					out.RawByte(',')
+				}
+				out.String(string(v30Name))
+				out.RawByte(':')
+				out.String(string(v30Value))
+			}
+			out.RawByte('}')
+		}
+	}
+	{
+		const prefix string = ",\"id\":"
+		if first {
+			first = false
+			out.RawString(prefix[1:])
+		} else {
+			out.RawString(prefix)
+		}
+		out.String(string(in.ID))
+	}
+	{
+		const prefix string = ",\"window\":"
+		out.RawString(prefix)
+		out.Float64(float64(in.Window))
+	}
+	{
+		const prefix string = ",\"value\":"
+		out.RawString(prefix)
+		out.Float64(float64(in.Value))
+	}
+	out.RawByte('}')
+}
+
+// MarshalJSON supports json.Marshaler interface
+func (v CounterRate) MarshalJSON() ([]byte, error) {
+	w := jwriter.Writer{}
+	easyjsonD2b7633eEncodeGithubComNikolayStrekalovVigilantOctoWaddleGitInternalModels11(&w, v)
+	return w.Buffer.BuildBytes(), w.Error
+}
+
+// MarshalEasyJSON supports easyjson.Marshaler interface
+func (v CounterRate) MarshalEasyJSON(w *jwriter.Writer) {
+	easyjsonD2b7633eEncodeGithubComNikolayStrekalovVigilantOctoWaddleGitInternalModels11(w, v)
+}
+
+// UnmarshalJSON supports json.Unmarshaler interface
+func (v *CounterRate) UnmarshalJSON(data []byte) error {
+	r := jlexer.Lexer{Data: data}
+	easyjsonD2b7633eDecodeGithubComNikolayStrekalovVigilantOctoWaddleGitInternalModels11(&r, v)
+	return r.Error()
+}
+
+// UnmarshalEasyJSON supports easyjson.Unmarshaler interface
+func (v *CounterRate) UnmarshalEasyJSON(l *jlexer.Lexer) {
+	easyjsonD2b7633eDecodeGithubComNikolayStrekalovVigilantOctoWaddleGitInternalModels11(l, v)
+}
+func easyjsonD2b7633eDecodeGithubComNikolayStrekalovVigilantOctoWaddleGitInternalModels12(in *jlexer.Lexer, out *Anomaly) {
+	isTopLevel := in.IsStart()
+	if in.IsNull() {
+		if isTopLevel {
+			in.Consumed()
+		}
+		in.Skip()
+		return
+	}
+	in.Delim('{')
+	for !in.IsDelim('}') {
+		key := in.UnsafeFieldName(false)
+		in.WantColon()
+		if in.IsNull() {
+			in.Skip()
+			in.WantComma()
+			continue
+		}
+		switch key {
+		case "detectedAt":
+			if data := in.Raw(); in.Ok() {
+				in.AddError((out.DetectedAt).UnmarshalJSON(data))
+			}
+		case "name":
+			out.Name = string(in.String())
+		case "value":
+			out.Value = float64(in.Float64())
+		case "mean":
+			out.Mean = float64(in.Float64())
+		case "stddev":
+			out.StdDev = float64(in.Float64())
+		case "score":
+			out.Score = float64(in.Float64())
+		case "sigma":
+			out.Sigma = float64(in.Float64())
+		default:
+			in.SkipRecursive()
+		}
+		in.WantComma()
+	}
+	in.Delim('}')
+	if isTopLevel {
+		in.Consumed()
+	}
+}
+func easyjsonD2b7633eEncodeGithubComNikolayStrekalovVigilantOctoWaddleGitInternalModels12(out *jwriter.Writer, in Anomaly) {
+	out.RawByte('{')
+	first := true
+	_ = first
+	{
+		const prefix string = ",\"detectedAt\":"
+		out.RawString(prefix[1:])
+		out.Raw((in.DetectedAt).MarshalJSON())
+	}
+	{
+		const prefix string = ",\"name\":"
+		out.RawString(prefix)
+		out.String(string(in.Name))
+	}
+	{
+		const prefix string = ",\"value\":"
+		out.RawString(prefix)
+		out.Float64(float64(in.Value))
+	}
+	{
+		const prefix string = ",\"mean\":"
+		out.RawString(prefix)
+		out.Float64(float64(in.Mean))
+	}
+	{
+		const prefix string = ",\"stddev\":"
+		out.RawString(prefix)
+		out.Float64(float64(in.StdDev))
+	}
+	{
+		const prefix string = ",\"score\":"
+		out.RawString(prefix)
+		out.Float64(float64(in.Score))
+	}
+	{
+		const prefix string = ",\"sigma\":"
+		out.RawString(prefix)
+		out.Float64(float64(in.Sigma))
+	}
+	out.RawByte('}')
+}
+
+// MarshalJSON supports json.Marshaler interface
+func (v Anomaly) MarshalJSON() ([]byte, error) {
+	w := jwriter.Writer{}
+	easyjsonD2b7633eEncodeGithubComNikolayStrekalovVigilantOctoWaddleGitInternalModels12(&w, v)
+	return w.Buffer.BuildBytes(), w.Error
+}
+
+// MarshalEasyJSON supports easyjson.Marshaler interface
+func (v Anomaly) MarshalEasyJSON(w *jwriter.Writer) {
+	easyjsonD2b7633eEncodeGithubComNikolayStrekalovVigilantOctoWaddleGitInternalModels12(w, v)
+}
+
+// UnmarshalJSON supports json.Unmarshaler interface
+func (v *Anomaly) UnmarshalJSON(data []byte) error {
+	r := jlexer.Lexer{Data: data}
+	easyjsonD2b7633eDecodeGithubComNikolayStrekalovVigilantOctoWaddleGitInternalModels12(&r, v)
+	return r.Error()
+}
+
+// UnmarshalEasyJSON supports easyjson.Unmarshaler interface
+func (v *Anomaly) UnmarshalEasyJSON(l *jlexer.Lexer) {
+	easyjsonD2b7633eDecodeGithubComNikolayStrekalovVigilantOctoWaddleGitInternalModels12(l, v)
+}
+func easyjsonD2b7633eDecodeGithubComNikolayStrekalovVigilantOctoWaddleGitInternalModels13(in *jlexer.Lexer, out *Anomalies) {
+	isTopLevel := in.IsStart()
+	if in.IsNull() {
+		in.Skip()
+		*out = nil
+	} else {
+		in.Delim('[')
+		if *out == nil {
+			if !in.IsDelim(']') {
+				*out = make(Anomalies, 0, 0)
+			} else {
+				*out = Anomalies{}
+			}
+		} else {
+			*out = (*out)[:0]
+		}
+		for !in.IsDelim(']') {
+			var v31 Anomaly
+			(v31).UnmarshalEasyJSON(in)
+			*out = append(*out, v31)
+			in.WantComma()
+		}
+		in.Delim(']')
+	}
+	if isTopLevel {
+		in.Consumed()
+	}
+}
+func easyjsonD2b7633eEncodeGithubComNikolayStrekalovVigilantOctoWaddleGitInternalModels13(out *jwriter.Writer, in Anomalies) {
+	if in == nil && (out.Flags&jwriter.NilSliceAsEmpty) == 0 {
+		out.RawString("null")
+	} else {
+		out.RawByte('[')
+		for v32, v33 := range in {
+			if v32 > 0 {
+				out.RawByte(',')
+			}
+			(v33).MarshalEasyJSON(out)
+		}
+		out.RawByte(']')
+	}
+}
+
+// MarshalJSON supports json.Marshaler interface
+func (v Anomalies) MarshalJSON() ([]byte, error) {
+	w := jwriter.Writer{}
+	easyjsonD2b7633eEncodeGithubComNikolayStrekalovVigilantOctoWaddleGitInternalModels13(&w, v)
+	return w.Buffer.BuildBytes(), w.Error
+}
+
+// MarshalEasyJSON supports easyjson.Marshaler interface
+func (v Anomalies) MarshalEasyJSON(w *jwriter.Writer) {
+	easyjsonD2b7633eEncodeGithubComNikolayStrekalovVigilantOctoWaddleGitInternalModels13(w, v)
+}
+
+// UnmarshalJSON supports json.Unmarshaler interface
+func (v *Anomalies) UnmarshalJSON(data []byte) error {
+	r := jlexer.Lexer{Data: data}
+	easyjsonD2b7633eDecodeGithubComNikolayStrekalovVigilantOctoWaddleGitInternalModels13(&r, v)
+	return r.Error()
+}
+
+// UnmarshalEasyJSON supports easyjson.Unmarshaler interface
+func (v *Anomalies) UnmarshalEasyJSON(l *jlexer.Lexer) {
+	easyjsonD2b7633eDecodeGithubComNikolayStrekalovVigilantOctoWaddleGitInternalModels13(l, v)
+}
+func easyjsonD2b7633eDecodeGithubComNikolayStrekalovVigilantOctoWaddleGitInternalModels14(in *jlexer.Lexer, out *AlertStatuses) {
+	isTopLevel := in.IsStart()
+	if in.IsNull() {
+		in.Skip()
+		*out = nil
+	} else {
+		in.Delim('[')
+		if *out == nil {
+			if !in.IsDelim(']') {
+				*out = make(AlertStatuses, 0, 0)
+			} else {
+				*out = AlertStatuses{}
+			}
+		} else {
+			*out = (*out)[:0]
+		}
+		for !in.IsDelim(']') {
+			var v34 AlertStatus
+			(v34).UnmarshalEasyJSON(in)
+			*out = append(*out, v34)
+			in.WantComma()
+		}
+		in.Delim(']')
+	}
+	if isTopLevel {
+		in.Consumed()
+	}
+}
+func easyjsonD2b7633eEncodeGithubComNikolayStrekalovVigilantOctoWaddleGitInternalModels14(out *jwriter.Writer, in AlertStatuses) {
+	if in == nil && (out.Flags&jwriter.NilSliceAsEmpty) == 0 {
+		out.RawString("null")
+	} else {
+		out.RawByte('[')
+		for v35, v36 := range in {
+			if v35 > 0 {
+				out.RawByte(',')
+			}
+			(v36).MarshalEasyJSON(out)
+		}
+		out.RawByte(']')
+	}
+}
+
+// MarshalJSON supports json.Marshaler interface
+func (v AlertStatuses) MarshalJSON() ([]byte, error) {
+	w := jwriter.Writer{}
+	easyjsonD2b7633eEncodeGithubComNikolayStrekalovVigilantOctoWaddleGitInternalModels14(&w, v)
+	return w.Buffer.BuildBytes(), w.Error
+}
+
+// MarshalEasyJSON supports easyjson.Marshaler interface
+func (v AlertStatuses) MarshalEasyJSON(w *jwriter.Writer) {
+	easyjsonD2b7633eEncodeGithubComNikolayStrekalovVigilantOctoWaddleGitInternalModels14(w, v)
+}
+
+// UnmarshalJSON supports json.Unmarshaler interface
+func (v *AlertStatuses) UnmarshalJSON(data []byte) error {
+	r := jlexer.Lexer{Data: data}
+	easyjsonD2b7633eDecodeGithubComNikolayStrekalovVigilantOctoWaddleGitInternalModels14(&r, v)
+	return r.Error()
+}
+
+// UnmarshalEasyJSON supports easyjson.Unmarshaler interface
+func (v *AlertStatuses) UnmarshalEasyJSON(l *jlexer.Lexer) {
+	easyjsonD2b7633eDecodeGithubComNikolayStrekalovVigilantOctoWaddleGitInternalModels14(l, v)
+}
+func easyjsonD2b7633eDecodeGithubComNikolayStrekalovVigilantOctoWaddleGitInternalModels15(in *jlexer.Lexer, out *AlertStatus) {
+	isTopLevel := in.IsStart()
+	if in.IsNull() {
+		if isTopLevel {
+			in.Consumed()
+		}
+		in.Skip()
+		return
+	}
+	in.Delim('{')
+	for !in.IsDelim('}') {
+		key := in.UnsafeFieldName(false)
+		in.WantColon()
+		if in.IsNull() {
+			in.Skip()
+			in.WantComma()
+			continue
+		}
+		switch key {
+		case "activeSince":
+			if in.IsNull() {
+				in.Skip()
+				out.ActiveSince = nil
+			} else {
+				if out.ActiveSince == nil {
+					out.ActiveSince = new(time.Time)
+				}
+				if data := in.Raw(); in.Ok() {
+					in.AddError((*out.ActiveSince).UnmarshalJSON(data))
+				}
+			}
+		case "value":
+			if in.IsNull() {
+				in.Skip()
+				out.Value = nil
+			} else {
+				if out.Value == nil {
+					out.Value = new(float64)
+				}
+				*out.Value = float64(in.Float64())
+			}
+		case "rule":
+			out.Rule = string(in.String())
+		case "state":
+			out.State = string(in.String())
+		case "changedAt":
+			if data := in.Raw(); in.Ok() {
+				in.AddError((out.ChangedAt).UnmarshalJSON(data))
+			}
+		case "ruleId":
+			out.RuleID = int64(in.Int64())
+		default:
+			in.SkipRecursive()
+		}
+		in.WantComma()
+	}
+	in.Delim('}')
+	if isTopLevel {
+		in.Consumed()
+	}
+}
+func easyjsonD2b7633eEncodeGithubComNikolayStrekalovVigilantOctoWaddleGitInternalModels15(out *jwriter.Writer, in AlertStatus) {
+	out.RawByte('{')
+	first := true
+	_ = first
+	if in.ActiveSince != nil {
+		const prefix string = ",\"activeSince\":"
+		first = false
+		out.RawString(prefix[1:])
+		out.Raw((*in.ActiveSince).MarshalJSON())
+	}
+	if in.Value != nil {
+		const prefix string = ",\"value\":"
+		if first {
+			first = false
+			out.RawString(prefix[1:])
+		} else {
+			out.RawString(prefix)
+		}
+		out.Float64(float64(*in.Value))
+	}
+	{
+		const prefix string = ",\"rule\":"
+		if first {
+			first = false
+			out.RawString(prefix[1:])
+		} else {
+			out.RawString(prefix)
+		}
+		out.String(string(in.Rule))
+	}
+	{
+		const prefix string = ",\"state\":"
+		out.RawString(prefix)
+		out.String(string(in.State))
+	}
+	{
+		const prefix string = ",\"changedAt\":"
+		out.RawString(prefix)
+		out.Raw((in.ChangedAt).MarshalJSON())
+	}
+	if in.RuleID != 0 {
+		const prefix string = ",\"ruleId\":"
+		out.RawString(prefix)
+		out.Int64(int64(in.RuleID))
+	}
+	out.RawByte('}')
+}
+
+// MarshalJSON supports json.Marshaler interface
+func (v AlertStatus) MarshalJSON() ([]byte, error) {
+	w := jwriter.Writer{}
+	easyjsonD2b7633eEncodeGithubComNikolayStrekalovVigilantOctoWaddleGitInternalModels15(&w, v)
+	return w.Buffer.BuildBytes(), w.Error
+}
+
+// MarshalEasyJSON supports easyjson.Marshaler interface
+func (v AlertStatus) MarshalEasyJSON(w *jwriter.Writer) {
+	easyjsonD2b7633eEncodeGithubComNikolayStrekalovVigilantOctoWaddleGitInternalModels15(w, v)
+}
+
+// UnmarshalJSON supports json.Unmarshaler interface
+func (v *AlertStatus) UnmarshalJSON(data []byte) error {
+	r := jlexer.Lexer{Data: data}
+	easyjsonD2b7633eDecodeGithubComNikolayStrekalovVigilantOctoWaddleGitInternalModels15(&r, v)
+	return r.Error()
+}
+
+// UnmarshalEasyJSON supports easyjson.Unmarshaler interface
+func (v *AlertStatus) UnmarshalEasyJSON(l *jlexer.Lexer) {
+	easyjsonD2b7633eDecodeGithubComNikolayStrekalovVigilantOctoWaddleGitInternalModels15(l, v)
+}
+func easyjsonD2b7633eDecodeGithubComNikolayStrekalovVigilantOctoWaddleGitInternalModels16(in *jlexer.Lexer, out *AlertRules) {
+	isTopLevel := in.IsStart()
+	if in.IsNull() {
+		in.Skip()
+		*out = nil
+	} else {
+		in.Delim('[')
+		if *out == nil {
+			if !in.IsDelim(']') {
+				*out = make(AlertRules, 0, 0)
+			} else {
+				*out = AlertRules{}
+			}
+		} else {
+			*out = (*out)[:0]
+		}
+		for !in.IsDelim(']') {
+			var v37 AlertRule
+			(v37).UnmarshalEasyJSON(in)
+			*out = append(*out, v37)
+			in.WantComma()
+		}
+		in.Delim(']')
+	}
+	if isTopLevel {
+		in.Consumed()
+	}
+}
+func easyjsonD2b7633eEncodeGithubComNikolayStrekalovVigilantOctoWaddleGitInternalModels16(out *jwriter.Writer, in AlertRules) {
+	if in == nil && (out.Flags&jwriter.NilSliceAsEmpty) == 0 {
+		out.RawString("null")
+	} else {
+		out.RawByte('[')
+		for v38, v39 := range in {
+			if v38 > 0 {
+				out.RawByte(',')
+			}
+			(v39).MarshalEasyJSON(out)
+		}
+		out.RawByte(']')
+	}
+}
+
+// MarshalJSON supports json.Marshaler interface
+func (v AlertRules) MarshalJSON() ([]byte, error) {
+	w := jwriter.Writer{}
+	easyjsonD2b7633eEncodeGithubComNikolayStrekalovVigilantOctoWaddleGitInternalModels16(&w, v)
+	return w.Buffer.BuildBytes(), w.Error
+}
+
+// MarshalEasyJSON supports easyjson.Marshaler interface
+func (v AlertRules) MarshalEasyJSON(w *jwriter.Writer) {
+	easyjsonD2b7633eEncodeGithubComNikolayStrekalovVigilantOctoWaddleGitInternalModels16(w, v)
+}
+
+// UnmarshalJSON supports json.Unmarshaler interface
+func (v *AlertRules) UnmarshalJSON(data []byte) error {
+	r := jlexer.Lexer{Data: data}
+	easyjsonD2b7633eDecodeGithubComNikolayStrekalovVigilantOctoWaddleGitInternalModels16(&r, v)
+	return r.Error()
+}
+
+// UnmarshalEasyJSON supports easyjson.Unmarshaler interface
+func (v *AlertRules) UnmarshalEasyJSON(l *jlexer.Lexer) {
+	easyjsonD2b7633eDecodeGithubComNikolayStrekalovVigilantOctoWaddleGitInternalModels16(l, v)
+}
+func easyjsonD2b7633eDecodeGithubComNikolayStrekalovVigilantOctoWaddleGitInternalModels17(in *jlexer.Lexer, out *AlertRule) {
+	isTopLevel := in.IsStart()
+	if in.IsNull() {
+		if isTopLevel {
+			in.Consumed()
+		}
+		in.Skip()
+		return
+	}
+	in.Delim('{')
+	for !in.IsDelim('}') {
+		key := in.UnsafeFieldName(false)
+		in.WantColon()
+		if in.IsNull() {
+			in.Skip()
+			in.WantComma()
+			continue
+		}
+		switch key {
+		case "type":
+			out.Kind = string(in.String())
+		case "name":
+			out.Name = string(in.String())
+		case "op":
+			out.Op = string(in.String())
+		case "for":
+			out.For = string(in.String())
+		case "threshold":
+			out.Threshold = float64(in.Float64())
+		case "id":
+			out.ID = int64(in.Int64())
+		case "rate":
+			out.Rate = bool(in.Bool())
+		default:
+			in.SkipRecursive()
+		}
+		in.WantComma()
+	}
+	in.Delim('}')
+	if isTopLevel {
+		in.Consumed()
+	}
+}
+func easyjsonD2b7633eEncodeGithubComNikolayStrekalovVigilantOctoWaddleGitInternalModels17(out *jwriter.Writer, in AlertRule) {
+	out.RawByte('{')
+	first := true
+	_ = first
+	{
+		const prefix string = ",\"type\":"
+		out.RawString(prefix[1:])
+		out.String(string(in.Kind))
+	}
+	{
+		const prefix string = ",\"name\":"
+		out.RawString(prefix)
+		out.String(string(in.Name))
+	}
+	{
+		const prefix string = ",\"op\":"
+		out.RawString(prefix)
+		out.String(string(in.Op))
+	}
+	if in.For != "" {
+		const prefix string = ",\"for\":"
+		out.RawString(prefix)
+		out.String(string(in.For))
+	}
+	{
+		const prefix string = ",\"threshold\":"
+		out.RawString(prefix)
+		out.Float64(float64(in.Threshold))
+	}
+	{
+		const prefix string = ",\"id\":"
+		out.RawString(prefix)
+		out.Int64(int64(in.ID))
+	}
+	{
+		const prefix string = ",\"rate\":"
+		out.RawString(prefix)
+		out.Bool(bool(in.Rate))
+	}
+	out.RawByte('}')
+}
+
+// MarshalJSON supports json.Marshaler interface
+func (v AlertRule) MarshalJSON() ([]byte, error) {
+	w := jwriter.Writer{}
+	easyjsonD2b7633eEncodeGithubComNikolayStrekalovVigilantOctoWaddleGitInternalModels17(&w, v)
+	return w.Buffer.BuildBytes(), w.Error
+}
+
+// MarshalEasyJSON supports easyjson.Marshaler interface
+func (v AlertRule) MarshalEasyJSON(w *jwriter.Writer) {
+	easyjsonD2b7633eEncodeGithubComNikolayStrekalovVigilantOctoWaddleGitInternalModels17(w, v)
+}
+
+// UnmarshalJSON supports json.Unmarshaler interface
+func (v *AlertRule) UnmarshalJSON(data []byte) error {
+	r := jlexer.Lexer{Data: data}
+	easyjsonD2b7633eDecodeGithubComNikolayStrekalovVigilantOctoWaddleGitInternalModels17(&r, v)
+	return r.Error()
+}
+
+// UnmarshalEasyJSON supports easyjson.Unmarshaler interface
+func (v *AlertRule) UnmarshalEasyJSON(l *jlexer.Lexer) {
+	easyjsonD2b7633eDecodeGithubComNikolayStrekalovVigilantOctoWaddleGitInternalModels17(l, v)
+}
+func easyjsonD2b7633eDecodeGithubComNikolayStrekalovVigilantOctoWaddleGitInternalModels18(in *jlexer.Lexer, out *AlertEvent) {
+	isTopLevel := in.IsStart()
+	if in.IsNull() {
+		if isTopLevel {
+			in.Consumed()
+		}
+		in.Skip()
+		return
+	}
+	in.Delim('{')
+	for !in.IsDelim('}') {
+		key := in.UnsafeFieldName(false)
+		in.WantColon()
+		if in.IsNull() {
+			in.Skip()
+			in.WantComma()
+			continue
+		}
+		switch key {
+		case "value":
+			if in.IsNull() {
+				in.Skip()
+				out.Value = nil
+			} else {
+				if out.Value == nil {
+					out.Value = new(float64)
+				}
+				*out.Value = float64(in.Float64())
+			}
+		case "rule":
+			out.Rule = string(in.String())
+		case "type":
+			out.Kind = string(in.String())
+		case "name":
+			out.Name = string(in.String())
+		case "state":
+			out.State = string(in.String())
+		case "threshold":
+			out.Threshold = float64(in.Float64())
+		case "changedAt":
+			if data := in.Raw(); in.Ok() {
+				in.AddError((out.ChangedAt).UnmarshalJSON(data))
+			}
+		case "ruleId":
+			out.RuleID = int64(in.Int64())
+		default:
+			in.SkipRecursive()
+		}
+		in.WantComma()
+	}
+	in.Delim('}')
+	if isTopLevel {
+		in.Consumed()
+	}
+}
+func easyjsonD2b7633eEncodeGithubComNikolayStrekalovVigilantOctoWaddleGitInternalModels18(out *jwriter.Writer, in AlertEvent) {
+	out.RawByte('{')
+	first := true
+	_ = first
+	if in.Value != nil {
+		const prefix string = ",\"value\":"
+		first = false
+		out.RawString(prefix[1:])
+		out.Float64(float64(*in.Value))
+	}
+	{
+		const prefix string = ",\"rule\":"
+		if first {
+			first = false
+			out.RawString(prefix[1:])
+		} else {
+			out.RawString(prefix)
+		}
+		out.String(string(in.Rule))
+	}
+	{
+		const prefix string = ",\"type\":"
+		out.RawString(prefix)
+		out.String(string(in.Kind))
+	}
+	{
+		const prefix string = ",\"name\":"
+		out.RawString(prefix)
+		out.String(string(in.Name))
+	}
+	{
+		const prefix string = ",\"state\":"
+		out.RawString(prefix)
+		out.String(string(in.State))
+	}
+	{
+		const prefix string = ",\"threshold\":"
+		out.RawString(prefix)
+		out.Float64(float64(in.Threshold))
+	}
+	{
+		const prefix string = ",\"changedAt\":"
+		out.RawString(prefix)
+		out.Raw((in.ChangedAt).MarshalJSON())
+	}
+	if in.RuleID != 0 {
+		const prefix string = ",\"ruleId\":"
+		out.RawString(prefix)
+		out.Int64(int64(in.RuleID))
+	}
+	out.RawByte('}')
+}
+
+// MarshalJSON supports json.Marshaler interface
+func (v AlertEvent) MarshalJSON() ([]byte, error) {
+	w := jwriter.Writer{}
+	easyjsonD2b7633eEncodeGithubComNikolayStrekalovVigilantOctoWaddleGitInternalModels18(&w, v)
+	return w.Buffer.BuildBytes(), w.Error
+}
+
+// MarshalEasyJSON supports easyjson.Marshaler interface
+func (v AlertEvent) MarshalEasyJSON(w *jwriter.Writer) {
+	easyjsonD2b7633eEncodeGithubComNikolayStrekalovVigilantOctoWaddleGitInternalModels18(w, v)
+}
+
+// UnmarshalJSON supports json.Unmarshaler interface
+func (v *AlertEvent) UnmarshalJSON(data []byte) error {
+	r := jlexer.Lexer{Data: data}
+	easyjsonD2b7633eDecodeGithubComNikolayStrekalovVigilantOctoWaddleGitInternalModels18(&r, v)
+	return r.Error()
+}
+
+// UnmarshalEasyJSON supports easyjson.Unmarshaler interface
+func (v *AlertEvent) UnmarshalEasyJSON(l *jlexer.Lexer) {
+	easyjsonD2b7633eDecodeGithubComNikolayStrekalovVigilantOctoWaddleGitInternalModels18(l, v)
+}
+func easyjsonD2b7633eDecodeGithubComNikolayStrekalovVigilantOctoWaddleGitInternalModels19(in *jlexer.Lexer, out *Agents) {
+	isTopLevel := in.IsStart()
+	if in.IsNull() {
+		in.Skip()
+		*out = nil
+	} else {
+		in.Delim('[')
+		if *out == nil {
+			if !in.IsDelim(']') {
+				*out = make(Agents, 0, 0)
+			} else {
+				*out = Agents{}
+			}
+		} else {
+			*out = (*out)[:0]
+		}
+		for !in.IsDelim(']') {
+			var v40 Agent
+			(v40).UnmarshalEasyJSON(in)
+			*out = append(*out, v40)
+			in.WantComma()
+		}
+		in.Delim(']')
+	}
+	if isTopLevel {
+		in.Consumed()
+	}
+}
+func easyjsonD2b7633eEncodeGithubComNikolayStrekalovVigilantOctoWaddleGitInternalModels19(out *jwriter.Writer, in Agents) {
+	if in == nil && (out.Flags&jwriter.NilSliceAsEmpty) == 0 {
+		out.RawString("null")
+	} else {
+		out.RawByte('[')
+		for v41, v42 := range in {
+			if v41 > 0 {
+				out.RawByte(',')
+			}
+			(v42).MarshalEasyJSON(out)
+		}
+		out.RawByte(']')
+	}
+}
+
+// MarshalJSON supports json.Marshaler interface
+func (v Agents) MarshalJSON() ([]byte, error) {
+	w := jwriter.Writer{}
+	easyjsonD2b7633eEncodeGithubComNikolayStrekalovVigilantOctoWaddleGitInternalModels19(&w, v)
+	return w.Buffer.BuildBytes(), w.Error
+}
+
+// MarshalEasyJSON supports easyjson.Marshaler interface
+func (v Agents) MarshalEasyJSON(w *jwriter.Writer) {
+	easyjsonD2b7633eEncodeGithubComNikolayStrekalovVigilantOctoWaddleGitInternalModels19(w, v)
+}
+
+// UnmarshalJSON supports json.Unmarshaler interface
+func (v *Agents) UnmarshalJSON(data []byte) error {
+	r := jlexer.Lexer{Data: data}
+	easyjsonD2b7633eDecodeGithubComNikolayStrekalovVigilantOctoWaddleGitInternalModels19(&r, v)
+	return r.Error()
+}
+
+// UnmarshalEasyJSON supports easyjson.Unmarshaler interface
+func (v *Agents) UnmarshalEasyJSON(l *jlexer.Lexer) {
+	easyjsonD2b7633eDecodeGithubComNikolayStrekalovVigilantOctoWaddleGitInternalModels19(l, v)
+}
+func easyjsonD2b7633eDecodeGithubComNikolayStrekalovVigilantOctoWaddleGitInternalModels20(in *jlexer.Lexer, out *Agent) {
+	isTopLevel := in.IsStart()
+	if in.IsNull() {
+		if isTopLevel {
+			in.Consumed()
+		}
+		in.Skip()
+		return
+	}
+	in.Delim('{')
+	for !in.IsDelim('}') {
+		key := in.UnsafeFieldName(false)
+		in.WantColon()
+		if in.IsNull() {
+			in.Skip()
+			in.WantComma()
+			continue
+		}
+		switch key {
+		case "firstSeen":
+			if data := in.Raw(); in.Ok() {
+				in.AddError((out.FirstSeen).UnmarshalJSON(data))
+			}
+		case "lastSeen":
+			if data := in.Raw(); in.Ok() {
+				in.AddError((out.LastSeen).UnmarshalJSON(data))
+			}
+		case "id":
+			out.ID = string(in.String())
+		case "hostname":
+			out.Hostname = string(in.String())
+		case "platform":
+			out.Platform = string(in.String())
+		case "version":
+			out.Version = string(in.String())
+		case "address":
+			out.Address = string(in.String())
+		case "metrics":
+			out.Metrics = int(in.Int())
+		default:
+			in.SkipRecursive()
+		}
+		in.WantComma()
+	}
+	in.Delim('}')
+	if isTopLevel {
+		in.Consumed()
+	}
+}
+func easyjsonD2b7633eEncodeGithubComNikolayStrekalovVigilantOctoWaddleGitInternalModels20(out *jwriter.Writer, in Agent) {
+	out.RawByte('{')
+	first := true
+	_ = first
+	{
+		const prefix string = ",\"firstSeen\":"
+		out.RawString(prefix[1:])
+		out.Raw((in.FirstSeen).MarshalJSON())
+	}
+	{
+		const prefix string = ",\"lastSeen\":"
+		out.RawString(prefix)
+		out.Raw((in.LastSeen).MarshalJSON())
+	}
+	{
+		const prefix string = ",\"id\":"
+		out.RawString(prefix)
+		out.String(string(in.ID))
+	}
+	{
+		const prefix string = ",\"hostname\":"
+		out.RawString(prefix)
+		out.String(string(in.Hostname))
+	}
+	{
+		const prefix string = ",\"platform\":"
+		out.RawString(prefix)
+		out.String(string(in.Platform))
+	}
+	{
+		const prefix string = ",\"version\":"
+		out.RawString(prefix)
+		out.String(string(in.Version))
+	}
+	{
+		const prefix string = ",\"address\":"
+		out.RawString(prefix)
+		out.String(string(in.Address))
+	}
+	{
+		const prefix string = ",\"metrics\":"
+		out.RawString(prefix)
+		out.Int(int(in.Metrics))
+	}
+	out.RawByte('}')
+}
+
+// MarshalJSON supports json.Marshaler interface
+func (v Agent) MarshalJSON() ([]byte, error) {
+	w := jwriter.Writer{}
+	easyjsonD2b7633eEncodeGithubComNikolayStrekalovVigilantOctoWaddleGitInternalModels20(&w, v)
+	return w.Buffer.BuildBytes(), w.Error
+}
+
+// MarshalEasyJSON supports easyjson.Marshaler interface
+func (v Agent) MarshalEasyJSON(w *jwriter.Writer) {
+	easyjsonD2b7633eEncodeGithubComNikolayStrekalovVigilantOctoWaddleGitInternalModels20(w, v)
+}
+
+// UnmarshalJSON supports json.Unmarshaler interface
+func (v *Agent) UnmarshalJSON(data []byte) error {
+	r := jlexer.Lexer{Data: data}
+	easyjsonD2b7633eDecodeGithubComNikolayStrekalovVigilantOctoWaddleGitInternalModels20(&r, v)
+	return r.Error()
+}
+
+// UnmarshalEasyJSON supports easyjson.Unmarshaler interface
+func (v *Agent) UnmarshalEasyJSON(l *jlexer.Lexer) {
+	easyjsonD2b7633eDecodeGithubComNikolayStrekalovVigilantOctoWaddleGitInternalModels20(l, v)
+}
+func easyjsonD2b7633eDecodeGithubComNikolayStrekalovVigilantOctoWaddleGitInternalModels21(in *jlexer.Lexer, out *Absences) {
+	isTopLevel := in.IsStart()
+	if in.IsNull() {
+		in.Skip()
+		*out = nil
+	} else {
+		in.Delim('[')
+		if *out == nil {
+			if !in.IsDelim(']') {
+				*out = make(Absences, 0, 0)
+			} else {
+				*out = Absences{}
+			}
+		} else {
+			*out = (*out)[:0]
+		}
+		for !in.IsDelim(']') {
+			var v43 Absence
+			(v43).UnmarshalEasyJSON(in)
+			*out = append(*out, v43)
+			in.WantComma()
+		}
+		in.Delim(']')
+	}
+	if isTopLevel {
+		in.Consumed()
+	}
+}
+func easyjsonD2b7633eEncodeGithubComNikolayStrekalovVigilantOctoWaddleGitInternalModels21(out *jwriter.Writer, in Absences) {
+	if in == nil && (out.Flags&jwriter.NilSliceAsEmpty) == 0 {
+		out.RawString("null")
+	} else {
+		out.RawByte('[')
+		for v44, v45 := range in {
+			if v44 > 0 {
+				out.RawByte(',')
+			}
+			(v45).MarshalEasyJSON(out)
+		}
+		out.RawByte(']')
+	}
+}
+
+// MarshalJSON supports json.Marshaler interface
+func (v Absences) MarshalJSON() ([]byte, error) {
+	w := jwriter.Writer{}
+	easyjsonD2b7633eEncodeGithubComNikolayStrekalovVigilantOctoWaddleGitInternalModels21(&w, v)
+	return w.Buffer.BuildBytes(), w.Error
+}
+
+// MarshalEasyJSON supports easyjson.Marshaler interface
+func (v Absences) MarshalEasyJSON(w *jwriter.Writer) {
+	easyjsonD2b7633eEncodeGithubComNikolayStrekalovVigilantOctoWaddleGitInternalModels21(w, v)
+}
+
+// UnmarshalJSON supports json.Unmarshaler interface
+func (v *Absences) UnmarshalJSON(data []byte) error {
+	r := jlexer.Lexer{Data: data}
+	easyjsonD2b7633eDecodeGithubComNikolayStrekalovVigilantOctoWaddleGitInternalModels21(&r, v)
+	return r.Error()
+}
+
+// UnmarshalEasyJSON supports easyjson.Unmarshaler interface
+func (v *Absences) UnmarshalEasyJSON(l *jlexer.Lexer) {
+	easyjsonD2b7633eDecodeGithubComNikolayStrekalovVigilantOctoWaddleGitInternalModels21(l, v)
+}
+func easyjsonD2b7633eDecodeGithubComNikolayStrekalovVigilantOctoWaddleGitInternalModels22(in *jlexer.Lexer, out *Absence) {
+	isTopLevel := in.IsStart()
+	if in.IsNull() {
+		if isTopLevel {
+			in.Consumed()
+		}
+		in.Skip()
+		return
+	}
+	in.Delim('{')
+	for !in.IsDelim('}') {
+		key := in.UnsafeFieldName(false)
+		in.WantColon()
+		if in.IsNull() {
+			in.Skip()
+			in.WantComma()
+			continue
+		}
+		switch key {
+		case "lastSeen":
+			if data := in.Raw(); in.Ok() {
+				in.AddError((out.LastSeen).UnmarshalJSON(data))
+			}
+		case "changedAt":
+			if data := in.Raw(); in.Ok() {
+				in.AddError((out.ChangedAt).UnmarshalJSON(data))
+			}
+		case "type":
+			out.Kind = string(in.String())
+		case "name":
+			out.Name = string(in.String())
+		case "state":
+			out.State = string(in.String())
+		default:
+			in.SkipRecursive()
+		}
+		in.WantComma()
+	}
+	in.Delim('}')
+	if isTopLevel {
+		in.Consumed()
+	}
+}
+func easyjsonD2b7633eEncodeGithubComNikolayStrekalovVigilantOctoWaddleGitInternalModels22(out *jwriter.Writer, in Absence) {
+	out.RawByte('{')
+	first := true
+	_ = first
+	{
+		const prefix string = ",\"lastSeen\":"
+		out.RawString(prefix[1:])
+		out.Raw((in.LastSeen).MarshalJSON())
+	}
+	{
+		const prefix string = ",\"changedAt\":"
+		out.RawString(prefix)
+		out.Raw((in.ChangedAt).MarshalJSON())
+	}
+	{
+		const prefix string = ",\"type\":"
+		out.RawString(prefix)
+		out.String(string(in.Kind))
+	}
+	{
+		const prefix string = ",\"name\":"
+		out.RawString(prefix)
+		out.String(string(in.Name))
+	}
+	{
+		const prefix string = ",\"state\":"
+		out.RawString(prefix)
+		out.String(string(in.State))
+	}
+	out.RawByte('}')
+}
+
+// MarshalJSON supports json.Marshaler interface
+func (v Absence) MarshalJSON() ([]byte, error) {
+	w := jwriter.Writer{}
+	easyjsonD2b7633eEncodeGithubComNikolayStrekalovVigilantOctoWaddleGitInternalModels22(&w, v)
+	return w.Buffer.BuildBytes(), w.Error
+}
+
+// MarshalEasyJSON supports easyjson.Marshaler interface
+func (v Absence) MarshalEasyJSON(w *jwriter.Writer) {
+	easyjsonD2b7633eEncodeGithubComNikolayStrekalovVigilantOctoWaddleGitInternalModels22(w, v)
+}
+
+// UnmarshalJSON supports json.Unmarshaler interface
+func (v *Absence) UnmarshalJSON(data []byte) error {
+	r := jlexer.Lexer{Data: data}
+	easyjsonD2b7633eDecodeGithubComNikolayStrekalovVigilantOctoWaddleGitInternalModels22(&r, v)
+	return r.Error()
+}
+
+// UnmarshalEasyJSON supports easyjson.Unmarshaler interface
+func (v *Absence) UnmarshalEasyJSON(l *jlexer.Lexer) {
+	easyjsonD2b7633eDecodeGithubComNikolayStrekalovVigilantOctoWaddleGitInternalModels22(l, v)
 }
@@ -0,0 +1,194 @@
+// Package otlp переводит метрики OpenTelemetry (OTLP) в обновления метрик сервера.
+package otlp
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/NikolayStrekalov/vigilant-octo-waddle.git/internal/cumulative"
+	"github.com/NikolayStrekalov/vigilant-octo-waddle.git/internal/models"
+	"github.com/NikolayStrekalov/vigilant-octo-waddle.git/internal/series"
+	colmetricspb "go.opentelemetry.io/proto/otlp/collector/metrics/v1"
+	commonpb "go.opentelemetry.io/proto/otlp/common/v1"
+	metricspb "go.opentelemetry.io/proto/otlp/metrics/v1"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+)
+
+const (
+	ProtobufType = "application/x-protobuf"
+	JSONType     = "application/json"
+)
+
+var ErrUnsupportedType = errors.New("content type must be application/x-protobuf or application/json")
+
+// Unmarshal разбирает ExportMetricsServiceRequest в кодировке, заданной типом содержимого.
+func Unmarshal(data []byte, contentType string) (*colmetricspb.ExportMetricsServiceRequest, error) {
+	req := &colmetricspb.ExportMetricsServiceRequest{}
+	var err error
+	switch MediaType(contentType) {
+	case ProtobufType:
+		err = proto.Unmarshal(data, req)
+	case JSONType:
+		err = protojson.UnmarshalOptions{DiscardUnknown: true}.Unmarshal(data, req)
+	default:
+		return nil, ErrUnsupportedType
+	}
+	if err != nil {
+		return nil, fmt.Errorf("otlp decode error: %w", err)
+	}
+	return req, nil
+}
+
+// Marshal кодирует ответ в той же кодировке, что и запрос.
+func Marshal(resp *colmetricspb.ExportMetricsServiceResponse, contentType string) ([]byte, error) {
+	var (
+		data []byte
+		err  error
+	)
+	if MediaType(contentType) == JSONType {
+		data, err = protojson.Marshal(resp)
+	} else {
+		data, err = proto.Marshal(resp)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("otlp encode error: %w", err)
+	}
+	return data, nil
+}
+
+// MediaType отбрасывает параметры типа содержимого.
+func MediaType(contentType string) string {
+	t, _, _ := strings.Cut(contentType, ";")
+	return strings.TrimSpace(t)
+}
+
+// ToMetrics переводит точки Gauge и Sum в обновления метрик. Атрибуты ресурса и точки становятся
+// метками серии, имена меток приводятся к [a-zA-Z0-9_], например service.name → service_name.
+// Монотонные суммы становятся счётчиками: накопительные передаются значением Total, а рост считает
+// сервер, дельты суммируются. Если время начала накопительной серии сдвинулось вперёд, перед значением
+// передаётся Total=0, чтобы сервер учёл сброс, даже если новое значение больше прежнего.
+// Немонотонные суммы становятся gauge. Остальные типы метрик пропускаются.
+func ToMetrics(req *colmetricspb.ExportMetricsServiceRequest, tracker *cumulative.Tracker,
+	now time.Time) models.MetricsSlice {
+	c := collector{index: make(map[[2]string]int)}
+	for _, rm := range req.GetResourceMetrics() {
+		resource := rm.GetResource().GetAttributes()
+		for _, sm := range rm.GetScopeMetrics() {
+			for _, m := range sm.GetMetrics() {
+				switch {
+				case m.GetGauge() != nil:
+					for _, p := range m.GetGauge().GetDataPoints() {
+						c.gauge(m.GetName(), labels(resource, p.GetAttributes()), pointValue(p))
+					}
+				case m.GetSum() != nil && m.GetSum().GetIsMonotonic():
+					temporality := m.GetSum().GetAggregationTemporality()
+					for _, p := range m.GetSum().GetDataPoints() {
+						l := labels(resource, p.GetAttributes())
+						key := series.Key(m.GetName(), l)
+						if temporality == metricspb.AggregationTemporality_AGGREGATION_TEMPORALITY_DELTA {
+							c.counter(m.GetName(), l, tracker.Add(key, pointValue(p), now))
+							continue
+						}
+						if tracker.Restarted(key, p.GetStartTimeUnixNano(), now) {
+							c.total(m.GetName(), l, 0)
+						}
+						c.total(m.GetName(), l, pointValue(p))
+					}
+				case m.GetSum() != nil:
+					for _, p := range m.GetSum().GetDataPoints() {
+						c.gauge(m.GetName(), labels(resource, p.GetAttributes()), pointValue(p))
+					}
+				}
+			}
+		}
+	}
+	return c.metrics
+}
+
+type collector struct {
+	index   map[[2]string]int
+	metrics models.MetricsSlice
+}
+
+func (c *collector) get(kind, name string, labels map[string]string) *models.Metrics {
+	key := series.Key(name, labels)
+	i, ok := c.index[[2]string{kind, key}]
+	if !ok {
+		i = len(c.metrics)
+		c.index[[2]string{kind, key}] = i
+		c.metrics = append(c.metrics, models.Metrics{ID: name, MType: kind, Labels: labels})
+	}
+	return &c.metrics[i]
+}
+
+func (c *collector) gauge(name string, labels map[string]string, value float64) {
+	if name == "" || math.IsNaN(value) {
+		return
+	}
+	c.get("gauge", name, labels).Value = &value
+}
+
+func (c *collector) counter(name string, labels map[string]string, delta int64) {
+	if name == "" {
+		return
+	}
+	m := c.get("counter", name, labels)
+	if m.Delta == nil {
+		m.Delta = new(int64)
+	}
+	*m.Delta += delta
+}
+
+// total добавляет накопительное значение отдельной записью, чтобы сервер видел значения по порядку.
+func (c *collector) total(name string, labels map[string]string, value float64) {
+	if name == "" || math.IsNaN(value) || math.IsInf(value, 0) || value < 0 {
+		return
+	}
+	total := int64(math.Floor(value))
+	c.metrics = append(c.metrics, models.Metrics{ID: name, MType: "counter", Labels: labels, Total: &total})
+}
+
+func pointValue(p *metricspb.NumberDataPoint) float64 {
+	if v, ok := p.GetValue().(*metricspb.NumberDataPoint_AsInt); ok {
+		return float64(v.AsInt)
+	}
+	return p.GetAsDouble()
+}
+
+// labels собирает метки из атрибутов ресурса и точки, атрибуты точки важнее.
+// Атрибуты-массивы и вложенные списки пропускаются.
+func labels(resource, point []*commonpb.KeyValue) map[string]string {
+	var ret map[string]string
+	for _, attrs := range [][]*commonpb.KeyValue{resource, point} {
+		for _, kv := range attrs {
+			value, ok := attributeValue(kv.GetValue())
+			if !ok {
+				continue
+			}
+			if ret == nil {
+				ret = make(map[string]string, len(resource)+len(point))
+			}
+			ret[series.Sanitize(kv.GetKey())] = value
+		}
+	}
+	return ret
+}
+
+func attributeValue(v *commonpb.AnyValue) (string, bool) {
+	switch v.GetValue().(type) {
+	case *commonpb.AnyValue_StringValue:
+		return v.GetStringValue(), true
+	case *commonpb.AnyValue_BoolValue:
+		return strconv.FormatBool(v.GetBoolValue()), true
+	case *commonpb.AnyValue_IntValue:
+		return strconv.FormatInt(v.GetIntValue(), 10), true
+	case *commonpb.AnyValue_DoubleValue:
+		return strconv.FormatFloat(v.GetDoubleValue(), 'g', -1, 64), true
+	}
+	return "", false
+}
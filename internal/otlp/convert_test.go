@@ -0,0 +1,121 @@
+package otlp
+
+import (
+	"testing"
+	"time"
+
+	"github.com/NikolayStrekalov/vigilant-octo-waddle.git/internal/cumulative"
+	"github.com/NikolayStrekalov/vigilant-octo-waddle.git/internal/models"
+	"github.com/stretchr/testify/assert"
+	colmetricspb "go.opentelemetry.io/proto/otlp/collector/metrics/v1"
+	commonpb "go.opentelemetry.io/proto/otlp/common/v1"
+	metricspb "go.opentelemetry.io/proto/otlp/metrics/v1"
+	resourcepb "go.opentelemetry.io/proto/otlp/resource/v1"
+	"google.golang.org/protobuf/proto"
+)
+
+func intPoint(v int64, attrs ...string) *metricspb.NumberDataPoint {
+	p := &metricspb.NumberDataPoint{Value: &metricspb.NumberDataPoint_AsInt{AsInt: v}}
+	for i := 0; i+1 < len(attrs); i += 2 {
+		p.Attributes = append(p.Attributes, &commonpb.KeyValue{
+			Key:   attrs[i],
+			Value: &commonpb.AnyValue{Value: &commonpb.AnyValue_StringValue{StringValue: attrs[i+1]}},
+		})
+	}
+	return p
+}
+
+func doublePoint(v float64) *metricspb.NumberDataPoint {
+	return &metricspb.NumberDataPoint{Value: &metricspb.NumberDataPoint_AsDouble{AsDouble: v}}
+}
+
+func sum(name string, temporality metricspb.AggregationTemporality, monotonic bool,
+	points ...*metricspb.NumberDataPoint) *metricspb.Metric {
+	return &metricspb.Metric{Name: name, Data: &metricspb.Metric_Sum{Sum: &metricspb.Sum{
+		DataPoints:             points,
+		AggregationTemporality: temporality,
+		IsMonotonic:            monotonic,
+	}}}
+}
+
+func export(metrics ...*metricspb.Metric) *colmetricspb.ExportMetricsServiceRequest {
+	return &colmetricspb.ExportMetricsServiceRequest{ResourceMetrics: []*metricspb.ResourceMetrics{{
+		Resource: &resourcepb.Resource{Attributes: []*commonpb.KeyValue{{
+			Key:   "service.name",
+			Value: &commonpb.AnyValue{Value: &commonpb.AnyValue_StringValue{StringValue: "api"}},
+		}}},
+		ScopeMetrics: []*metricspb.ScopeMetrics{{Metrics: metrics}},
+	}}}
+}
+
+func TestToMetrics(t *testing.T) {
+	const (
+		cumulativeSum = metricspb.AggregationTemporality_AGGREGATION_TEMPORALITY_CUMULATIVE
+		deltaSum      = metricspb.AggregationTemporality_AGGREGATION_TEMPORALITY_DELTA
+	)
+	tracker := cumulative.NewTracker(time.Hour)
+	now := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+	started := func(p *metricspb.NumberDataPoint, start uint64) *metricspb.NumberDataPoint {
+		p.StartTimeUnixNano = start
+		return p
+	}
+	int64p := func(v int64) *int64 { return &v }
+	float64p := func(v float64) *float64 { return &v }
+
+	first := export(
+		&metricspb.Metric{Name: "heap", Data: &metricspb.Metric_Gauge{Gauge: &metricspb.Gauge{
+			DataPoints: []*metricspb.NumberDataPoint{doublePoint(1.5)},
+		}}},
+		sum("requests", cumulativeSum, true, started(intPoint(10, "route", "/a"), 1000), intPoint(4, "route", "/b")),
+		sum("bytes", deltaSum, true, doublePoint(0.75)),
+		sum("queue", cumulativeSum, false, intPoint(-3)),
+		&metricspb.Metric{Name: "latency", Data: &metricspb.Metric_Histogram{Histogram: &metricspb.Histogram{}}},
+	)
+	api := map[string]string{"service_name": "api"}
+	routeA := map[string]string{"service_name": "api", "route": "/a"}
+	routeB := map[string]string{"service_name": "api", "route": "/b"}
+	assert.Equal(t, models.MetricsSlice{
+		{ID: "heap", MType: "gauge", Value: float64p(1.5), Labels: api},
+		{ID: "requests", MType: "counter", Total: int64p(10), Labels: routeA},
+		{ID: "requests", MType: "counter", Total: int64p(4), Labels: routeB},
+		{ID: "bytes", MType: "counter", Delta: int64p(0), Labels: api},
+		{ID: "queue", MType: "gauge", Value: float64p(-3), Labels: api},
+	}, ToMetrics(first, tracker, now))
+
+	second := export(
+		sum("requests", cumulativeSum, true, started(intPoint(12, "route", "/a"), 1000), intPoint(1, "route", "/b")),
+		sum("bytes", deltaSum, true, doublePoint(0.75)),
+	)
+	assert.Equal(t, models.MetricsSlice{
+		{ID: "requests", MType: "counter", Total: int64p(12), Labels: routeA},
+		{ID: "requests", MType: "counter", Total: int64p(1), Labels: routeB},
+		{ID: "bytes", MType: "counter", Delta: int64p(1), Labels: api},
+	}, ToMetrics(second, tracker, now))
+
+	// источник перезапустился и успел насчитать больше прежнего: сброс виден только по времени начала
+	third := export(sum("requests", cumulativeSum, true, started(intPoint(20, "route", "/a"), 2000)))
+	assert.Equal(t, models.MetricsSlice{
+		{ID: "requests", MType: "counter", Total: int64p(0), Labels: routeA},
+		{ID: "requests", MType: "counter", Total: int64p(20), Labels: routeA},
+	}, ToMetrics(third, tracker, now))
+}
+
+func TestUnmarshal(t *testing.T) {
+	req := export(sum("requests", metricspb.AggregationTemporality_AGGREGATION_TEMPORALITY_DELTA, true, intPoint(1)))
+	data, err := proto.Marshal(req)
+	assert.Nil(t, err)
+	got, err := Unmarshal(data, "application/x-protobuf")
+	assert.Nil(t, err)
+	assert.True(t, proto.Equal(req, got))
+
+	got, err = Unmarshal([]byte(`{"resourceMetrics":[{"scopeMetrics":[{"metrics":[
+		{"name":"requests","sum":{"aggregationTemporality":1,"isMonotonic":true,"dataPoints":[{"asInt":"1"}]}}
+	]}]}]}`), "application/json; charset=utf-8")
+	assert.Nil(t, err)
+	assert.Equal(t, "requests", got.GetResourceMetrics()[0].GetScopeMetrics()[0].GetMetrics()[0].GetName())
+
+	_, err = Unmarshal(data, "text/plain")
+	assert.ErrorIs(t, err, ErrUnsupportedType)
+	_, err = Unmarshal([]byte("{"), JSONType)
+	assert.Error(t, err)
+}
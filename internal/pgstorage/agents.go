@@ -0,0 +1,77 @@
+package pgstorage
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/NikolayStrekalov/vigilant-octo-waddle.git/internal/models"
+	"github.com/avast/retry-go/v4"
+)
+
+func (db *DB) GetAgents(ctx context.Context) ([]models.Agent, error) {
+	ret := []models.Agent{}
+	rows, err := db.pool.Query(
+		ctx,
+		`SELECT id, hostname, platform, version, address, metrics, first_seen, last_seen FROM agents ORDER BY id;`,
+	)
+	if err != nil {
+		return ret, fmt.Errorf("error fetching agents: %w", err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var a models.Agent
+		err := rows.Scan(&a.ID, &a.Hostname, &a.Platform, &a.Version, &a.Address, &a.Metrics, &a.FirstSeen, &a.LastSeen)
+		if err != nil {
+			return ret, fmt.Errorf("error reading agents: %w", err)
+		}
+		ret = append(ret, a)
+	}
+	if err := rows.Err(); err != nil {
+		return ret, fmt.Errorf("error reading agents: %w", err)
+	}
+	return ret, nil
+}
+
+// SeenAgent записывает в реестр данные агента из последней пачки, время первого появления сохраняется.
+func (db *DB) SeenAgent(ctx context.Context, agent *models.Agent) error {
+	_, err := db.pool.Exec(
+		ctx,
+		`INSERT INTO agents(id, hostname, platform, version, address, metrics, first_seen, last_seen)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		ON CONFLICT (id) DO UPDATE SET hostname = EXCLUDED.hostname, platform = EXCLUDED.platform,
+			version = EXCLUDED.version, address = EXCLUDED.address, metrics = EXCLUDED.metrics,
+			last_seen = EXCLUDED.last_seen;`,
+		agent.ID, agent.Hostname, agent.Platform, agent.Version, agent.Address, agent.Metrics,
+		agent.FirstSeen, agent.LastSeen,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to update agent %s: %w", agent.ID, err)
+	}
+	return nil
+}
+
+func (p *PGStorage) GetAgents() ([]models.Agent, error) {
+	ret, err := retry.DoWithData(
+		func() ([]models.Agent, error) {
+			return p.db.GetAgents(context.TODO())
+		},
+		RetryOptions...,
+	)
+	if err != nil {
+		return ret, fmt.Errorf("failed to get agents: %w", err)
+	}
+	return ret, nil
+}
+
+func (p *PGStorage) SeenAgent(agent *models.Agent) error {
+	err := retry.Do(
+		func() error {
+			return p.db.SeenAgent(context.TODO(), agent)
+		},
+		RetryOptions...,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to update agent: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,147 @@
+package pgstorage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/NikolayStrekalov/vigilant-octo-waddle.git/internal/models"
+	"github.com/avast/retry-go/v4"
+)
+
+var errRuleNotFound = errors.New("alert rule not found")
+
+const sqlAlertRuleColumns = `id, kind, name, op, threshold, hold, rate`
+
+func (db *DB) GetAlertRules(ctx context.Context) ([]models.AlertRule, error) {
+	ret := []models.AlertRule{}
+	rows, err := db.pool.Query(ctx, "SELECT "+sqlAlertRuleColumns+" FROM alert_rules ORDER BY id;")
+	if err != nil {
+		return ret, fmt.Errorf("error fetching alert rules: %w", err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var r models.AlertRule
+		if err := rows.Scan(&r.ID, &r.Kind, &r.Name, &r.Op, &r.Threshold, &r.For, &r.Rate); err != nil {
+			return ret, fmt.Errorf("error reading alert rules: %w", err)
+		}
+		ret = append(ret, r)
+	}
+	if err := rows.Err(); err != nil {
+		return ret, fmt.Errorf("error reading alert rules: %w", err)
+	}
+	return ret, nil
+}
+
+func (db *DB) GetAlertRule(ctx context.Context, id int64) (models.AlertRule, error) {
+	var r models.AlertRule
+	row := db.pool.QueryRow(ctx, "SELECT "+sqlAlertRuleColumns+" FROM alert_rules WHERE id = $1;", id)
+	if err := row.Scan(&r.ID, &r.Kind, &r.Name, &r.Op, &r.Threshold, &r.For, &r.Rate); err != nil {
+		return r, fmt.Errorf("error getting alert rule %d: %w", id, err)
+	}
+	return r, nil
+}
+
+func (db *DB) AddAlertRule(ctx context.Context, rule *models.AlertRule) (models.AlertRule, error) {
+	added := *rule
+	row := db.pool.QueryRow(
+		ctx,
+		`INSERT INTO alert_rules(kind, name, op, threshold, hold, rate) VALUES ($1, $2, $3, $4, $5, $6) RETURNING id;`,
+		rule.Kind, rule.Name, rule.Op, rule.Threshold, rule.For, rule.Rate,
+	)
+	if err := row.Scan(&added.ID); err != nil {
+		return added, fmt.Errorf("failed to add alert rule: %w", err)
+	}
+	return added, nil
+}
+
+func (db *DB) UpdateAlertRule(ctx context.Context, rule *models.AlertRule) error {
+	tag, err := db.pool.Exec(
+		ctx,
+		`UPDATE alert_rules SET kind = $2, name = $3, op = $4, threshold = $5, hold = $6, rate = $7 WHERE id = $1;`,
+		rule.ID, rule.Kind, rule.Name, rule.Op, rule.Threshold, rule.For, rule.Rate,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to update alert rule %d: %w", rule.ID, err)
+	}
+	if tag.RowsAffected() == 0 {
+		return fmt.Errorf("failed to update alert rule %d: %w", rule.ID, errRuleNotFound)
+	}
+	return nil
+}
+
+func (db *DB) DeleteAlertRule(ctx context.Context, id int64) error {
+	tag, err := db.pool.Exec(ctx, `DELETE FROM alert_rules WHERE id = $1;`, id)
+	if err != nil {
+		return fmt.Errorf("failed to delete alert rule %d: %w", id, err)
+	}
+	if tag.RowsAffected() == 0 {
+		return fmt.Errorf("failed to delete alert rule %d: %w", id, errRuleNotFound)
+	}
+	return nil
+}
+
+func (p *PGStorage) GetAlertRules() ([]models.AlertRule, error) {
+	ret, err := retry.DoWithData(
+		func() ([]models.AlertRule, error) {
+			return p.db.GetAlertRules(context.TODO())
+		},
+		RetryOptions...,
+	)
+	if err != nil {
+		return ret, fmt.Errorf("failed to get alert rules: %w", err)
+	}
+	return ret, nil
+}
+
+func (p *PGStorage) GetAlertRule(id int64) (models.AlertRule, error) {
+	ret, err := retry.DoWithData(
+		func() (models.AlertRule, error) {
+			return p.db.GetAlertRule(context.TODO(), id)
+		},
+		RetryOptions...,
+	)
+	if err != nil {
+		return ret, fmt.Errorf("failed to get alert rule: %w", err)
+	}
+	return ret, nil
+}
+
+func (p *PGStorage) AddAlertRule(rule *models.AlertRule) (models.AlertRule, error) {
+	ret, err := retry.DoWithData(
+		func() (models.AlertRule, error) {
+			return p.db.AddAlertRule(context.TODO(), rule)
+		},
+		RetryOptions...,
+	)
+	if err != nil {
+		return ret, fmt.Errorf("failed to add alert rule: %w", err)
+	}
+	return ret, nil
+}
+
+func (p *PGStorage) UpdateAlertRule(rule *models.AlertRule) error {
+	err := retry.Do(
+		func() error {
+			return p.db.UpdateAlertRule(context.TODO(), rule)
+		},
+		RetryOptions...,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to update alert rule: %w", err)
+	}
+	return nil
+}
+
+func (p *PGStorage) DeleteAlertRule(id int64) error {
+	err := retry.Do(
+		func() error {
+			return p.db.DeleteAlertRule(context.TODO(), id)
+		},
+		RetryOptions...,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to delete alert rule: %w", err)
+	}
+	return nil
+}
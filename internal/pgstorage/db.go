@@ -4,11 +4,18 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"time"
 
+	"github.com/NikolayStrekalov/vigilant-octo-waddle.git/internal/histogram"
+	"github.com/NikolayStrekalov/vigilant-octo-waddle.git/internal/history"
+	"github.com/NikolayStrekalov/vigilant-octo-waddle.git/internal/hll"
 	"github.com/NikolayStrekalov/vigilant-octo-waddle.git/internal/logger"
 	"github.com/NikolayStrekalov/vigilant-octo-waddle.git/internal/models"
+	"github.com/NikolayStrekalov/vigilant-octo-waddle.git/internal/series"
+	"github.com/NikolayStrekalov/vigilant-octo-waddle.git/internal/sketch"
 	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/mailru/easyjson"
 )
 
 type Config struct {
@@ -19,12 +26,238 @@ type DB struct {
 	pool *pgxpool.Pool
 }
 
+// Каждое обновление пишет текущее значение и сэмпл в историю одним запросом.
 const (
-	sqlUpdateGauge = `INSERT INTO gauges(name, value) VALUES ($1, $2)
-ON CONFLICT ON CONSTRAINT gauges_name_key DO UPDATE SET value = EXCLUDED.value;`
-	sqlIncrementCounter = `INSERT INTO counters(name, value) VALUES ($1, $2)
-ON CONFLICT ON CONSTRAINT counters_name_key DO UPDATE SET value = counters.value + EXCLUDED.value;`
+	sqlUpdateGauge = `WITH upd AS (
+	INSERT INTO gauges(name, labels, value) VALUES ($1, $2, $3)
+	ON CONFLICT ON CONSTRAINT gauges_series_key DO UPDATE SET value = EXCLUDED.value, updated_at = now()
+	RETURNING name, labels, value
 )
+INSERT INTO samples(kind, name, labels, value) SELECT 'gauge', name, labels, value FROM upd;`
+	sqlIncrementCounter = `WITH upd AS (
+	INSERT INTO counters(name, labels, value) VALUES ($1, $2, $3)
+	ON CONFLICT ON CONSTRAINT counters_series_key DO UPDATE SET
+		value = counters.value + EXCLUDED.value,
+		updated_at = now()
+	RETURNING name, labels, value
+)
+INSERT INTO samples(kind, name, labels, value) SELECT 'counter', name, labels, value FROM upd;`
+	// уменьшение накопительного значения считается сбросом, как и в memstorage
+	sqlAddCounterTotal = `WITH upd AS (
+	INSERT INTO counters(name, labels, value, total) VALUES ($1, $2, $3, $3)
+	ON CONFLICT ON CONSTRAINT counters_series_key DO UPDATE SET
+		value = counters.value + CASE
+			WHEN counters.total IS NULL OR EXCLUDED.total < counters.total THEN EXCLUDED.total
+			ELSE EXCLUDED.total - counters.total
+		END,
+		total = EXCLUDED.total,
+		updated_at = now()
+	RETURNING name, labels, value
+)
+INSERT INTO samples(kind, name, labels, value) SELECT 'counter', name, labels, value FROM upd;`
+	// приращение с другими границами не применяется, как и в memstorage: строка не меняется
+	sqlUpdateHistogram = `INSERT INTO histograms(name, labels, bounds, counts, sum, count)
+VALUES ($1, $2, $3, $4, $5, $6)
+ON CONFLICT ON CONSTRAINT histograms_series_key DO UPDATE SET
+	counts = ARRAY(
+		SELECT a + b FROM unnest(histograms.counts, EXCLUDED.counts) WITH ORDINALITY AS t(a, b, n) ORDER BY n
+	),
+	sum = histograms.sum + EXCLUDED.sum,
+	count = histograms.count + EXCLUDED.count
+WHERE histograms.bounds = EXCLUDED.bounds;`
+	sqlInsertBatch = `INSERT INTO batches(key, seq) VALUES ($1, $2) ON CONFLICT DO NOTHING;`
+	// наброски summary и set сливаются в Go, см. mergeValue
+	sqlInsertSummary = `INSERT INTO summaries(name, labels, sketch) VALUES ($1, $2, $3)
+ON CONFLICT ON CONSTRAINT summaries_series_key DO NOTHING;`
+	sqlLockSummary   = `SELECT sketch FROM summaries WHERE name = $1 AND labels = $2 FOR UPDATE;`
+	sqlUpdateSummary = `UPDATE summaries SET sketch = $3 WHERE name = $1 AND labels = $2;`
+	sqlInsertSet     = `INSERT INTO sets(name, labels, hll) VALUES ($1, $2, $3)
+ON CONFLICT ON CONSTRAINT sets_series_key DO NOTHING;`
+	sqlLockSet       = `SELECT hll FROM sets WHERE name = $1 AND labels = $2 FOR UPDATE;`
+	sqlUpdateSet     = `UPDATE sets SET hll = $3 WHERE name = $1 AND labels = $2;`
+	sqlSelectHistory = `SELECT ts, value FROM samples
+WHERE kind = $1 AND name = $2 AND ts >= $3 AND ts <= $4 AND labels = $5
+ORDER BY ts;`
+	sqlSelectHistoryStep = `SELECT bucket, min(value), max(value), avg(value), (array_agg(value ORDER BY ts DESC))[1]
+FROM (
+	SELECT to_timestamp(floor(extract(epoch FROM ts)::double precision / $5) * $5) AS bucket, ts, value
+	FROM samples
+	WHERE kind = $1 AND name = $2 AND ts >= $3 AND ts <= $4 AND labels = $6
+) s
+GROUP BY bucket
+ORDER BY bucket;`
+	sqlSelectRollups = `SELECT bucket, min, max, sum / count, last FROM rollups
+WHERE kind = $1 AND name = $2 AND resolution = $3 AND bucket >= $4 AND bucket <= $5 AND labels = $6
+ORDER BY bucket;`
+	sqlSelectRollupsStep = `SELECT b, min(min), max(max), sum(sum) / sum(count), (array_agg(last ORDER BY bucket DESC))[1]
+FROM (
+	SELECT to_timestamp(floor(extract(epoch FROM bucket)::double precision / $6) * $6) AS b, bucket,
+		min, max, sum, count, last
+	FROM rollups
+	WHERE kind = $1 AND name = $2 AND resolution = $3 AND bucket >= $4 AND bucket <= $5 AND labels = $7
+) r
+GROUP BY b
+ORDER BY b;`
+	sqlCompactRollups = `INSERT INTO rollups(kind, name, labels, resolution, bucket, min, max, sum, count, last)
+SELECT kind, name, labels, $1::int, bucket, min(value), max(value), sum(value), count(*),
+	(array_agg(value ORDER BY ts DESC))[1]
+FROM (
+	SELECT kind, name, labels, ts, value,
+		to_timestamp(floor(extract(epoch FROM ts)::double precision / $1::int) * $1::int) AS bucket
+	FROM samples
+	WHERE ts >= $2 AND ts < $3
+) s
+GROUP BY kind, name, labels, bucket
+ON CONFLICT (kind, name, labels, resolution, bucket) DO UPDATE SET
+	min = EXCLUDED.min, max = EXCLUDED.max, sum = EXCLUDED.sum, count = EXCLUDED.count, last = EXCLUDED.last;`
+	sqlDeleteRollups        = `DELETE FROM rollups WHERE resolution = $1 AND bucket < $2;`
+	sqlDeleteUnknownRollups = `DELETE FROM rollups WHERE resolution <> ALL($1);`
+	sqlDeleteSamples        = `DELETE FROM samples WHERE ts < $1;`
+)
+
+// migrations содержит изменения схемы, индекс в слайсе плюс один — номер версии.
+var migrations = [][]string{
+	{
+		`CREATE TABLE IF NOT EXISTS gauges(
+			id INT PRIMARY KEY GENERATED ALWAYS AS IDENTITY,
+			name VARCHAR(200) UNIQUE NOT NULL,
+			value DOUBLE PRECISION NOT NULL
+		)`,
+		`CREATE TABLE IF NOT EXISTS counters(
+			id INT PRIMARY KEY GENERATED ALWAYS AS IDENTITY,
+			name VARCHAR(200) UNIQUE NOT NULL,
+			value BIGINT NOT NULL
+		)`,
+	},
+	{
+		`CREATE TABLE IF NOT EXISTS samples(
+			id BIGINT PRIMARY KEY GENERATED ALWAYS AS IDENTITY,
+			kind VARCHAR(20) NOT NULL,
+			name VARCHAR(200) NOT NULL,
+			value DOUBLE PRECISION NOT NULL,
+			ts TIMESTAMPTZ NOT NULL DEFAULT now()
+		)`,
+		`CREATE INDEX IF NOT EXISTS samples_series_ts_idx ON samples(kind, name, ts)`,
+	},
+	{
+		`CREATE TABLE IF NOT EXISTS rollups(
+			kind VARCHAR(20) NOT NULL,
+			name VARCHAR(200) NOT NULL,
+			resolution INT NOT NULL,
+			bucket TIMESTAMPTZ NOT NULL,
+			min DOUBLE PRECISION NOT NULL,
+			max DOUBLE PRECISION NOT NULL,
+			sum DOUBLE PRECISION NOT NULL,
+			count BIGINT NOT NULL,
+			last DOUBLE PRECISION NOT NULL,
+			PRIMARY KEY (kind, name, resolution, bucket)
+		)`,
+		`CREATE INDEX IF NOT EXISTS samples_ts_idx ON samples(ts)`,
+	},
+	{
+		`CREATE TABLE IF NOT EXISTS alert_rules(
+			id BIGINT PRIMARY KEY GENERATED ALWAYS AS IDENTITY,
+			kind VARCHAR(20) NOT NULL,
+			name VARCHAR(200) NOT NULL,
+			op VARCHAR(2) NOT NULL,
+			threshold DOUBLE PRECISION NOT NULL,
+			hold VARCHAR(40) NOT NULL DEFAULT '',
+			rate BOOLEAN NOT NULL DEFAULT false
+		)`,
+	},
+	{
+		`CREATE TABLE IF NOT EXISTS maintenance_windows(
+			id BIGINT PRIMARY KEY GENERATED ALWAYS AS IDENTITY,
+			pattern VARCHAR(200) NOT NULL,
+			mode VARCHAR(10) NOT NULL,
+			comment TEXT NOT NULL DEFAULT '',
+			starts_at TIMESTAMPTZ NOT NULL,
+			ends_at TIMESTAMPTZ NOT NULL
+		)`,
+	},
+	{
+		// серия — имя вместе с метками, данные без меток получают пустой набор
+		`ALTER TABLE gauges ADD COLUMN IF NOT EXISTS labels JSONB NOT NULL DEFAULT '{}'`,
+		`ALTER TABLE gauges DROP CONSTRAINT IF EXISTS gauges_name_key`,
+		`ALTER TABLE gauges ADD CONSTRAINT gauges_series_key UNIQUE (name, labels)`,
+		`ALTER TABLE counters ADD COLUMN IF NOT EXISTS labels JSONB NOT NULL DEFAULT '{}'`,
+		`ALTER TABLE counters DROP CONSTRAINT IF EXISTS counters_name_key`,
+		`ALTER TABLE counters ADD CONSTRAINT counters_series_key UNIQUE (name, labels)`,
+		`ALTER TABLE samples ADD COLUMN IF NOT EXISTS labels JSONB NOT NULL DEFAULT '{}'`,
+		`DROP INDEX IF EXISTS samples_series_ts_idx`,
+		`CREATE INDEX IF NOT EXISTS samples_series_ts_idx ON samples(kind, name, labels, ts)`,
+		`ALTER TABLE rollups ADD COLUMN IF NOT EXISTS labels JSONB NOT NULL DEFAULT '{}'`,
+		`ALTER TABLE rollups DROP CONSTRAINT IF EXISTS rollups_pkey`,
+		`ALTER TABLE rollups ADD PRIMARY KEY (kind, name, labels, resolution, bucket)`,
+	},
+	{
+		`CREATE TABLE IF NOT EXISTS histograms(
+			id INT PRIMARY KEY GENERATED ALWAYS AS IDENTITY,
+			name VARCHAR(200) NOT NULL,
+			labels JSONB NOT NULL DEFAULT '{}',
+			bounds DOUBLE PRECISION[] NOT NULL,
+			counts BIGINT[] NOT NULL,
+			sum DOUBLE PRECISION NOT NULL,
+			count BIGINT NOT NULL,
+			CONSTRAINT histograms_series_key UNIQUE (name, labels)
+		)`,
+	},
+	{
+		`CREATE TABLE IF NOT EXISTS summaries(
+			id INT PRIMARY KEY GENERATED ALWAYS AS IDENTITY,
+			name VARCHAR(200) NOT NULL,
+			labels JSONB NOT NULL DEFAULT '{}',
+			sketch JSONB NOT NULL,
+			CONSTRAINT summaries_series_key UNIQUE (name, labels)
+		)`,
+	},
+	{
+		`CREATE TABLE IF NOT EXISTS sets(
+			id INT PRIMARY KEY GENERATED ALWAYS AS IDENTITY,
+			name VARCHAR(200) NOT NULL,
+			labels JSONB NOT NULL DEFAULT '{}',
+			hll BYTEA NOT NULL,
+			CONSTRAINT sets_series_key UNIQUE (name, labels)
+		)`,
+	},
+	{
+		// последнее накопительное значение счётчика, NULL — значения ещё не приходили
+		`ALTER TABLE counters ADD COLUMN IF NOT EXISTS total BIGINT`,
+	},
+	{
+		// ключи применённых пачек /updates/, по ним отбрасываются повторы
+		`CREATE TABLE IF NOT EXISTS batches(
+			key VARCHAR(100) NOT NULL,
+			seq BIGINT NOT NULL,
+			applied_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+			PRIMARY KEY (key, seq)
+		)`,
+		`CREATE INDEX IF NOT EXISTS batches_applied_at_idx ON batches(applied_at)`,
+	},
+	{
+		`CREATE TABLE IF NOT EXISTS agents(
+			id VARCHAR(200) PRIMARY KEY,
+			hostname VARCHAR(200) NOT NULL DEFAULT '',
+			platform VARCHAR(200) NOT NULL DEFAULT '',
+			version VARCHAR(200) NOT NULL DEFAULT '',
+			address VARCHAR(200) NOT NULL DEFAULT '',
+			metrics INT NOT NULL DEFAULT 0,
+			first_seen TIMESTAMPTZ NOT NULL,
+			last_seen TIMESTAMPTZ NOT NULL
+		)`,
+	},
+	{
+		// время последнего обновления, по нему серии скрываются из списков и удаляются
+		`ALTER TABLE gauges ADD COLUMN IF NOT EXISTS updated_at TIMESTAMPTZ NOT NULL DEFAULT now()`,
+		`ALTER TABLE counters ADD COLUMN IF NOT EXISTS updated_at TIMESTAMPTZ NOT NULL DEFAULT now()`,
+		`CREATE INDEX IF NOT EXISTS gauges_updated_at_idx ON gauges(updated_at)`,
+		`CREATE INDEX IF NOT EXISTS counters_updated_at_idx ON counters(updated_at)`,
+	},
+	{
+		// метрики, последнее значение которых получено во время обслуживания
+		`CREATE TABLE IF NOT EXISTS maintenance_flags(key TEXT PRIMARY KEY)`,
+	},
+}
 
 func NewDB(ctx context.Context, cfg Config) (*DB, error) {
 	pool, err := initPool(ctx, cfg)
@@ -59,10 +292,11 @@ func initPool(ctx context.Context, cfg Config) (*pgxpool.Pool, error) {
 func (db *DB) initTables(ctx context.Context) error {
 	row := db.pool.QueryRow(ctx, "SELECT version FROM migrations WHERE id = 1")
 	var version int
-	err := row.Scan(&version)
-	if err != nil || version == 0 {
-		err := db.createSchema(ctx)
-		if err != nil {
+	if err := row.Scan(&version); err != nil {
+		version = 0
+	}
+	for ; version < len(migrations); version++ {
+		if err := db.migrate(ctx, version+1, migrations[version]); err != nil {
 			return err
 		}
 	}
@@ -74,7 +308,7 @@ func (db *DB) Close() error {
 	return nil
 }
 
-func (db *DB) createSchema(ctx context.Context) error {
+func (db *DB) migrate(ctx context.Context, version int, stmts []string) error {
 	tx, err := db.pool.BeginTx(ctx, pgx.TxOptions{})
 	if err != nil {
 		return fmt.Errorf("failed to start a transaction: %w", err)
@@ -87,29 +321,26 @@ func (db *DB) createSchema(ctx context.Context) error {
 		}
 	}()
 
-	createSchemaStmts := []string{
+	stmts = append([]string{
 		`CREATE TABLE IF NOT EXISTS migrations(
 			id INT PRIMARY KEY,
 			version INT NOT NULL
 		)`,
-		`CREATE TABLE IF NOT EXISTS gauges(
-			id INT PRIMARY KEY GENERATED ALWAYS AS IDENTITY,
-			name VARCHAR(200) UNIQUE NOT NULL,
-			value DOUBLE PRECISION NOT NULL
-		)`,
-		`CREATE TABLE IF NOT EXISTS counters(
-			id INT PRIMARY KEY GENERATED ALWAYS AS IDENTITY,
-			name VARCHAR(200) UNIQUE NOT NULL,
-			value BIGINT NOT NULL
-		)`,
-		`INSERT INTO migrations(id, version) VALUES (1, 1)`,
-	}
-
-	for _, stmt := range createSchemaStmts {
+	}, stmts...)
+	for _, stmt := range stmts {
 		if _, err := tx.Exec(ctx, stmt); err != nil {
 			return fmt.Errorf("failed to execute statement `%s`: %w", stmt, err)
 		}
 	}
+	_, err = tx.Exec(
+		ctx,
+		`INSERT INTO migrations(id, version) VALUES (1, $1)
+		ON CONFLICT (id) DO UPDATE SET version = EXCLUDED.version`,
+		version,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to set schema version %d: %w", version, err)
+	}
 
 	if err := tx.Commit(ctx); err != nil {
 		return fmt.Errorf("failed to commit the transaction: %w", err)
@@ -117,97 +348,532 @@ func (db *DB) createSchema(ctx context.Context) error {
 	return nil
 }
 
-func (db *DB) GetGauge(ctx context.Context, name string) (float64, error) {
+// splitKey разбирает ключ серии на значения колонок name и labels.
+func splitKey(key string) (string, map[string]string) {
+	name, labels := series.Parse(key)
+	if labels == nil {
+		labels = map[string]string{}
+	}
+	return name, labels
+}
+
+func (db *DB) GetGauge(ctx context.Context, key string) (float64, error) {
 	var value float64
-	row := db.pool.QueryRow(ctx, "SELECT value FROM gauges WHERE name=$1;", name)
+	name, labels := splitKey(key)
+	row := db.pool.QueryRow(ctx, "SELECT value FROM gauges WHERE name=$1 AND labels=$2;", name, labels)
 	err := row.Scan(&value)
 	if err != nil {
-		return 0, fmt.Errorf("error getting gauge '%s': %w", name, err)
+		return 0, fmt.Errorf("error getting gauge '%s': %w", key, err)
 	}
 	return value, nil
 }
 
-func (db *DB) GetGauges(ctx context.Context) ([]GaugeListItem, error) {
+// GetGauges возвращает gauge, обновлённые не раньше since.
+func (db *DB) GetGauges(ctx context.Context, since time.Time) ([]GaugeListItem, error) {
 	var (
-		name  string
-		value float64
-		ret   = []GaugeListItem{}
+		name   string
+		labels map[string]string
+		value  float64
+		ret    = []GaugeListItem{}
 	)
-	rows, err := db.pool.Query(ctx, "SELECT name, value FROM gauges;")
+	rows, err := db.pool.Query(ctx, "SELECT name, labels, value FROM gauges WHERE updated_at >= $1;", since)
 	if err != nil {
 		return ret, fmt.Errorf("error fetching gauges: %w", err)
 	}
 	defer rows.Close()
 	for rows.Next() {
-		err := rows.Scan(&name, &value)
+		err := rows.Scan(&name, &labels, &value)
 		if err != nil {
 			return ret, fmt.Errorf("error reading gauges: %w", err)
 		}
-		ret = append(ret, GaugeListItem{Name: name, Value: value})
+		ret = append(ret, GaugeListItem{Name: series.Key(name, labels), Value: value})
 	}
 	return ret, nil
 }
 
-func (db *DB) GetCounter(ctx context.Context, name string) (int64, error) {
+func (db *DB) GetCounter(ctx context.Context, key string) (int64, error) {
 	var value int64
-	row := db.pool.QueryRow(ctx, "SELECT value FROM counters WHERE name=$1;", name)
+	name, labels := splitKey(key)
+	row := db.pool.QueryRow(ctx, "SELECT value FROM counters WHERE name=$1 AND labels=$2;", name, labels)
 	err := row.Scan(&value)
 	if err != nil {
-		return 0, fmt.Errorf("error getting counter '%s': %w", name, err)
+		return 0, fmt.Errorf("error getting counter '%s': %w", key, err)
 	}
 	return value, nil
 }
 
-func (db *DB) GetCounters(ctx context.Context) ([]CounterListItem, error) {
+// GetCounters возвращает counter, обновлённые не раньше since.
+func (db *DB) GetCounters(ctx context.Context, since time.Time) ([]CounterListItem, error) {
 	var (
-		name  string
-		value int64
-		ret   = []CounterListItem{}
+		name   string
+		labels map[string]string
+		value  int64
+		ret    = []CounterListItem{}
 	)
-	rows, err := db.pool.Query(ctx, "SELECT name, value FROM counters;")
+	rows, err := db.pool.Query(ctx, "SELECT name, labels, value FROM counters WHERE updated_at >= $1;", since)
 	if err != nil {
 		return ret, fmt.Errorf("error fetching counters: %w", err)
 	}
 	defer rows.Close()
 	for rows.Next() {
-		err := rows.Scan(&name, &value)
+		err := rows.Scan(&name, &labels, &value)
 		if err != nil {
 			return ret, fmt.Errorf("error reading counters: %w", err)
 		}
-		ret = append(ret, CounterListItem{Name: name, Value: value})
+		ret = append(ret, CounterListItem{Name: series.Key(name, labels), Value: value})
+	}
+	return ret, nil
+}
+
+func (db *DB) GetHistogram(ctx context.Context, key string) (models.Histogram, error) {
+	var (
+		counts []int64
+		h      models.Histogram
+		count  int64
+	)
+	name, labels := splitKey(key)
+	row := db.pool.QueryRow(ctx, "SELECT bounds, counts, sum, count FROM histograms WHERE name=$1 AND labels=$2;",
+		name, labels)
+	err := row.Scan(&h.Bounds, &counts, &h.Sum, &count)
+	if err != nil {
+		return h, fmt.Errorf("error getting histogram '%s': %w", key, err)
+	}
+	h.Counts, h.Count = toUnsigned(counts), uint64(count)
+	return h, nil
+}
+
+func (db *DB) GetHistograms(ctx context.Context) ([]HistogramListItem, error) {
+	var (
+		name   string
+		labels map[string]string
+		ret    = []HistogramListItem{}
+	)
+	rows, err := db.pool.Query(ctx, "SELECT name, labels, bounds, counts, sum, count FROM histograms;")
+	if err != nil {
+		return ret, fmt.Errorf("error fetching histograms: %w", err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var (
+			counts []int64
+			h      models.Histogram
+			count  int64
+		)
+		err := rows.Scan(&name, &labels, &h.Bounds, &counts, &h.Sum, &count)
+		if err != nil {
+			return ret, fmt.Errorf("error reading histograms: %w", err)
+		}
+		h.Counts, h.Count = toUnsigned(counts), uint64(count)
+		ret = append(ret, HistogramListItem{Name: series.Key(name, labels), Value: h})
+	}
+	return ret, nil
+}
+
+func (db *DB) GetSummary(ctx context.Context, key string) (models.Sketch, error) {
+	var (
+		raw []byte
+		ret models.Sketch
+	)
+	name, labels := splitKey(key)
+	row := db.pool.QueryRow(ctx, "SELECT sketch FROM summaries WHERE name=$1 AND labels=$2;", name, labels)
+	if err := row.Scan(&raw); err != nil {
+		return ret, fmt.Errorf("error getting summary '%s': %w", key, err)
+	}
+	if err := easyjson.Unmarshal(raw, &ret); err != nil {
+		return ret, fmt.Errorf("error decoding summary '%s': %w", key, err)
+	}
+	return ret, nil
+}
+
+func (db *DB) GetSummaries(ctx context.Context) ([]SummaryListItem, error) {
+	var (
+		name   string
+		labels map[string]string
+		raw    []byte
+		ret    = []SummaryListItem{}
+	)
+	rows, err := db.pool.Query(ctx, "SELECT name, labels, sketch FROM summaries;")
+	if err != nil {
+		return ret, fmt.Errorf("error fetching summaries: %w", err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		if err := rows.Scan(&name, &labels, &raw); err != nil {
+			return ret, fmt.Errorf("error reading summaries: %w", err)
+		}
+		item := SummaryListItem{Name: series.Key(name, labels)}
+		if err := easyjson.Unmarshal(raw, &item.Value); err != nil {
+			return ret, fmt.Errorf("error decoding summary '%s': %w", item.Name, err)
+		}
+		ret = append(ret, item)
 	}
 	return ret, nil
 }
 
-func (db *DB) UpdateGauge(ctx context.Context, name string, value float64) error {
-	_, err := db.pool.Exec(ctx, sqlUpdateGauge, name, value)
+// toSigned и toUnsigned переводят числа наблюдений в BIGINT и обратно.
+func toSigned(values []uint64) []int64 {
+	ret := make([]int64, len(values))
+	for i, v := range values {
+		ret[i] = int64(v)
+	}
+	return ret
+}
+
+func toUnsigned(values []int64) []uint64 {
+	ret := make([]uint64, len(values))
+	for i, v := range values {
+		ret[i] = uint64(v)
+	}
+	return ret
+}
+
+func histogramArgs(name string, labels map[string]string, h *models.Histogram) []any {
+	return []any{name, labels, h.Bounds, toSigned(h.Counts), h.Sum, int64(h.Count)}
+}
+
+func (db *DB) UpdateGauge(ctx context.Context, key string, value float64) error {
+	name, labels := splitKey(key)
+	_, err := db.pool.Exec(ctx, sqlUpdateGauge, name, labels, value)
+	if err != nil {
+		return fmt.Errorf("failed to update gauge %s: %w", key, err)
+	}
+	return nil
+}
+
+func (db *DB) IncrementCounter(ctx context.Context, key string, value int64) error {
+	name, labels := splitKey(key)
+	_, err := db.pool.Exec(ctx, sqlIncrementCounter, name, labels, value)
+	if err != nil {
+		return fmt.Errorf("failed to increment counter %s: %w", key, err)
+	}
+	return nil
+}
+
+func (db *DB) AddCounterTotal(ctx context.Context, key string, total int64) error {
+	name, labels := splitKey(key)
+	_, err := db.pool.Exec(ctx, sqlAddCounterTotal, name, labels, total)
+	if err != nil {
+		return fmt.Errorf("failed to add total to counter %s: %w", key, err)
+	}
+	return nil
+}
+
+// UpdateHistogram прибавляет к гистограмме приращение delta.
+// При других границах корзин возвращается histogram.ErrBoundsMismatch.
+func (db *DB) UpdateHistogram(ctx context.Context, key string, delta *models.Histogram) error {
+	name, labels := splitKey(key)
+	tag, err := db.pool.Exec(ctx, sqlUpdateHistogram, histogramArgs(name, labels, delta)...)
+	if err != nil {
+		return fmt.Errorf("failed to update histogram %s: %w", key, err)
+	}
+	if tag.RowsAffected() == 0 {
+		return fmt.Errorf("failed to update histogram %s: %w", key, histogram.ErrBoundsMismatch)
+	}
+	return nil
+}
+
+func (db *DB) UpdateSummary(ctx context.Context, key string, s *models.Sketch) error {
+	name, labels := splitKey(key)
+	if err := db.updateSummary(ctx, db.pool, name, labels, s); err != nil {
+		return fmt.Errorf("failed to update summary %s: %w", key, err)
+	}
+	return nil
+}
+
+// querier — пул соединений или транзакция, внутри которой Begin открывает точку сохранения.
+type querier interface {
+	Begin(ctx context.Context) (pgx.Tx, error)
+	SendBatch(ctx context.Context, b *pgx.Batch) pgx.BatchResults
+}
+
+// mergeQueries — запросы к таблице, значения которой сливаются в Go.
+type mergeQueries struct {
+	insert string // вставляет новую серию, при конфликте ничего не делает
+	lock   string // читает значение серии, блокируя строку
+	update string // записывает слитое значение
+}
+
+var (
+	summaryQueries = mergeQueries{insert: sqlInsertSummary, lock: sqlLockSummary, update: sqlUpdateSummary}
+	setQueries     = mergeQueries{insert: sqlInsertSet, lock: sqlLockSet, update: sqlUpdateSet}
+)
+
+// mergeValue записывает значение data новой серии или сливает его с сохранённым функцией merge.
+// Строка блокируется до конца транзакции, чтобы параллельные слияния не теряли данные.
+func (db *DB) mergeValue(
+	ctx context.Context,
+	conn querier,
+	q mergeQueries,
+	name string,
+	labels map[string]string,
+	data []byte,
+	merge func(stored []byte) ([]byte, error),
+) error {
+	tx, err := conn.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to start a transaction: %w", err)
+	}
+	defer func() {
+		if err := tx.Rollback(ctx); err != nil {
+			if !errors.Is(err, pgx.ErrTxClosed) {
+				logger.Info("failed to rollback the transaction", err)
+			}
+		}
+	}()
+	tag, err := tx.Exec(ctx, q.insert, name, labels, data)
 	if err != nil {
-		return fmt.Errorf("failed to update gauge %s: %w", name, err)
+		return fmt.Errorf("failed to insert value: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		var stored []byte
+		if err := tx.QueryRow(ctx, q.lock, name, labels).Scan(&stored); err != nil {
+			return fmt.Errorf("failed to read value: %w", err)
+		}
+		if data, err = merge(stored); err != nil {
+			return err
+		}
+		if _, err := tx.Exec(ctx, q.update, name, labels, data); err != nil {
+			return fmt.Errorf("failed to update value: %w", err)
+		}
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to commit the transaction: %w", err)
 	}
 	return nil
 }
 
-func (db *DB) IncrementCounter(ctx context.Context, name string, value int64) error {
-	_, err := db.pool.Exec(ctx, sqlIncrementCounter, name, value)
+func (db *DB) updateSummary(
+	ctx context.Context, conn querier, name string, labels map[string]string, s *models.Sketch,
+) error {
+	data, err := easyjson.Marshal(s)
 	if err != nil {
-		return fmt.Errorf("failed to increment counter %s: %w", name, err)
+		return fmt.Errorf("failed to encode sketch: %w", err)
+	}
+	return db.mergeValue(ctx, conn, summaryQueries, name, labels, data, func(raw []byte) ([]byte, error) {
+		var stored models.Sketch
+		if err := easyjson.Unmarshal(raw, &stored); err != nil {
+			return nil, fmt.Errorf("failed to decode sketch: %w", err)
+		}
+		if err := sketch.Merge(&stored, s); err != nil {
+			return nil, err
+		}
+		data, err := easyjson.Marshal(&stored)
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode sketch: %w", err)
+		}
+		return data, nil
+	})
+}
+
+func (db *DB) updateSet(ctx context.Context, conn querier, name string, labels map[string]string, h *models.HLL) error {
+	return db.mergeValue(ctx, conn, setQueries, name, labels, hll.Encode(h), func(raw []byte) ([]byte, error) {
+		stored, err := hll.Decode(raw)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode hll: %w", err)
+		}
+		hll.Merge(&stored, h)
+		return hll.Encode(&stored), nil
+	})
+}
+
+func (db *DB) UpdateSet(ctx context.Context, key string, h *models.HLL) error {
+	name, labels := splitKey(key)
+	if err := db.updateSet(ctx, db.pool, name, labels, h); err != nil {
+		return fmt.Errorf("failed to update set %s: %w", key, err)
 	}
 	return nil
 }
 
+func (db *DB) GetSet(ctx context.Context, key string) (models.HLL, error) {
+	var raw []byte
+	name, labels := splitKey(key)
+	row := db.pool.QueryRow(ctx, "SELECT hll FROM sets WHERE name=$1 AND labels=$2;", name, labels)
+	if err := row.Scan(&raw); err != nil {
+		return models.HLL{}, fmt.Errorf("error getting set '%s': %w", key, err)
+	}
+	h, err := hll.Decode(raw)
+	if err != nil {
+		return h, fmt.Errorf("error decoding set '%s': %w", key, err)
+	}
+	return h, nil
+}
+
+func (db *DB) GetSets(ctx context.Context) ([]SetListItem, error) {
+	var (
+		name   string
+		labels map[string]string
+		raw    []byte
+		ret    = []SetListItem{}
+	)
+	rows, err := db.pool.Query(ctx, "SELECT name, labels, hll FROM sets;")
+	if err != nil {
+		return ret, fmt.Errorf("error fetching sets: %w", err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		if err := rows.Scan(&name, &labels, &raw); err != nil {
+			return ret, fmt.Errorf("error reading sets: %w", err)
+		}
+		h, err := hll.Decode(raw)
+		if err != nil {
+			return ret, fmt.Errorf("error decoding set '%s': %w", series.Key(name, labels), err)
+		}
+		ret = append(ret, SetListItem{Name: series.Key(name, labels), Value: h})
+	}
+	return ret, nil
+}
+
 func (db *DB) BulkUpdate(ctx context.Context, metrics models.MetricsSlice) error {
+	return db.bulkUpdate(ctx, db.pool, metrics)
+}
+
+// BulkUpdateOnce применяет пачку в одной транзакции с записью её ключа. Повтор пачки ждёт завершения
+// первой транзакции на уникальном ключе и ничего не меняет.
+func (db *DB) BulkUpdateOnce(ctx context.Context, key string, seq int64, metrics models.MetricsSlice) (bool, error) {
+	tx, err := db.pool.BeginTx(ctx, pgx.TxOptions{})
+	if err != nil {
+		return false, fmt.Errorf("failed to start a transaction: %w", err)
+	}
+	defer func() {
+		if err := tx.Rollback(ctx); err != nil {
+			if !errors.Is(err, pgx.ErrTxClosed) {
+				logger.Info("failed to rollback the transaction", err)
+			}
+		}
+	}()
+	tag, err := tx.Exec(ctx, sqlInsertBatch, key, seq)
+	if err != nil {
+		return false, fmt.Errorf("failed to record batch %s/%d: %w", key, seq, err)
+	}
+	if tag.RowsAffected() == 0 {
+		return false, nil
+	}
+	if err := db.bulkUpdate(ctx, tx, metrics); err != nil {
+		return false, err
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return false, fmt.Errorf("failed to commit the transaction: %w", err)
+	}
+	return true, nil
+}
+
+func (db *DB) ForgetBatches(ctx context.Context, before time.Time) error {
+	if _, err := db.pool.Exec(ctx, "DELETE FROM batches WHERE applied_at < $1;", before); err != nil {
+		return fmt.Errorf("error deleting batch keys: %w", err)
+	}
+	return nil
+}
+
+func (db *DB) bulkUpdate(ctx context.Context, conn querier, metrics models.MetricsSlice) error {
 	batch := &pgx.Batch{}
-	for _, metric := range metrics {
-		switch metric.MType {
+	for i := range metrics {
+		labels := metrics[i].Labels
+		if labels == nil {
+			labels = map[string]string{}
+		}
+		switch metrics[i].MType {
 		case "counter":
-			batch.Queue(sqlIncrementCounter, metric.ID, metric.Delta)
+			if metrics[i].Total != nil {
+				batch.Queue(sqlAddCounterTotal, metrics[i].ID, labels, metrics[i].Total)
+			} else {
+				batch.Queue(sqlIncrementCounter, metrics[i].ID, labels, metrics[i].Delta)
+			}
 		case "gauge":
-			batch.Queue(sqlUpdateGauge, metric.ID, metric.Value)
+			batch.Queue(sqlUpdateGauge, metrics[i].ID, labels, metrics[i].Value)
+		case "histogram":
+			if metrics[i].Histogram != nil {
+				batch.Queue(sqlUpdateHistogram, histogramArgs(metrics[i].ID, labels, metrics[i].Histogram)...)
+			}
 		}
 	}
-	err := db.pool.SendBatch(ctx, batch).Close()
+	err := conn.SendBatch(ctx, batch).Close()
 	if err != nil {
 		return fmt.Errorf("error sending batch update: %w", err)
 	}
+	for i := range metrics {
+		labels := metrics[i].Labels
+		if labels == nil {
+			labels = map[string]string{}
+		}
+		switch {
+		case metrics[i].MType == "summary" && metrics[i].Sketch != nil:
+			err = db.updateSummary(ctx, conn, metrics[i].ID, labels, metrics[i].Sketch)
+			// набросок с другой точностью пропускается, как и в memstorage, его точка сохранения уже откачена
+			if errors.Is(err, sketch.ErrAlphaMismatch) {
+				logger.Info("skip summary update:", metrics[i].Key(), err)
+				err = nil
+			}
+		case metrics[i].MType == "set" && metrics[i].HLL != nil:
+			err = db.updateSet(ctx, conn, metrics[i].ID, labels, metrics[i].HLL)
+		}
+		if err != nil {
+			return fmt.Errorf("error updating %s %s: %w", metrics[i].MType, metrics[i].Key(), err)
+		}
+	}
+	return nil
+}
+
+// GetHistory читает сырые сэмплы при resolution == 0, иначе свёртки этого разрешения.
+func (db *DB) GetHistory(
+	ctx context.Context, kind, key string, from, to time.Time, step, resolution time.Duration,
+) ([]models.HistoryPoint, error) {
+	name, labels := splitKey(key)
+	var (
+		rows pgx.Rows
+		err  error
+		ret  = []models.HistoryPoint{}
+	)
+	seconds := int64(resolution / time.Second)
+	aggregated := step > 0 || resolution > 0
+	switch {
+	case resolution == 0 && step == 0:
+		rows, err = db.pool.Query(ctx, sqlSelectHistory, kind, name, from, to, labels)
+	case resolution == 0:
+		rows, err = db.pool.Query(ctx, sqlSelectHistoryStep, kind, name, from, to, step.Seconds(), labels)
+	case step > resolution:
+		from = history.Align(from, resolution)
+		rows, err = db.pool.Query(ctx, sqlSelectRollupsStep, kind, name, seconds, from, to, step.Seconds(), labels)
+	default:
+		from = history.Align(from, resolution)
+		rows, err = db.pool.Query(ctx, sqlSelectRollups, kind, name, seconds, from, to, labels)
+	}
+	if err != nil {
+		return ret, fmt.Errorf("error fetching history of %s %s: %w", kind, key, err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var point models.HistoryPoint
+		if aggregated {
+			point.Min, point.Max, point.Avg = new(float64), new(float64), new(float64)
+			err = rows.Scan(&point.Time, point.Min, point.Max, point.Avg, &point.Value)
+		} else {
+			err = rows.Scan(&point.Time, &point.Value)
+		}
+		if err != nil {
+			return ret, fmt.Errorf("error reading history of %s %s: %w", kind, key, err)
+		}
+		ret = append(ret, point)
+	}
+	if err := rows.Err(); err != nil {
+		return ret, fmt.Errorf("error reading history of %s %s: %w", kind, key, err)
+	}
+	return ret, nil
+}
+
+// CompactHistory строит свёртки за интервалы, завершившиеся после since, и удаляет устаревшие данные.
+func (db *DB) CompactHistory(ctx context.Context, p history.Policy, since, now time.Time) error {
+	batch := &pgx.Batch{}
+	resolutions := make([]int64, 0, len(p.Rollups))
+	for _, l := range p.Rollups {
+		seconds := int64(l.Resolution / time.Second)
+		resolutions = append(resolutions, seconds)
+		batch.Queue(sqlCompactRollups, seconds, history.Align(since, l.Resolution), history.Align(now, l.Resolution))
+		batch.Queue(sqlDeleteRollups, seconds, now.Add(-l.Retention))
+	}
+	batch.Queue(sqlDeleteUnknownRollups, resolutions)
+	batch.Queue(sqlDeleteSamples, now.Add(-p.Raw))
+	if err := db.pool.SendBatch(ctx, batch).Close(); err != nil {
+		return fmt.Errorf("error compacting history: %w", err)
+	}
 	return nil
 }
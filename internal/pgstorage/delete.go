@@ -0,0 +1,155 @@
+package pgstorage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path"
+
+	"github.com/NikolayStrekalov/vigilant-octo-waddle.git/internal/series"
+	"github.com/avast/retry-go/v4"
+	"github.com/jackc/pgx/v5"
+)
+
+var errMetricNotFound = errors.New("metric not found")
+
+// seriesTables — таблицы серий по типам метрик.
+var seriesTables = map[string]string{
+	"gauge":     "gauges",
+	"counter":   "counters",
+	"histogram": "histograms",
+	"summary":   "summaries",
+	"set":       "sets",
+}
+
+const sqlResetCounter = `WITH upd AS (
+	UPDATE counters SET value = 0, updated_at = now() WHERE name = $1 AND labels = $2
+	RETURNING name, labels, value
+)
+INSERT INTO samples(kind, name, labels, value) SELECT 'counter', name, labels, value FROM upd;`
+
+// queueDelete добавляет в batch удаление серии вместе с её историей.
+func queueDelete(batch *pgx.Batch, kind, name string, labels map[string]string) {
+	batch.Queue("DELETE FROM "+seriesTables[kind]+" WHERE name = $1 AND labels = $2;", name, labels)
+	batch.Queue("DELETE FROM samples WHERE kind = $1 AND name = $2 AND labels = $3;", kind, name, labels)
+	batch.Queue("DELETE FROM rollups WHERE kind = $1 AND name = $2 AND labels = $3;", kind, name, labels)
+}
+
+func (db *DB) DeleteMetric(ctx context.Context, kind, key string) error {
+	if _, ok := seriesTables[kind]; !ok {
+		return fmt.Errorf("failed to delete %s %s: %w", kind, key, errMetricNotFound)
+	}
+	name, labels := splitKey(key)
+	err := pgx.BeginFunc(ctx, db.pool, func(tx pgx.Tx) error {
+		var exists bool
+		row := tx.QueryRow(ctx, "SELECT true FROM "+seriesTables[kind]+" WHERE name = $1 AND labels = $2;", name, labels)
+		if err := row.Scan(&exists); err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				return errMetricNotFound
+			}
+			return fmt.Errorf("error reading series: %w", err)
+		}
+		batch := &pgx.Batch{}
+		queueDelete(batch, kind, name, labels)
+		if err := tx.SendBatch(ctx, batch).Close(); err != nil {
+			return fmt.Errorf("error sending batch delete: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed to delete %s %s: %w", kind, key, err)
+	}
+	return nil
+}
+
+// DeleteMetrics удаляет серии всех типов, ключи которых подходят под шаблон path.Match.
+// Шаблон проверяется в Go, поэтому ключи серий сначала читаются.
+func (db *DB) DeleteMetrics(ctx context.Context, pattern string) (int64, error) {
+	if _, err := path.Match(pattern, ""); err != nil {
+		return 0, fmt.Errorf("wrong pattern %q: %w", pattern, err)
+	}
+	var deleted int64
+	err := pgx.BeginFunc(ctx, db.pool, func(tx pgx.Tx) error {
+		deleted = 0
+		batch := &pgx.Batch{}
+		for kind, table := range seriesTables {
+			rows, err := tx.Query(ctx, "SELECT name, labels FROM "+table+" FOR UPDATE;")
+			if err != nil {
+				return fmt.Errorf("error fetching %s: %w", table, err)
+			}
+			var (
+				name   string
+				labels map[string]string
+			)
+			_, err = pgx.ForEachRow(rows, []any{&name, &labels}, func() error {
+				if ok, _ := path.Match(pattern, series.Key(name, labels)); ok {
+					queueDelete(batch, kind, name, labels)
+					deleted++
+				}
+				return nil
+			})
+			if err != nil {
+				return fmt.Errorf("error reading %s: %w", table, err)
+			}
+		}
+		if err := tx.SendBatch(ctx, batch).Close(); err != nil {
+			return fmt.Errorf("error sending batch delete: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, fmt.Errorf("failed to delete metrics by %q: %w", pattern, err)
+	}
+	return deleted, nil
+}
+
+func (db *DB) ResetCounter(ctx context.Context, key string) error {
+	name, labels := splitKey(key)
+	tag, err := db.pool.Exec(ctx, sqlResetCounter, name, labels)
+	if err != nil {
+		return fmt.Errorf("failed to reset counter %s: %w", key, err)
+	}
+	if tag.RowsAffected() == 0 {
+		return fmt.Errorf("failed to reset counter %s: %w", key, errMetricNotFound)
+	}
+	return nil
+}
+
+func (p *PGStorage) DeleteMetric(kind, name string) error {
+	err := retry.Do(
+		func() error {
+			return p.db.DeleteMetric(context.TODO(), kind, name)
+		},
+		RetryOptions...,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to delete metric: %w", err)
+	}
+	return nil
+}
+
+func (p *PGStorage) DeleteMetrics(pattern string) (int64, error) {
+	deleted, err := retry.DoWithData(
+		func() (int64, error) {
+			return p.db.DeleteMetrics(context.TODO(), pattern)
+		},
+		RetryOptions...,
+	)
+	if err != nil {
+		return 0, fmt.Errorf("failed to delete metrics: %w", err)
+	}
+	return deleted, nil
+}
+
+func (p *PGStorage) ResetCounter(name string) error {
+	err := retry.Do(
+		func() error {
+			return p.db.ResetCounter(context.TODO(), name)
+		},
+		RetryOptions...,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to reset counter: %w", err)
+	}
+	return nil
+}
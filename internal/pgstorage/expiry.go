@@ -0,0 +1,67 @@
+package pgstorage
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/avast/retry-go/v4"
+	"github.com/jackc/pgx/v5"
+)
+
+// sqlExpire удаляет устаревшие серии таблицы %s вместе с историей и возвращает их число.
+const sqlExpire = `WITH expired AS (
+	DELETE FROM %s WHERE updated_at < $1 RETURNING name, labels
+), expired_samples AS (
+	DELETE FROM samples s USING expired e WHERE s.kind = $2 AND s.name = e.name AND s.labels = e.labels
+), expired_rollups AS (
+	DELETE FROM rollups r USING expired e WHERE r.kind = $2 AND r.name = e.name AND r.labels = e.labels
+)
+SELECT count(*) FROM expired;`
+
+// ExpireMetrics удаляет gauge и counter, не обновлявшиеся с before, и возвращает число удалённых серий.
+func (db *DB) ExpireMetrics(ctx context.Context, before time.Time) (int64, error) {
+	var deleted int64
+	err := pgx.BeginFunc(ctx, db.pool, func(tx pgx.Tx) error {
+		deleted = 0
+		for _, kind := range []string{"gauge", "counter"} {
+			var n int64
+			row := tx.QueryRow(ctx, fmt.Sprintf(sqlExpire, seriesTables[kind]), before, kind)
+			if err := row.Scan(&n); err != nil {
+				return fmt.Errorf("error expiring %s: %w", seriesTables[kind], err)
+			}
+			deleted += n
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, fmt.Errorf("failed to expire metrics: %w", err)
+	}
+	return deleted, nil
+}
+
+// SetStaleTTL скрывает из списков gauge и counter, не обновлявшиеся дольше ttl. Ноль отключает скрытие.
+func (p *PGStorage) SetStaleTTL(ttl time.Duration) {
+	p.staleTTL = ttl
+}
+
+// freshSince возвращает время, раньше которого серия считается устаревшей.
+func (p *PGStorage) freshSince() time.Time {
+	if p.staleTTL <= 0 {
+		return time.Time{}
+	}
+	return time.Now().Add(-p.staleTTL)
+}
+
+func (p *PGStorage) ExpireMetrics(before time.Time) (int64, error) {
+	deleted, err := retry.DoWithData(
+		func() (int64, error) {
+			return p.db.ExpireMetrics(context.TODO(), before)
+		},
+		RetryOptions...,
+	)
+	if err != nil {
+		return 0, fmt.Errorf("failed to expire metrics: %w", err)
+	}
+	return deleted, nil
+}
@@ -0,0 +1,154 @@
+package pgstorage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/NikolayStrekalov/vigilant-octo-waddle.git/internal/models"
+	"github.com/avast/retry-go/v4"
+	"github.com/jackc/pgx/v5"
+)
+
+var errWindowNotFound = errors.New("maintenance window not found")
+
+func (db *DB) GetMaintenanceWindows(ctx context.Context) ([]models.MaintenanceWindow, error) {
+	ret := []models.MaintenanceWindow{}
+	rows, err := db.pool.Query(
+		ctx,
+		`SELECT id, pattern, mode, comment, starts_at, ends_at FROM maintenance_windows ORDER BY id;`,
+	)
+	if err != nil {
+		return ret, fmt.Errorf("error fetching maintenance windows: %w", err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var w models.MaintenanceWindow
+		if err := rows.Scan(&w.ID, &w.Pattern, &w.Mode, &w.Comment, &w.Start, &w.End); err != nil {
+			return ret, fmt.Errorf("error reading maintenance windows: %w", err)
+		}
+		ret = append(ret, w)
+	}
+	if err := rows.Err(); err != nil {
+		return ret, fmt.Errorf("error reading maintenance windows: %w", err)
+	}
+	return ret, nil
+}
+
+func (db *DB) AddMaintenanceWindow(
+	ctx context.Context,
+	window *models.MaintenanceWindow,
+) (models.MaintenanceWindow, error) {
+	added := *window
+	row := db.pool.QueryRow(
+		ctx,
+		`INSERT INTO maintenance_windows(pattern, mode, comment, starts_at, ends_at)
+		VALUES ($1, $2, $3, $4, $5) RETURNING id;`,
+		window.Pattern, window.Mode, window.Comment, window.Start, window.End,
+	)
+	if err := row.Scan(&added.ID); err != nil {
+		return added, fmt.Errorf("failed to add maintenance window: %w", err)
+	}
+	return added, nil
+}
+
+func (db *DB) DeleteMaintenanceWindow(ctx context.Context, id int64) error {
+	tag, err := db.pool.Exec(ctx, `DELETE FROM maintenance_windows WHERE id = $1;`, id)
+	if err != nil {
+		return fmt.Errorf("failed to delete maintenance window %d: %w", id, err)
+	}
+	if tag.RowsAffected() == 0 {
+		return fmt.Errorf("failed to delete maintenance window %d: %w", id, errWindowNotFound)
+	}
+	return nil
+}
+
+// GetMaintenanceFlags возвращает ключи метрик, помеченных во время обслуживания.
+func (db *DB) GetMaintenanceFlags(ctx context.Context) ([]string, error) {
+	rows, err := db.pool.Query(ctx, `SELECT key FROM maintenance_flags;`)
+	if err != nil {
+		return nil, fmt.Errorf("error fetching maintenance flags: %w", err)
+	}
+	ret, err := pgx.CollectRows(rows, pgx.RowTo[string])
+	if err != nil {
+		return nil, fmt.Errorf("error reading maintenance flags: %w", err)
+	}
+	return ret, nil
+}
+
+// SetMaintenanceFlag ставит или снимает пометку метрики.
+func (db *DB) SetMaintenanceFlag(ctx context.Context, key string, flagged bool) error {
+	query := `DELETE FROM maintenance_flags WHERE key = $1;`
+	if flagged {
+		query = `INSERT INTO maintenance_flags(key) VALUES ($1) ON CONFLICT DO NOTHING;`
+	}
+	if _, err := db.pool.Exec(ctx, query, key); err != nil {
+		return fmt.Errorf("failed to set maintenance flag %s: %w", key, err)
+	}
+	return nil
+}
+
+func (p *PGStorage) GetMaintenanceWindows() ([]models.MaintenanceWindow, error) {
+	ret, err := retry.DoWithData(
+		func() ([]models.MaintenanceWindow, error) {
+			return p.db.GetMaintenanceWindows(context.TODO())
+		},
+		RetryOptions...,
+	)
+	if err != nil {
+		return ret, fmt.Errorf("failed to get maintenance windows: %w", err)
+	}
+	return ret, nil
+}
+
+func (p *PGStorage) AddMaintenanceWindow(window *models.MaintenanceWindow) (models.MaintenanceWindow, error) {
+	ret, err := retry.DoWithData(
+		func() (models.MaintenanceWindow, error) {
+			return p.db.AddMaintenanceWindow(context.TODO(), window)
+		},
+		RetryOptions...,
+	)
+	if err != nil {
+		return ret, fmt.Errorf("failed to add maintenance window: %w", err)
+	}
+	return ret, nil
+}
+
+func (p *PGStorage) DeleteMaintenanceWindow(id int64) error {
+	err := retry.Do(
+		func() error {
+			return p.db.DeleteMaintenanceWindow(context.TODO(), id)
+		},
+		RetryOptions...,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to delete maintenance window: %w", err)
+	}
+	return nil
+}
+
+func (p *PGStorage) GetMaintenanceFlags() ([]string, error) {
+	ret, err := retry.DoWithData(
+		func() ([]string, error) {
+			return p.db.GetMaintenanceFlags(context.TODO())
+		},
+		RetryOptions...,
+	)
+	if err != nil {
+		return ret, fmt.Errorf("failed to get maintenance flags: %w", err)
+	}
+	return ret, nil
+}
+
+func (p *PGStorage) SetMaintenanceFlag(key string, flagged bool) error {
+	err := retry.Do(
+		func() error {
+			return p.db.SetMaintenanceFlag(context.TODO(), key, flagged)
+		},
+		RetryOptions...,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to set maintenance flag: %w", err)
+	}
+	return nil
+}
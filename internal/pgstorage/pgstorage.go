@@ -7,6 +7,7 @@ import (
 	"syscall"
 	"time"
 
+	"github.com/NikolayStrekalov/vigilant-octo-waddle.git/internal/history"
 	"github.com/NikolayStrekalov/vigilant-octo-waddle.git/internal/logger"
 	"github.com/NikolayStrekalov/vigilant-octo-waddle.git/internal/models"
 	"github.com/avast/retry-go/v4"
@@ -15,7 +16,9 @@ import (
 )
 
 type PGStorage struct {
-	db *DB
+	db       *DB
+	policy   history.Policy
+	staleTTL time.Duration
 }
 
 type GaugeListItem = struct {
@@ -28,6 +31,21 @@ type CounterListItem = struct {
 	Value int64
 }
 
+type HistogramListItem = struct {
+	Name  string
+	Value models.Histogram
+}
+
+type SummaryListItem = struct {
+	Name  string
+	Value models.Sketch
+}
+
+type SetListItem = struct {
+	Name  string
+	Value models.HLL
+}
+
 const maxRequestAttempts = 4
 
 var RetryOptions = []retry.Option{
@@ -60,7 +78,7 @@ func NewPGStorage(dsn string) (*PGStorage, func() error, error) {
 func (p *PGStorage) GetGaugeList() []GaugeListItem {
 	ret, err := retry.DoWithData(
 		func() ([]GaugeListItem, error) {
-			return p.db.GetGauges(context.TODO())
+			return p.db.GetGauges(context.TODO(), p.freshSince())
 		},
 		RetryOptions...,
 	)
@@ -73,7 +91,7 @@ func (p *PGStorage) GetGaugeList() []GaugeListItem {
 func (p *PGStorage) GetCounterList() []CounterListItem {
 	ret, err := retry.DoWithData(
 		func() ([]CounterListItem, error) {
-			return p.db.GetCounters(context.TODO())
+			return p.db.GetCounters(context.TODO(), p.freshSince())
 		},
 		RetryOptions...,
 	)
@@ -83,6 +101,45 @@ func (p *PGStorage) GetCounterList() []CounterListItem {
 	return ret
 }
 
+func (p *PGStorage) GetHistogramList() []HistogramListItem {
+	ret, err := retry.DoWithData(
+		func() ([]HistogramListItem, error) {
+			return p.db.GetHistograms(context.TODO())
+		},
+		RetryOptions...,
+	)
+	if err != nil {
+		logger.Info("error while query histograms:", err)
+	}
+	return ret
+}
+
+func (p *PGStorage) GetSummaryList() []SummaryListItem {
+	ret, err := retry.DoWithData(
+		func() ([]SummaryListItem, error) {
+			return p.db.GetSummaries(context.TODO())
+		},
+		RetryOptions...,
+	)
+	if err != nil {
+		logger.Info("error while query summaries:", err)
+	}
+	return ret
+}
+
+func (p *PGStorage) GetSetList() []SetListItem {
+	ret, err := retry.DoWithData(
+		func() ([]SetListItem, error) {
+			return p.db.GetSets(context.TODO())
+		},
+		RetryOptions...,
+	)
+	if err != nil {
+		logger.Info("error while query sets:", err)
+	}
+	return ret
+}
+
 func (p *PGStorage) GetGauge(name string) (float64, error) {
 	val, err := retry.DoWithData(
 		func() (float64, error) {
@@ -109,6 +166,45 @@ func (p *PGStorage) GetCounter(name string) (int64, error) {
 	return val, nil
 }
 
+func (p *PGStorage) GetHistogram(name string) (models.Histogram, error) {
+	val, err := retry.DoWithData(
+		func() (models.Histogram, error) {
+			return p.db.GetHistogram(context.TODO(), name)
+		},
+		RetryOptions...,
+	)
+	if err != nil {
+		return val, fmt.Errorf("failed to get histogram %s: %w", name, err)
+	}
+	return val, nil
+}
+
+func (p *PGStorage) GetSummary(name string) (models.Sketch, error) {
+	val, err := retry.DoWithData(
+		func() (models.Sketch, error) {
+			return p.db.GetSummary(context.TODO(), name)
+		},
+		RetryOptions...,
+	)
+	if err != nil {
+		return val, fmt.Errorf("failed to get summary %s: %w", name, err)
+	}
+	return val, nil
+}
+
+func (p *PGStorage) GetSet(name string) (models.HLL, error) {
+	val, err := retry.DoWithData(
+		func() (models.HLL, error) {
+			return p.db.GetSet(context.TODO(), name)
+		},
+		RetryOptions...,
+	)
+	if err != nil {
+		return val, fmt.Errorf("failed to get set %s: %w", name, err)
+	}
+	return val, nil
+}
+
 func (p *PGStorage) UpdateGauge(name string, value float64) {
 	err := retry.Do(
 		func() error {
@@ -133,6 +229,56 @@ func (p *PGStorage) IncrementCounter(name string, value int64) {
 	}
 }
 
+func (p *PGStorage) AddCounterTotal(name string, total int64) {
+	err := retry.Do(
+		func() error {
+			return p.db.AddCounterTotal(context.TODO(), name, total)
+		},
+		RetryOptions...,
+	)
+	if err != nil {
+		logger.Info("failed to update counter:", err)
+	}
+}
+
+func (p *PGStorage) UpdateHistogram(name string, delta *models.Histogram) error {
+	err := retry.Do(
+		func() error {
+			return p.db.UpdateHistogram(context.TODO(), name, delta)
+		},
+		RetryOptions...,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to update histogram: %w", err)
+	}
+	return nil
+}
+
+func (p *PGStorage) UpdateSummary(name string, s *models.Sketch) error {
+	err := retry.Do(
+		func() error {
+			return p.db.UpdateSummary(context.TODO(), name, s)
+		},
+		RetryOptions...,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to update summary: %w", err)
+	}
+	return nil
+}
+
+func (p *PGStorage) UpdateSet(name string, h *models.HLL) {
+	err := retry.Do(
+		func() error {
+			return p.db.UpdateSet(context.TODO(), name, h)
+		},
+		RetryOptions...,
+	)
+	if err != nil {
+		logger.Info("failed to update set:", err)
+	}
+}
+
 func (p *PGStorage) BulkUpdate(metrics models.MetricsSlice) {
 	err := retry.Do(
 		func() error {
@@ -144,3 +290,63 @@ func (p *PGStorage) BulkUpdate(metrics models.MetricsSlice) {
 		logger.Info("failed doing bulk update:", err)
 	}
 }
+
+func (p *PGStorage) BulkUpdateOnce(key string, seq int64, metrics models.MetricsSlice) (bool, error) {
+	applied, err := retry.DoWithData(
+		func() (bool, error) {
+			return p.db.BulkUpdateOnce(context.TODO(), key, seq, metrics)
+		},
+		RetryOptions...,
+	)
+	if err != nil {
+		return false, fmt.Errorf("failed doing bulk update: %w", err)
+	}
+	return applied, nil
+}
+
+func (p *PGStorage) ForgetBatches(before time.Time) error {
+	err := retry.Do(
+		func() error {
+			return p.db.ForgetBatches(context.TODO(), before)
+		},
+		RetryOptions...,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to forget batches: %w", err)
+	}
+	return nil
+}
+
+// SetHistoryPolicy задаёт политику хранения, по которой выбираются источники истории и идёт сжатие.
+func (p *PGStorage) SetHistoryPolicy(policy history.Policy) {
+	p.policy = policy
+}
+
+func (p *PGStorage) GetHistory(
+	kind, name string, from, to time.Time, step time.Duration,
+) ([]models.HistoryPoint, error) {
+	resolution := p.policy.Source(from, step, time.Now())
+	ret, err := retry.DoWithData(
+		func() ([]models.HistoryPoint, error) {
+			return p.db.GetHistory(context.TODO(), kind, name, from, to, step, resolution)
+		},
+		RetryOptions...,
+	)
+	if err != nil {
+		return ret, fmt.Errorf("failed to get history of %s %s: %w", kind, name, err)
+	}
+	return ret, nil
+}
+
+func (p *PGStorage) CompactHistory(since, now time.Time) error {
+	err := retry.Do(
+		func() error {
+			return p.db.CompactHistory(context.TODO(), p.policy, since, now)
+		},
+		RetryOptions...,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to compact history: %w", err)
+	}
+	return nil
+}
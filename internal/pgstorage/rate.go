@@ -0,0 +1,56 @@
+package pgstorage
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/NikolayStrekalov/vigilant-octo-waddle.git/internal/models"
+	"github.com/avast/retry-go/v4"
+)
+
+// sqlSelectCounterWindow выбирает сэмплы counter из (from, to] и последний сэмпл не позже from.
+const sqlSelectCounterWindow = `(
+	SELECT ts, value FROM samples
+	WHERE kind = 'counter' AND name = $1 AND labels = $2 AND ts <= $3
+	ORDER BY ts DESC LIMIT 1
+) UNION ALL (
+	SELECT ts, value FROM samples
+	WHERE kind = 'counter' AND name = $1 AND labels = $2 AND ts > $3 AND ts <= $4
+)
+ORDER BY ts;`
+
+func (db *DB) GetCounterSamples(ctx context.Context, key string, from, to time.Time) ([]models.HistoryPoint, error) {
+	name, labels := splitKey(key)
+	ret := []models.HistoryPoint{}
+	rows, err := db.pool.Query(ctx, sqlSelectCounterWindow, name, labels, from, to)
+	if err != nil {
+		return ret, fmt.Errorf("error fetching samples of counter %s: %w", key, err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var point models.HistoryPoint
+		if err := rows.Scan(&point.Time, &point.Value); err != nil {
+			return ret, fmt.Errorf("error reading samples of counter %s: %w", key, err)
+		}
+		ret = append(ret, point)
+	}
+	if err := rows.Err(); err != nil {
+		return ret, fmt.Errorf("error reading samples of counter %s: %w", key, err)
+	}
+	return ret, nil
+}
+
+// GetCounterSamples возвращает сырые сэмплы counter за окно (from, to] вместе с последним сэмплом до окна.
+func (p *PGStorage) GetCounterSamples(name string, from, to time.Time) ([]models.HistoryPoint, error) {
+	ret, err := retry.DoWithData(
+		func() ([]models.HistoryPoint, error) {
+			return p.db.GetCounterSamples(context.TODO(), name, from, to)
+		},
+		RetryOptions...,
+	)
+	if err != nil {
+		return ret, fmt.Errorf("failed to get samples of counter %s: %w", name, err)
+	}
+	return ret, nil
+}
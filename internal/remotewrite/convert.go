@@ -0,0 +1,107 @@
+package remotewrite
+
+import (
+	"math"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/NikolayStrekalov/vigilant-octo-waddle.git/internal/models"
+	"github.com/NikolayStrekalov/vigilant-octo-waddle.git/internal/series"
+)
+
+const counterSuffix = "_total"
+
+// SeriesLabels возвращает метки серии без __name__.
+func (ts *TimeSeries) SeriesLabels() map[string]string {
+	var labels map[string]string
+	for _, l := range ts.Labels {
+		if l.Name == nameLabel {
+			continue
+		}
+		if labels == nil {
+			labels = make(map[string]string, len(ts.Labels))
+		}
+		labels[l.Name] = l.Value
+	}
+	return labels
+}
+
+// ToMetrics переводит серии в обновления метрик, метки кроме __name__ становятся метками серии.
+// Для gauge берётся последнее значение серии. Счётчики передаются накопительным значением Total,
+// сервер сам хранит прошлое значение и считает рост, поэтому перезапуск сервера не удваивает счётчик.
+// Если внутри серии значение уменьшилось, значение перед сбросом тоже передаётся, чтобы рост до сброса
+// не потерялся. Счётчиком считается серия, для которой метаданные указывают counter, или с суффиксом _total.
+// Серии с недопустимыми именами меток или значениями не в UTF-8 пропускаются, их число возвращается вторым.
+func ToMetrics(req *WriteRequest) (models.MetricsSlice, int) {
+	ret := models.MetricsSlice{}
+	dropped := 0
+	index := make(map[[2]string]int)
+	for i := range req.Timeseries {
+		ts := &req.Timeseries[i]
+		name := ts.Name()
+		if name == "" {
+			continue
+		}
+		labels := ts.SeriesLabels()
+		if !validLabels(labels) {
+			dropped++
+			continue
+		}
+		if t := req.Types[name]; t == TypeCounter || (t == TypeUnknown && strings.HasSuffix(name, counterSuffix)) {
+			ret = append(ret, counterTotals(name, labels, ts.Samples)...)
+			continue
+		}
+		key := series.Key(name, labels)
+		for _, s := range ts.Samples {
+			if math.IsNaN(s.Value) {
+				// staleness marker или пропуск
+				continue
+			}
+			j, ok := index[[2]string{"gauge", key}]
+			if !ok {
+				j = len(ret)
+				index[[2]string{"gauge", key}] = j
+				ret = append(ret, models.Metrics{ID: name, MType: "gauge", Labels: labels})
+			}
+			v := s.Value
+			ret[j].Value = &v
+		}
+	}
+	return ret, dropped
+}
+
+// validLabels проверяет имена меток как у остальных протоколов, значения должны быть в UTF-8.
+func validLabels(labels map[string]string) bool {
+	if series.Validate(labels) != nil {
+		return false
+	}
+	for _, v := range labels {
+		if !utf8.ValidString(v) {
+			return false
+		}
+	}
+	return true
+}
+
+// counterTotals возвращает накопительные значения счётчика: последнее и каждое перед уменьшением.
+// Дробные значения округляются вниз.
+func counterTotals(name string, labels map[string]string, samples []Sample) models.MetricsSlice {
+	var (
+		ret  models.MetricsSlice
+		last *int64
+	)
+	for _, s := range samples {
+		if math.IsNaN(s.Value) || math.IsInf(s.Value, 0) || s.Value < 0 {
+			continue
+		}
+		total := int64(math.Floor(s.Value))
+		if last != nil && total < *last {
+			ret = append(ret, models.Metrics{ID: name, MType: "counter", Labels: labels, Total: last})
+		}
+		last = &total
+	}
+	if last != nil {
+		ret = append(ret, models.Metrics{ID: name, MType: "counter", Labels: labels, Total: last})
+	}
+	return ret
+}
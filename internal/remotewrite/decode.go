@@ -0,0 +1,202 @@
+// Package remotewrite разбирает запросы Prometheus remote_write (protobuf, сжатый snappy).
+package remotewrite
+
+import (
+	"errors"
+	"fmt"
+	"math"
+
+	"github.com/golang/snappy"
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+// Тип метрики из метаданных WriteRequest.
+const (
+	TypeUnknown = 0
+	TypeCounter = 1
+	TypeGauge   = 2
+)
+
+// Номера полей prometheus.WriteRequest и вложенных сообщений.
+const (
+	fieldTimeseries     = 1
+	fieldMetadata       = 3
+	fieldSeriesLabels   = 1
+	fieldSeriesSamples  = 2
+	fieldLabelName      = 1
+	fieldLabelValue     = 2
+	fieldSampleValue    = 1
+	fieldSampleTime     = 2
+	fieldMetadataType   = 1
+	fieldMetadataFamily = 2
+)
+
+// nameLabel — метка с именем метрики.
+const nameLabel = "__name__"
+
+var errWrongWireType = errors.New("unexpected wire type")
+
+type Label struct {
+	Name  string
+	Value string
+}
+
+type Sample struct {
+	Value     float64
+	Timestamp int64 // миллисекунды
+}
+
+type TimeSeries struct {
+	Labels  []Label
+	Samples []Sample
+}
+
+// Name возвращает значение метки __name__.
+func (ts *TimeSeries) Name() string {
+	for _, l := range ts.Labels {
+		if l.Name == nameLabel {
+			return l.Value
+		}
+	}
+	return ""
+}
+
+type WriteRequest struct {
+	Types      map[string]int // тип по имени семейства из метаданных
+	Timeseries []TimeSeries
+}
+
+// Decode распаковывает snappy и разбирает WriteRequest.
+func Decode(compressed []byte) (WriteRequest, error) {
+	data, err := snappy.Decode(nil, compressed)
+	if err != nil {
+		return WriteRequest{}, fmt.Errorf("snappy decode error: %w", err)
+	}
+	return Unmarshal(data)
+}
+
+// Unmarshal разбирает WriteRequest, неизвестные поля пропускаются.
+func Unmarshal(b []byte) (WriteRequest, error) {
+	req := WriteRequest{Types: make(map[string]int)}
+	err := walk(b, func(f field) error {
+		switch f.num {
+		case fieldTimeseries:
+			ts, err := unmarshalSeries(f.bytes)
+			if err != nil {
+				return err
+			}
+			req.Timeseries = append(req.Timeseries, ts)
+		case fieldMetadata:
+			family, kind, err := unmarshalMetadata(f.bytes)
+			if err != nil {
+				return err
+			}
+			req.Types[family] = kind
+		}
+		return nil
+	})
+	return req, err
+}
+
+func unmarshalSeries(b []byte) (TimeSeries, error) {
+	var ts TimeSeries
+	err := walk(b, func(f field) error {
+		switch f.num {
+		case fieldSeriesLabels:
+			var l Label
+			err := walk(f.bytes, func(f field) error {
+				switch f.num {
+				case fieldLabelName:
+					l.Name = string(f.bytes)
+				case fieldLabelValue:
+					l.Value = string(f.bytes)
+				}
+				return nil
+			})
+			if err != nil {
+				return err
+			}
+			ts.Labels = append(ts.Labels, l)
+		case fieldSeriesSamples:
+			var s Sample
+			err := walk(f.bytes, func(f field) error {
+				switch f.num {
+				case fieldSampleValue:
+					if f.typ != protowire.Fixed64Type {
+						return errWrongWireType
+					}
+					s.Value = math.Float64frombits(f.scalar)
+				case fieldSampleTime:
+					if f.typ != protowire.VarintType {
+						return errWrongWireType
+					}
+					s.Timestamp = int64(f.scalar)
+				}
+				return nil
+			})
+			if err != nil {
+				return err
+			}
+			ts.Samples = append(ts.Samples, s)
+		}
+		return nil
+	})
+	return ts, err
+}
+
+func unmarshalMetadata(b []byte) (family string, kind int, err error) {
+	err = walk(b, func(f field) error {
+		switch f.num {
+		case fieldMetadataType:
+			if f.typ != protowire.VarintType {
+				return errWrongWireType
+			}
+			kind = int(f.scalar)
+		case fieldMetadataFamily:
+			family = string(f.bytes)
+		}
+		return nil
+	})
+	return family, kind, err
+}
+
+type field struct {
+	bytes  []byte // значение поля типа bytes
+	scalar uint64 // значение varint и fixed-полей
+	num    protowire.Number
+	typ    protowire.Type
+}
+
+// walk вызывает fn для каждого поля сообщения.
+func walk(b []byte, fn func(f field) error) error {
+	for len(b) > 0 {
+		num, typ, n := protowire.ConsumeTag(b)
+		if n < 0 {
+			return fmt.Errorf("protobuf decode error: %w", protowire.ParseError(n))
+		}
+		b = b[n:]
+		f := field{num: num, typ: typ}
+		switch typ {
+		case protowire.VarintType:
+			f.scalar, n = protowire.ConsumeVarint(b)
+		case protowire.Fixed64Type:
+			f.scalar, n = protowire.ConsumeFixed64(b)
+		case protowire.Fixed32Type:
+			var v uint32
+			v, n = protowire.ConsumeFixed32(b)
+			f.scalar = uint64(v)
+		case protowire.BytesType:
+			f.bytes, n = protowire.ConsumeBytes(b)
+		default:
+			n = protowire.ConsumeFieldValue(num, typ, b)
+		}
+		if n < 0 {
+			return fmt.Errorf("protobuf decode error: %w", protowire.ParseError(n))
+		}
+		b = b[n:]
+		if err := fn(f); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,106 @@
+package remotewrite
+
+import (
+	"math"
+	"testing"
+
+	"github.com/NikolayStrekalov/vigilant-octo-waddle.git/internal/models"
+	"github.com/golang/snappy"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+func appendSeries(b []byte, ts *TimeSeries) []byte {
+	var msg []byte
+	for _, l := range ts.Labels {
+		var lb []byte
+		lb = protowire.AppendTag(lb, fieldLabelName, protowire.BytesType)
+		lb = protowire.AppendString(lb, l.Name)
+		lb = protowire.AppendTag(lb, fieldLabelValue, protowire.BytesType)
+		lb = protowire.AppendString(lb, l.Value)
+		msg = protowire.AppendTag(msg, fieldSeriesLabels, protowire.BytesType)
+		msg = protowire.AppendBytes(msg, lb)
+	}
+	for _, s := range ts.Samples {
+		var sb []byte
+		sb = protowire.AppendTag(sb, fieldSampleValue, protowire.Fixed64Type)
+		sb = protowire.AppendFixed64(sb, math.Float64bits(s.Value))
+		sb = protowire.AppendTag(sb, fieldSampleTime, protowire.VarintType)
+		sb = protowire.AppendVarint(sb, uint64(s.Timestamp))
+		msg = protowire.AppendTag(msg, fieldSeriesSamples, protowire.BytesType)
+		msg = protowire.AppendBytes(msg, sb)
+	}
+	b = protowire.AppendTag(b, fieldTimeseries, protowire.BytesType)
+	return protowire.AppendBytes(b, msg)
+}
+
+func appendMetadata(b []byte, family string, kind int) []byte {
+	var msg []byte
+	msg = protowire.AppendTag(msg, fieldMetadataType, protowire.VarintType)
+	msg = protowire.AppendVarint(msg, uint64(kind))
+	msg = protowire.AppendTag(msg, fieldMetadataFamily, protowire.BytesType)
+	msg = protowire.AppendString(msg, family)
+	b = protowire.AppendTag(b, fieldMetadata, protowire.BytesType)
+	return protowire.AppendBytes(b, msg)
+}
+
+func timeSeries(name, instance string, values ...float64) TimeSeries {
+	ts := TimeSeries{Labels: []Label{{Name: "__name__", Value: name}, {Name: "instance", Value: instance}}}
+	for i, v := range values {
+		ts.Samples = append(ts.Samples, Sample{Value: v, Timestamp: 1717243200000 + int64(i)*1000})
+	}
+	return ts
+}
+
+func TestDecode(t *testing.T) {
+	heap := timeSeries("go_memstats_heap_alloc_bytes", "a", 1024, 2048)
+	requests := timeSeries("http_requests_total", "a", 10, 15)
+	var b []byte
+	b = appendSeries(b, &heap)
+	b = appendSeries(b, &requests)
+	b = appendMetadata(b, "go_memstats_heap_alloc_bytes", TypeGauge)
+
+	got, err := Decode(snappy.Encode(nil, b))
+	assert.Nil(t, err)
+	assert.Equal(t, WriteRequest{
+		Types:      map[string]int{"go_memstats_heap_alloc_bytes": TypeGauge},
+		Timeseries: []TimeSeries{heap, requests},
+	}, got)
+
+	_, err = Decode(b)
+	assert.Error(t, err)
+	_, err = Decode(snappy.Encode(nil, b[:len(b)-1]))
+	assert.Error(t, err)
+}
+
+func TestToMetrics(t *testing.T) {
+	req := WriteRequest{
+		Types: map[string]int{"process_cpu_seconds": TypeCounter, "up_total": TypeGauge},
+		Timeseries: []TimeSeries{
+			timeSeries("http_requests_total", "a", 10, 15),
+			timeSeries("http_requests_total", "b", 7, 2, 4),
+			timeSeries("process_cpu_seconds", "a", 3.5),
+			timeSeries("up_total", "a", 1),
+			timeSeries("go_goroutines", "a", 12, math.NaN(), 14),
+			{Samples: []Sample{{Value: 1}}},
+			timeSeries("go_threads", "\xff", 1),
+			{
+				Labels:  []Label{{Name: "__name__", Value: "go_threads"}, {Name: "pod-name", Value: "a"}},
+				Samples: []Sample{{Value: 1}},
+			},
+		},
+	}
+	int64p := func(v int64) *int64 { return &v }
+	float64p := func(v float64) *float64 { return &v }
+	a, b := map[string]string{"instance": "a"}, map[string]string{"instance": "b"}
+	metrics, dropped := ToMetrics(&req)
+	assert.Equal(t, models.MetricsSlice{
+		{ID: "http_requests_total", MType: "counter", Total: int64p(15), Labels: a},
+		{ID: "http_requests_total", MType: "counter", Total: int64p(7), Labels: b},
+		{ID: "http_requests_total", MType: "counter", Total: int64p(4), Labels: b},
+		{ID: "process_cpu_seconds", MType: "counter", Total: int64p(3), Labels: a},
+		{ID: "up_total", MType: "gauge", Value: float64p(1), Labels: a},
+		{ID: "go_goroutines", MType: "gauge", Value: float64p(14), Labels: a},
+	}, metrics)
+	assert.Equal(t, 2, dropped, "series with wrong labels are dropped")
+}
@@ -0,0 +1,154 @@
+// Package series строит идентификатор серии из имени метрики и её меток.
+//
+// Серия без меток называется просто по имени, поэтому данные, записанные до появления меток,
+// остаются той же серией. С метками ключ записывается как в Prometheus: name{a="1",b="2"},
+// метки отсортированы, так что одинаковый набор меток всегда даёт один и тот же ключ.
+package series
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+var errWrongLabel = errors.New("label name must match [a-zA-Z_][a-zA-Z0-9_]*")
+
+var (
+	escaper   = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+	unescaper = strings.NewReplacer(`\\`, `\`, `\"`, `"`, `\n`, "\n")
+)
+
+// Key возвращает ключ серии.
+func Key(name string, labels map[string]string) string {
+	if len(labels) == 0 {
+		return name
+	}
+	keys := make([]string, 0, len(labels))
+	for k := range labels {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	var b strings.Builder
+	b.WriteString(name)
+	b.WriteByte('{')
+	for i, k := range keys {
+		if i > 0 {
+			b.WriteByte(',')
+		}
+		b.WriteString(k)
+		b.WriteString(`="`)
+		b.WriteString(escaper.Replace(labels[k]))
+		b.WriteByte('"')
+	}
+	b.WriteByte('}')
+	return b.String()
+}
+
+// Parse разбирает ключ серии на имя и метки. Ключ, который не разбирается, считается именем без меток.
+func Parse(key string) (string, map[string]string) {
+	start := strings.IndexByte(key, '{')
+	if start < 0 || !strings.HasSuffix(key, "}") {
+		return key, nil
+	}
+	name, rest := key[:start], key[start+1:len(key)-1]
+	labels := make(map[string]string)
+	for rest != "" {
+		eq := strings.Index(rest, `="`)
+		if eq <= 0 || !validName(rest[:eq]) {
+			return key, nil
+		}
+		label := rest[:eq]
+		rest = rest[eq+2:]
+		end := closingQuote(rest)
+		if end < 0 {
+			return key, nil
+		}
+		labels[label] = unescaper.Replace(rest[:end])
+		rest = rest[end+1:]
+		if rest != "" {
+			if rest[0] != ',' {
+				return key, nil
+			}
+			rest = rest[1:]
+		}
+	}
+	return name, labels
+}
+
+// closingQuote ищет закрывающую неэкранированную кавычку значения.
+func closingQuote(s string) int {
+	for i := 0; i < len(s); i++ {
+		switch s[i] {
+		case '\\':
+			i++
+		case '"':
+			return i
+		}
+	}
+	return -1
+}
+
+// Name возвращает имя метрики из ключа серии.
+func Name(key string) string {
+	name, _ := Parse(key)
+	return name
+}
+
+// Validate проверяет имена меток, полученных от клиентов.
+func Validate(labels map[string]string) error {
+	for k := range labels {
+		if !validName(k) {
+			return fmt.Errorf("label %q: %w", k, errWrongLabel)
+		}
+	}
+	return nil
+}
+
+// Sanitize приводит имя метки из внешнего протокола (атрибуты OTLP, теги InfluxDB)
+// к допустимому виду, недопустимые символы заменяются на _.
+func Sanitize(label string) string {
+	var b strings.Builder
+	for i := 0; i < len(label); i++ {
+		c := label[i]
+		if nameChar(c) && !(i == 0 && c >= '0' && c <= '9') {
+			b.WriteByte(c)
+		} else {
+			b.WriteByte('_')
+		}
+	}
+	if b.Len() == 0 {
+		return "_"
+	}
+	return b.String()
+}
+
+// Match сообщает, есть ли у серии все метки selector с теми же значениями.
+func Match(key string, selector map[string]string) bool {
+	if len(selector) == 0 {
+		return true
+	}
+	_, labels := Parse(key)
+	for k, v := range selector {
+		if got, ok := labels[k]; !ok || got != v {
+			return false
+		}
+	}
+	return true
+}
+
+func validName(s string) bool {
+	if s == "" || (s[0] >= '0' && s[0] <= '9') {
+		return false
+	}
+	for i := 0; i < len(s); i++ {
+		if !nameChar(s[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+func nameChar(c byte) bool {
+	return c == '_' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') || (c >= '0' && c <= '9')
+}
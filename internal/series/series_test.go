@@ -0,0 +1,73 @@
+package series
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestKeyParse(t *testing.T) {
+	tests := []struct {
+		labels map[string]string
+		name   string
+		key    string
+	}{
+		{name: "FreeMemory", key: "FreeMemory"},
+		{name: "FreeMemory", labels: map[string]string{"host": "a"}, key: `FreeMemory{host="a"}`},
+		{
+			name:   "requests",
+			labels: map[string]string{"path": "/", "code": "200"},
+			key:    `requests{code="200",path="/"}`,
+		},
+		{
+			name:   "log",
+			labels: map[string]string{"msg": "say \"hi\"\n\\"},
+			key:    `log{msg="say \"hi\"\n\\"}`,
+		},
+		{name: "empty", labels: map[string]string{"v": ""}, key: `empty{v=""}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.key, func(t *testing.T) {
+			assert.Equal(t, tt.key, Key(tt.name, tt.labels))
+			name, labels := Parse(tt.key)
+			assert.Equal(t, tt.name, name)
+			if len(tt.labels) == 0 {
+				assert.Empty(t, labels)
+			} else {
+				assert.Equal(t, tt.labels, labels)
+			}
+		})
+	}
+}
+
+func TestParse_Unparsable(t *testing.T) {
+	for _, key := range []string{`odd{`, `odd{host}`, `odd{host="a"`, `odd{1x="a"}`, `odd{a="1"b="2"}`} {
+		name, labels := Parse(key)
+		assert.Equal(t, key, name)
+		assert.Nil(t, labels)
+	}
+}
+
+func TestValidate(t *testing.T) {
+	assert.NoError(t, Validate(nil))
+	assert.NoError(t, Validate(map[string]string{"host": "a", "_x1": "b"}))
+	assert.Error(t, Validate(map[string]string{"1host": "a"}))
+	assert.Error(t, Validate(map[string]string{"service.name": "a"}))
+	assert.Error(t, Validate(map[string]string{"": "a"}))
+}
+
+func TestSanitize(t *testing.T) {
+	assert.Equal(t, "service_name", Sanitize("service.name"))
+	assert.Equal(t, "_xx", Sanitize("1xx"))
+	assert.Equal(t, "_", Sanitize(""))
+	assert.Equal(t, "host", Sanitize("host"))
+}
+
+func TestMatch(t *testing.T) {
+	key := Key("FreeMemory", map[string]string{"host": "a", "dc": "eu"})
+	assert.True(t, Match(key, nil))
+	assert.True(t, Match(key, map[string]string{"host": "a"}))
+	assert.True(t, Match(key, map[string]string{"host": "a", "dc": "eu"}))
+	assert.False(t, Match(key, map[string]string{"host": "b"}))
+	assert.False(t, Match("FreeMemory", map[string]string{"host": "a"}))
+}
@@ -0,0 +1,76 @@
+package server
+
+import (
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/NikolayStrekalov/vigilant-octo-waddle.git/internal/absence"
+	"github.com/NikolayStrekalov/vigilant-octo-waddle.git/internal/alerting"
+	"github.com/NikolayStrekalov/vigilant-octo-waddle.git/internal/models"
+)
+
+var Absence *absence.Tracker
+
+// startAbsence запускает отслеживание метрик и агентов, переставших присылать данные.
+// Метрики, уже лежащие в хранилище, считаются обновлёнными в момент запуска.
+func startAbsence() {
+	interval := time.Duration(ServerConfig.ReportInterval) * time.Second
+	Absence = absence.NewTracker(interval * time.Duration(ServerConfig.AbsenceIntervals))
+	if urls := webhookURLs(ServerConfig.WebhookURLs); len(urls) > 0 {
+		Absence.SetNotifier(alerting.NewWebhook(urls, ServerConfig.SignKey))
+	}
+	if Storage != nil {
+		now := time.Now()
+		for _, g := range Storage.GetGaugeList() {
+			Absence.Seen(gaugeKind, g.Name, now)
+		}
+		for _, c := range Storage.GetCounterList() {
+			Absence.Seen(counterKind, c.Name, now)
+		}
+	}
+	go Absence.Run(interval)
+}
+
+// markSeen отмечает обновление метрики и агента, от которого оно пришло, если агент известен.
+func markSeen(agent, kind, name string) {
+	if Absence == nil {
+		return
+	}
+	now := time.Now()
+	Absence.Seen(kind, name, now)
+	if agent != "" {
+		Absence.Seen(absence.KindAgent, agent, now)
+	}
+}
+
+// agentName определяет агента для отслеживания пропаж так же, как реестр агентов, — по заголовку Agent-Id.
+// Если агент его не передал, например при приёме OTLP или Influx, агент определяется по адресу запроса.
+func agentName(req *http.Request) string {
+	return agentIDOr(req.Header.Get(AgentIDHeader), agentAddress(req))
+}
+
+// agentIDOr возвращает идентификатор агента id, а если он пуст или слишком длинный — адрес address.
+func agentIDOr(id, address string) string {
+	if id == "" || len(id) > maxAgentFieldLength {
+		return address
+	}
+	return id
+}
+
+// agentAddress возвращает адрес, с которого пришёл запрос.
+func agentAddress(req *http.Request) string {
+	host, _, err := net.SplitHostPort(req.RemoteAddr)
+	if err != nil {
+		return req.RemoteAddr
+	}
+	return host
+}
+
+func absentHandler(res http.ResponseWriter, req *http.Request) {
+	absences := models.Absences{}
+	if Absence != nil {
+		absences = Absence.Absences()
+	}
+	writeJSON(res, http.StatusOK, absences)
+}
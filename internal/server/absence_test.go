@@ -0,0 +1,77 @@
+package server
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/NikolayStrekalov/vigilant-octo-waddle.git/internal/absence"
+	"github.com/NikolayStrekalov/vigilant-octo-waddle.git/internal/memstorage"
+	"github.com/NikolayStrekalov/vigilant-octo-waddle.git/internal/models"
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_absentHandler(t *testing.T) {
+	Storage, _, _ = memstorage.NewMemStorage("", false, 300)
+	Absence = absence.NewTracker(time.Minute)
+	defer func() { Absence = nil }()
+	r := chi.NewRouter()
+	prepareRoutes(r)
+	post := func() {
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "http://localhost:8080/update/gauge/Alloc/1", http.NoBody))
+		_ = w.Result().Body.Close()
+	}
+	absent := func() models.Absences {
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "http://localhost:8080/api/absent", http.NoBody))
+		res := w.Result()
+		defer func() {
+			_ = res.Body.Close()
+		}()
+		require.Equal(t, http.StatusOK, res.StatusCode)
+		data, err := io.ReadAll(res.Body)
+		require.NoError(t, err)
+		ret := models.Absences{}
+		require.NoError(t, ret.UnmarshalJSON(data))
+		return ret
+	}
+
+	post()
+	assert.Empty(t, absent())
+	Absence.Check(time.Now().Add(2 * time.Minute))
+	got := absent()
+	if assert.Len(t, got, 2) {
+		// httptest.NewRequest проставляет адрес 192.0.2.1
+		assert.Equal(t, absence.KindAgent, got[0].Kind)
+		assert.Equal(t, "192.0.2.1", got[0].Name)
+		assert.Equal(t, gaugeKind, got[1].Kind)
+		assert.Equal(t, "Alloc", got[1].Name)
+	}
+	html, err := renderIndexPage(&templateArgs{Gauge: Storage.GetGaugeList()})
+	require.NoError(t, err)
+	assert.Contains(t, html.String(), "<li>gauge Alloc 1 (absent)</li>")
+	assert.Contains(t, html.String(), "<li>agent 192.0.2.1 (absent)</li>")
+
+	post()
+	assert.Empty(t, absent())
+
+	// агент с заголовком Agent-Id отслеживается по нему, как в реестре агентов, а не по адресу
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodPost, "http://localhost:8080/update/gauge/Alloc/2", http.NoBody)
+	req.Header.Set(AgentIDHeader, "web-1")
+	r.ServeHTTP(w, req)
+	_ = w.Result().Body.Close()
+	Absence.Check(time.Now().Add(2 * time.Minute))
+	got = absent()
+	if assert.Len(t, got, 3) {
+		assert.Equal(t, absence.KindAgent, got[0].Kind)
+		assert.Equal(t, "192.0.2.1", got[0].Name)
+		assert.Equal(t, absence.KindAgent, got[1].Kind)
+		assert.Equal(t, "web-1", got[1].Name)
+	}
+}
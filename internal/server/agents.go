@@ -0,0 +1,59 @@
+package server
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/NikolayStrekalov/vigilant-octo-waddle.git/internal/logger"
+	"github.com/NikolayStrekalov/vigilant-octo-waddle.git/internal/models"
+)
+
+// Заголовки, которыми агент сообщает о себе в каждой пачке /updates/, по gRPC — одноимённые метаданные.
+const (
+	AgentIDHeader       = "Agent-Id"
+	AgentHostnameHeader = "Agent-Hostname"
+	AgentPlatformHeader = "Agent-Platform"
+	AgentVersionHeader  = "Agent-Version"
+	maxAgentFieldLength = 200
+)
+
+var errWrongAgent = errors.New("agent fields must not be longer than 200 bytes")
+
+// agentInfo читает сведения об агенте функцией get по имени заголовка.
+// Пустой ID означает, что агент себя не назвал.
+func agentInfo(get func(string) string) (models.Agent, error) {
+	agent := models.Agent{
+		ID:       get(AgentIDHeader),
+		Hostname: get(AgentHostnameHeader),
+		Platform: get(AgentPlatformHeader),
+		Version:  get(AgentVersionHeader),
+	}
+	for _, f := range []string{agent.ID, agent.Hostname, agent.Platform, agent.Version} {
+		if len(f) > maxAgentFieldLength {
+			return agent, errWrongAgent
+		}
+	}
+	return agent, nil
+}
+
+// registerAgent отмечает в реестре пачку из count метрик, пришедшую от агента с адреса address.
+func registerAgent(agent *models.Agent, address string, count int) {
+	if agent.ID == "" {
+		return
+	}
+	now := time.Now()
+	agent.Address, agent.Metrics, agent.FirstSeen, agent.LastSeen = address, count, now, now
+	if err := Storage.SeenAgent(agent); err != nil {
+		logger.Info("error registering agent:", err)
+	}
+}
+
+func agentsHandler(res http.ResponseWriter, req *http.Request) {
+	agents, err := Storage.GetAgents()
+	if err != nil {
+		http.Error(res, messageInternalServerError, http.StatusInternalServerError)
+		return
+	}
+	writeJSON(res, http.StatusOK, models.Agents(agents))
+}
@@ -0,0 +1,76 @@
+package server
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/NikolayStrekalov/vigilant-octo-waddle.git/internal/logger"
+	"github.com/NikolayStrekalov/vigilant-octo-waddle.git/internal/memstorage"
+	"github.com/NikolayStrekalov/vigilant-octo-waddle.git/internal/models"
+	"github.com/go-chi/chi/v5"
+	"github.com/mailru/easyjson"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_agentsHandler(t *testing.T) {
+	_ = logger.InitLog()
+	storage, _, _ := memstorage.NewMemStorage("", false, 300)
+	Storage = storage
+	r := chi.NewRouter()
+	prepareRoutes(r)
+
+	do := func(method, path, body string, header map[string]string) (int, string) {
+		req := httptest.NewRequest(method, "http://localhost:8080"+path, strings.NewReader(body))
+		req.Header.Set("Content-Type", applicationJSONType)
+		for k, v := range header {
+			req.Header.Set(k, v)
+		}
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		res := w.Result()
+		defer func() {
+			_ = res.Body.Close()
+		}()
+		data, err := io.ReadAll(res.Body)
+		require.NoError(t, err)
+		return res.StatusCode, string(data)
+	}
+
+	header := map[string]string{
+		AgentIDHeader:       "web-1",
+		AgentHostnameHeader: "web-1.local",
+		AgentPlatformHeader: "linux/amd64",
+		AgentVersionHeader:  "1.0.0",
+	}
+	status, _ := do(http.MethodPost, "/updates/", `[{"id":"A","type":"gauge","value":1}]`, header)
+	assert.Equal(t, http.StatusOK, status)
+	header[AgentVersionHeader] = "1.1.0"
+	two := `[{"id":"A","type":"gauge","value":1},{"id":"B","type":"gauge","value":2}]`
+	status, _ = do(http.MethodPost, "/updates/", two, header)
+	assert.Equal(t, http.StatusOK, status)
+	status, _ = do(http.MethodPost, "/updates/", `[{"id":"A","type":"gauge","value":1}]`, nil)
+	assert.Equal(t, http.StatusOK, status, "batches without agent id are accepted")
+	status, _ = do(http.MethodPost, "/updates/", `[]`, map[string]string{AgentIDHeader: strings.Repeat("a", 201)})
+	assert.Equal(t, http.StatusBadRequest, status)
+
+	status, body := do(http.MethodGet, "/api/agents", "", nil)
+	assert.Equal(t, http.StatusOK, status)
+	agents := models.Agents{}
+	require.NoError(t, easyjson.Unmarshal([]byte(body), &agents))
+	if assert.Len(t, agents, 1) {
+		assert.Equal(t, "web-1", agents[0].ID)
+		assert.Equal(t, "web-1.local", agents[0].Hostname)
+		assert.Equal(t, "linux/amd64", agents[0].Platform)
+		assert.Equal(t, "1.1.0", agents[0].Version)
+		assert.Equal(t, "192.0.2.1", agents[0].Address)
+		assert.Equal(t, 2, agents[0].Metrics)
+		assert.True(t, agents[0].FirstSeen.Before(agents[0].LastSeen))
+	}
+
+	_, page := do(http.MethodGet, "/", "", nil)
+	assert.Contains(t, page, "<li>agent web-1 host=web-1.local version=1.1.0 address=192.0.2.1 metrics=2 lastSeen=")
+}
@@ -0,0 +1,193 @@
+package server
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/NikolayStrekalov/vigilant-octo-waddle.git/internal/alerting"
+	"github.com/NikolayStrekalov/vigilant-octo-waddle.git/internal/logger"
+	"github.com/NikolayStrekalov/vigilant-octo-waddle.git/internal/models"
+	"github.com/go-chi/chi/v5"
+	"github.com/mailru/easyjson"
+)
+
+const alertRuleNotFound = "Alert rule not found!"
+
+var Alerts *alerting.Engine
+
+// fileRules — правила из файла конфигурации, они не меняются через API.
+var fileRules []alerting.Rule
+
+// startAlerting загружает правила из файла и хранилища и запускает их периодическую проверку.
+func startAlerting() error {
+	if ServerConfig.AlertRulesFile != "" {
+		data, err := os.ReadFile(ServerConfig.AlertRulesFile)
+		if err != nil {
+			return fmt.Errorf("can't read alert rules: %w", err)
+		}
+		if fileRules, err = alerting.ParseRules(string(data)); err != nil {
+			return fmt.Errorf("can't parse alert rules: %w", err)
+		}
+	}
+	Alerts = alerting.NewEngine(Storage, fileRules)
+	if urls := webhookURLs(ServerConfig.WebhookURLs); len(urls) > 0 {
+		Alerts.SetNotifier(alerting.NewWebhook(urls, ServerConfig.SignKey))
+	}
+	reloadAlertRules()
+	go Alerts.Run(time.Duration(ServerConfig.AlertInterval) * time.Second)
+	return nil
+}
+
+// webhookURLs разбирает список адресов через запятую.
+func webhookURLs(v string) []string {
+	var urls []string
+	for _, u := range strings.Split(v, ",") {
+		if u = strings.TrimSpace(u); u != "" {
+			urls = append(urls, u)
+		}
+	}
+	return urls
+}
+
+// reloadAlertRules передаёт движку правила из файла и из хранилища.
+func reloadAlertRules() {
+	if Alerts == nil || Storage == nil {
+		return
+	}
+	stored, err := Storage.GetAlertRules()
+	if err != nil {
+		logger.Info("can't load alert rules:", err)
+		return
+	}
+	rules := make([]alerting.Rule, len(fileRules), len(fileRules)+len(stored))
+	copy(rules, fileRules)
+	for i := range stored {
+		rule, err := alerting.FromModel(&stored[i])
+		if err != nil {
+			logger.Info("skip invalid alert rule:", err)
+			continue
+		}
+		rules = append(rules, rule)
+	}
+	Alerts.SetRules(rules)
+}
+
+func alertsHandler(res http.ResponseWriter, req *http.Request) {
+	statuses := models.AlertStatuses{}
+	if Alerts != nil {
+		statuses = Alerts.Statuses()
+	}
+	writeJSON(res, http.StatusOK, statuses)
+}
+
+func alertRulesHandler(res http.ResponseWriter, req *http.Request) {
+	rules, err := Storage.GetAlertRules()
+	if err != nil {
+		http.Error(res, messageInternalServerError, http.StatusInternalServerError)
+		return
+	}
+	writeJSON(res, http.StatusOK, models.AlertRules(rules))
+}
+
+func alertRuleHandler(res http.ResponseWriter, req *http.Request) {
+	id, err := strconv.ParseInt(chi.URLParam(req, "id"), 10, 64)
+	if err != nil {
+		http.Error(res, alertRuleNotFound, http.StatusNotFound)
+		return
+	}
+	rule, err := Storage.GetAlertRule(id)
+	if err != nil {
+		http.Error(res, alertRuleNotFound, http.StatusNotFound)
+		return
+	}
+	writeJSON(res, http.StatusOK, &rule)
+}
+
+func addAlertRuleHandler(res http.ResponseWriter, req *http.Request) {
+	rule, ok := readAlertRule(res, req)
+	if !ok {
+		return
+	}
+	added, err := Storage.AddAlertRule(&rule)
+	if err != nil {
+		http.Error(res, messageInternalServerError, http.StatusInternalServerError)
+		return
+	}
+	reloadAlertRules()
+	writeJSON(res, http.StatusCreated, &added)
+}
+
+func updateAlertRuleHandler(res http.ResponseWriter, req *http.Request) {
+	id, err := strconv.ParseInt(chi.URLParam(req, "id"), 10, 64)
+	if err != nil {
+		http.Error(res, alertRuleNotFound, http.StatusNotFound)
+		return
+	}
+	rule, ok := readAlertRule(res, req)
+	if !ok {
+		return
+	}
+	rule.ID = id
+	if err := Storage.UpdateAlertRule(&rule); err != nil {
+		http.Error(res, alertRuleNotFound, http.StatusNotFound)
+		return
+	}
+	reloadAlertRules()
+	writeJSON(res, http.StatusOK, &rule)
+}
+
+func deleteAlertRuleHandler(res http.ResponseWriter, req *http.Request) {
+	id, err := strconv.ParseInt(chi.URLParam(req, "id"), 10, 64)
+	if err != nil {
+		http.Error(res, alertRuleNotFound, http.StatusNotFound)
+		return
+	}
+	if err := Storage.DeleteAlertRule(id); err != nil {
+		http.Error(res, alertRuleNotFound, http.StatusNotFound)
+		return
+	}
+	reloadAlertRules()
+	res.WriteHeader(http.StatusNoContent)
+}
+
+// readAlertRule читает правило из тела запроса и проверяет его, при ошибке отвечает клиенту сам.
+func readAlertRule(res http.ResponseWriter, req *http.Request) (models.AlertRule, bool) {
+	rule := models.AlertRule{}
+	if val, ok := req.Header["Content-Type"]; !ok || val[0] != applicationJSONType {
+		http.Error(res, "Wrong Content-Type, use application/json!", http.StatusBadRequest)
+		return rule, false
+	}
+	data, err := io.ReadAll(req.Body)
+	defer func() { _ = req.Body.Close() }()
+	if err != nil {
+		http.Error(res, messageInternalServerError, http.StatusInternalServerError)
+		return rule, false
+	}
+	if err := easyjson.Unmarshal(data, &rule); err != nil {
+		http.Error(res, "Wrong json provided.", http.StatusBadRequest)
+		return rule, false
+	}
+	if _, err := alerting.FromModel(&rule); err != nil {
+		http.Error(res, "Wrong alert rule: "+err.Error(), http.StatusBadRequest)
+		return rule, false
+	}
+	return rule, true
+}
+
+func writeJSON(res http.ResponseWriter, status int, v easyjson.Marshaler) {
+	rawBytes, err := easyjson.Marshal(v)
+	if err != nil {
+		http.Error(res, messageInternalServerError, http.StatusInternalServerError)
+		return
+	}
+	res.Header().Set("Content-Type", applicationJSONType)
+	res.WriteHeader(status)
+	if _, err := res.Write(rawBytes); err != nil {
+		http.Error(res, messageInternalServerError, http.StatusInternalServerError)
+	}
+}
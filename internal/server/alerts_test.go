@@ -0,0 +1,140 @@
+package server
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/NikolayStrekalov/vigilant-octo-waddle.git/internal/alerting"
+	"github.com/NikolayStrekalov/vigilant-octo-waddle.git/internal/memstorage"
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_alertsHandler(t *testing.T) {
+	storage, _, _ := memstorage.NewMemStorage("", false, 300)
+	storage.UpdateGauge("FreeMemory", 1024)
+	Storage = storage
+	rules, err := alerting.ParseRules("gauge FreeMemory < 500MB\ngauge HeapAlloc > 1GB")
+	require.NoError(t, err)
+	Alerts = alerting.NewEngine(Storage, rules)
+	defer func() { Alerts = nil }()
+	now := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+	Alerts.Evaluate(now)
+
+	r := chi.NewRouter()
+	prepareRoutes(r)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "http://localhost:8080/alerts", http.NoBody))
+	res := w.Result()
+	defer func() {
+		_ = res.Body.Close()
+	}()
+	assert.Equal(t, http.StatusOK, res.StatusCode)
+	assert.Equal(t, "application/json", res.Header.Get("Content-Type"))
+	data, err := io.ReadAll(res.Body)
+	require.NoError(t, err)
+	assert.JSONEq(t, `[
+		{
+			"rule": "gauge FreeMemory < 500MB",
+			"state": "firing",
+			"value": 1024,
+			"activeSince": "2024-06-01T12:00:00Z",
+			"changedAt": "2024-06-01T12:00:00Z"
+		},
+		{
+			"rule": "gauge HeapAlloc > 1GB",
+			"state": "inactive",
+			"changedAt": "2024-06-01T12:00:00Z"
+		}
+	]`, string(data))
+}
+
+func Test_alertRulesHandlers(t *testing.T) {
+	storage, _, _ := memstorage.NewMemStorage("", false, 300)
+	storage.UpdateGauge("FreeMemory", 1024)
+	Storage = storage
+	Alerts = alerting.NewEngine(Storage, nil)
+	defer func() { Alerts = nil }()
+	r := chi.NewRouter()
+	prepareRoutes(r)
+
+	tests := []struct {
+		name   string
+		method string
+		path   string
+		body   string
+		status int
+		want   string
+	}{
+		{
+			name:   "Create",
+			method: http.MethodPost,
+			path:   "/api/alerts/rules",
+			body:   `{"type":"gauge","name":"FreeMemory","op":"<","threshold":2048}`,
+			status: http.StatusCreated,
+			want:   `{"id":1,"type":"gauge","name":"FreeMemory","op":"<","threshold":2048,"rate":false}`,
+		},
+		{
+			name:   "Invalid operator",
+			method: http.MethodPost,
+			path:   "/api/alerts/rules",
+			body:   `{"type":"gauge","name":"FreeMemory","op":"~","threshold":2048}`,
+			status: http.StatusBadRequest,
+		},
+		{
+			name:   "Update",
+			method: http.MethodPut,
+			path:   "/api/alerts/rules/1",
+			body:   `{"type":"gauge","name":"FreeMemory","op":">","threshold":2048,"for":"1m"}`,
+			status: http.StatusOK,
+			want:   `{"id":1,"type":"gauge","name":"FreeMemory","op":">","threshold":2048,"for":"1m","rate":false}`,
+		},
+		{
+			name:   "Update missing",
+			method: http.MethodPut,
+			path:   "/api/alerts/rules/7",
+			body:   `{"type":"gauge","name":"FreeMemory","op":">","threshold":2048}`,
+			status: http.StatusNotFound,
+		},
+		{
+			name:   "Get",
+			method: http.MethodGet,
+			path:   "/api/alerts/rules/1",
+			status: http.StatusOK,
+			want:   `{"id":1,"type":"gauge","name":"FreeMemory","op":">","threshold":2048,"for":"1m","rate":false}`,
+		},
+		{
+			name:   "List",
+			method: http.MethodGet,
+			path:   "/api/alerts/rules",
+			status: http.StatusOK,
+			want:   `[{"id":1,"type":"gauge","name":"FreeMemory","op":">","threshold":2048,"for":"1m","rate":false}]`,
+		},
+		{name: "Delete", method: http.MethodDelete, path: "/api/alerts/rules/1", status: http.StatusNoContent},
+		{name: "Delete missing", method: http.MethodDelete, path: "/api/alerts/rules/1", status: http.StatusNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, "http://localhost:8080"+tt.path, strings.NewReader(tt.body))
+			req.Header.Set("Content-Type", applicationJSONType)
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+			res := w.Result()
+			defer func() {
+				_ = res.Body.Close()
+			}()
+			assert.Equal(t, tt.status, res.StatusCode)
+			if tt.want != "" {
+				data, err := io.ReadAll(res.Body)
+				require.NoError(t, err)
+				assert.JSONEq(t, tt.want, string(data))
+			}
+		})
+	}
+	assert.Len(t, Alerts.Statuses(), 0)
+}
@@ -0,0 +1,60 @@
+package server
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/NikolayStrekalov/vigilant-octo-waddle.git/internal/anomaly"
+	"github.com/NikolayStrekalov/vigilant-octo-waddle.git/internal/models"
+)
+
+var Anomalies *anomaly.Detector
+
+// anomalyStorage передаёт детектору аномалий все обновления gauge, проходящие через хранилище.
+type anomalyStorage struct {
+	StorageOperations
+	detector *anomaly.Detector
+}
+
+func observeAnomalies(storage StorageOperations, detector *anomaly.Detector) StorageOperations {
+	return anomalyStorage{StorageOperations: storage, detector: detector}
+}
+
+func (s anomalyStorage) UpdateGauge(name string, value float64) {
+	s.StorageOperations.UpdateGauge(name, value)
+	s.detector.Observe(name, value, time.Now())
+}
+
+func (s anomalyStorage) BulkUpdate(metrics models.MetricsSlice) {
+	s.StorageOperations.BulkUpdate(metrics)
+	s.observe(metrics)
+}
+
+func (s anomalyStorage) BulkUpdateOnce(key string, seq int64, metrics models.MetricsSlice) (bool, error) {
+	applied, err := s.StorageOperations.BulkUpdateOnce(key, seq, metrics)
+	if err != nil {
+		return false, fmt.Errorf("storage error: %w", err)
+	}
+	if applied {
+		s.observe(metrics)
+	}
+	return applied, nil
+}
+
+func (s anomalyStorage) observe(metrics models.MetricsSlice) {
+	now := time.Now()
+	for i := range metrics {
+		if metrics[i].MType == gaugeKind && metrics[i].Value != nil {
+			s.detector.Observe(metrics[i].Key(), *metrics[i].Value, now)
+		}
+	}
+}
+
+func anomaliesHandler(res http.ResponseWriter, req *http.Request) {
+	anomalies := models.Anomalies{}
+	if Anomalies != nil {
+		anomalies = Anomalies.Anomalies()
+	}
+	writeJSON(res, http.StatusOK, anomalies)
+}
@@ -0,0 +1,48 @@
+package server
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/NikolayStrekalov/vigilant-octo-waddle.git/internal/anomaly"
+	"github.com/NikolayStrekalov/vigilant-octo-waddle.git/internal/memstorage"
+	"github.com/NikolayStrekalov/vigilant-octo-waddle.git/internal/models"
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_anomaliesHandler(t *testing.T) {
+	storage, _, _ := memstorage.NewMemStorage("", false, 300)
+	Anomalies = anomaly.NewDetector(nil)
+	defer func() { Anomalies = nil }()
+	Storage = observeAnomalies(storage, Anomalies)
+	for i := 0; i < 20; i++ {
+		Storage.UpdateGauge("HeapAlloc", 1000+float64(i%2))
+	}
+	spike := 5000.0
+	Storage.BulkUpdate(models.MetricsSlice{{ID: "HeapAlloc", MType: gaugeKind, Value: &spike}})
+	v, err := storage.GetGauge("HeapAlloc")
+	require.NoError(t, err)
+	assert.Equal(t, spike, v)
+
+	r := chi.NewRouter()
+	prepareRoutes(r)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "http://localhost:8080/api/anomalies", http.NoBody))
+	res := w.Result()
+	defer func() {
+		_ = res.Body.Close()
+	}()
+	assert.Equal(t, http.StatusOK, res.StatusCode)
+	data, err := io.ReadAll(res.Body)
+	require.NoError(t, err)
+	anomalies := models.Anomalies{}
+	require.NoError(t, anomalies.UnmarshalJSON(data))
+	if assert.Len(t, anomalies, 1) {
+		assert.Equal(t, "HeapAlloc", anomalies[0].Name)
+		assert.Equal(t, spike, anomalies[0].Value)
+	}
+}
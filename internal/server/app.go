@@ -7,7 +7,9 @@ import (
 	"net/http"
 	"os"
 	"os/signal"
+	"time"
 
+	"github.com/NikolayStrekalov/vigilant-octo-waddle.git/internal/anomaly"
 	"github.com/NikolayStrekalov/vigilant-octo-waddle.git/internal/logger"
 	"github.com/NikolayStrekalov/vigilant-octo-waddle.git/internal/memstorage"
 	"github.com/NikolayStrekalov/vigilant-octo-waddle.git/internal/pgstorage"
@@ -43,12 +45,50 @@ func Start() {
 	}
 	if err != nil {
 		logger.Info("error creating storage:", err)
+	} else {
+		Storage.SetHistoryPolicy(ServerConfig.HistoryPolicy)
+		Anomalies = anomaly.NewDetector(ServerConfig.Sensitivity)
+		Storage = observeAnomalies(Storage, Anomalies)
+		reloadMaintenance()
+		restoreMaintenanceFlags()
+		go compactHistory(time.Duration(ServerConfig.CompactInterval) * time.Second)
+		go forgetBatches(time.Duration(ServerConfig.CompactInterval) * time.Second)
+		Storage.SetStaleTTL(time.Duration(ServerConfig.StaleTTL) * time.Second)
+		if ServerConfig.ExpireTTL > 0 {
+			go expireMetrics(time.Duration(ServerConfig.CompactInterval) * time.Second)
+		}
 	}
 	defer func() {
+		Maintenance.Flush()
 		if storageClose != nil {
 			_ = storageClose()
 		}
 	}()
+	if err := startAlerting(); err != nil {
+		panic(err)
+	}
+	startAbsence()
+	statsdClose, err := startStatsd()
+	if err != nil {
+		panic(err)
+	}
+	defer func() {
+		_ = statsdClose()
+	}()
+	graphiteClose, err := startGraphite()
+	if err != nil {
+		panic(err)
+	}
+	defer func() {
+		_ = graphiteClose()
+	}()
+	grpcClose, err := startGRPC()
+	if err != nil {
+		panic(err)
+	}
+	defer func() {
+		_ = grpcClose()
+	}()
 	r := appRouter()
 
 	// Дожидаемся выхода из этой функции
@@ -0,0 +1,66 @@
+package server
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/NikolayStrekalov/vigilant-octo-waddle.git/internal/logger"
+	"github.com/NikolayStrekalov/vigilant-octo-waddle.git/internal/models"
+)
+
+// Ключ и номер пачки /updates/: ключ отличает запуск агента, номер — пачку внутри него.
+// Повтор уже применённой пачки принимается, но ничего не меняет.
+const (
+	IdempotencyKeyHeader        = "Idempotency-Key"
+	IdempotencySequenceHeader   = "Idempotency-Sequence"
+	IdempotencyKeyMetadata      = "idempotency-key"
+	IdempotencySequenceMetadata = "idempotency-sequence"
+	maxIdempotencyKeyLength     = 100
+	// batchRetention — сколько помнить применённые пачки, агент повторяет их намного раньше.
+	batchRetention = 24 * time.Hour
+)
+
+var errWrongBatchKey = errors.New("wrong idempotency key or sequence")
+
+// parseBatchKey проверяет ключ и номер пачки. Пустой ключ означает пачку без защиты от повторов.
+func parseBatchKey(key, seq string) (int64, error) {
+	if key == "" {
+		return 0, nil
+	}
+	if len(key) > maxIdempotencyKeyLength {
+		return 0, errWrongBatchKey
+	}
+	n, err := strconv.ParseInt(seq, 10, 64)
+	if err != nil || n < 0 {
+		return 0, errWrongBatchKey
+	}
+	return n, nil
+}
+
+// ingestOnce сохраняет пачку как ingest, но пачку с ключом key и номером seq — не больше одного раза.
+func ingestOnce(metrics models.MetricsSlice, agent, key string, seq int64) error {
+	if key == "" {
+		ingest(metrics, agent)
+		return nil
+	}
+	metrics, flags := admitBulk(metrics)
+	if _, err := Storage.BulkUpdateOnce(key, seq, metrics); err != nil {
+		return fmt.Errorf("failed to apply batch: %w", err)
+	}
+	markBulk(agent, metrics, flags)
+	return nil
+}
+
+// forgetBatches периодически удаляет из хранилища ключи давно применённых пачек.
+func forgetBatches(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		<-ticker.C
+		if err := Storage.ForgetBatches(time.Now().Add(-batchRetention)); err != nil {
+			logger.Info("error forgetting batches:", err)
+		}
+	}
+}
@@ -0,0 +1,57 @@
+package server
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/NikolayStrekalov/vigilant-octo-waddle.git/internal/logger"
+	"github.com/NikolayStrekalov/vigilant-octo-waddle.git/internal/memstorage"
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_bulkHandlerIdempotency(t *testing.T) {
+	_ = logger.InitLog()
+	storage, _, _ := memstorage.NewMemStorage("", false, 300)
+	Storage = storage
+	r := chi.NewRouter()
+	prepareRoutes(r)
+
+	tests := []struct {
+		name   string
+		key    string
+		seq    string
+		status int
+		want   int64
+	}{
+		{name: "First", key: "agent-1", seq: "1", status: http.StatusOK, want: 3},
+		{name: "Retry", key: "agent-1", seq: "1", status: http.StatusOK, want: 3},
+		{name: "Next", key: "agent-1", seq: "2", status: http.StatusOK, want: 6},
+		{name: "Other agent", key: "agent-2", seq: "1", status: http.StatusOK, want: 9},
+		{name: "Without key", status: http.StatusOK, want: 12},
+		{name: "Wrong sequence", key: "agent-1", seq: "x", status: http.StatusBadRequest, want: 12},
+		{name: "Missing sequence", key: "agent-1", status: http.StatusBadRequest, want: 12},
+		{name: "Long key", key: strings.Repeat("k", 101), seq: "1", status: http.StatusBadRequest, want: 12},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			body := strings.NewReader(`[{"id":"PollCount","type":"counter","delta":3}]`)
+			req := httptest.NewRequest(http.MethodPost, "http://localhost:8080/updates/", body)
+			req.Header.Set("Content-Type", applicationJSONType)
+			if tt.key != "" {
+				req.Header.Set(IdempotencyKeyHeader, tt.key)
+				req.Header.Set(IdempotencySequenceHeader, tt.seq)
+			}
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+			res := w.Result()
+			_ = res.Body.Close()
+			assert.Equal(t, tt.status, res.StatusCode)
+			got, err := Storage.GetCounter("PollCount")
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
@@ -8,19 +8,58 @@ import (
 	"os"
 	"strconv"
 
+	"github.com/NikolayStrekalov/vigilant-octo-waddle.git/internal/anomaly"
+	"github.com/NikolayStrekalov/vigilant-octo-waddle.git/internal/graphite"
+	"github.com/NikolayStrekalov/vigilant-octo-waddle.git/internal/histogram"
+	"github.com/NikolayStrekalov/vigilant-octo-waddle.git/internal/history"
+	"github.com/NikolayStrekalov/vigilant-octo-waddle.git/internal/hll"
+	"github.com/NikolayStrekalov/vigilant-octo-waddle.git/internal/influx"
 	"github.com/NikolayStrekalov/vigilant-octo-waddle.git/internal/logger"
+	"github.com/NikolayStrekalov/vigilant-octo-waddle.git/internal/sketch"
 )
 
 type Config struct {
-	Address         string `json:"server"`
-	FileStoragePath string `json:"dumpPath"`
-	DatabaseDSN     string `json:"dsn"`
-	SignKey         string `json:"key"`
-	StoreInterval   int    `json:"interval"`
-	RestoreStore    bool   `json:"restore"`
+	Address            string                `json:"server"`
+	FileStoragePath    string                `json:"dumpPath"`
+	DatabaseDSN        string                `json:"dsn"`
+	SignKey            string                `json:"key"`
+	RetentionPolicy    string                `json:"retention"`
+	AlertRulesFile     string                `json:"alertRules"`
+	WebhookURLs        string                `json:"webhooks"`
+	AnomalySensitivity string                `json:"anomalySensitivity"`
+	StatsdAddress      string                `json:"statsd"`
+	GraphiteAddress    string                `json:"graphite"`
+	GraphiteMapping    string                `json:"graphiteMapping"`
+	GRPCAddress        string                `json:"grpc"`
+	HistogramBuckets   string                `json:"histogramBuckets"`
+	InfluxCounters     string                `json:"influxCounters"`
+	HistoryPolicy      history.Policy        `json:"-"`
+	Sensitivity        []anomaly.Sensitivity `json:"-"`
+	GraphiteRules      []graphite.Rule       `json:"-"`
+	InfluxCounterRules []string              `json:"-"`
+	HistogramBounds    []float64             `json:"-"`
+	SummaryAccuracy    float64               `json:"summaryAccuracy"`
+	SetPrecision       uint                  `json:"setPrecision"`
+	StoreInterval      int                   `json:"interval"`
+	CompactInterval    int                   `json:"compactInterval"`
+	AlertInterval      int                   `json:"alertInterval"`
+	ReportInterval     int                   `json:"reportInterval"`
+	AbsenceIntervals   int                   `json:"absenceIntervals"`
+	StatsdFlush        int                   `json:"statsdFlush"`
+	StaleTTL           int                   `json:"staleTTL"`
+	ExpireTTL          int                   `json:"expireTTL"`
+	RestoreStore       bool                  `json:"restore"`
 }
 
-const defaultStoreInterval = 300 // seconds
+const (
+	defaultStoreInterval    = 300 // seconds
+	defaultCompactInterval  = 60  // seconds
+	defaultAlertInterval    = 10  // seconds
+	defaultReportInterval   = 10  // seconds
+	defaultAbsenceIntervals = 3
+	defaultStatsdFlush      = 10 // seconds
+	defaultRetentionPolicy  = "raw=24h,1m=30d,1h=365d"
+)
 
 var ServerConfig = Config{}
 
@@ -56,6 +95,120 @@ func fillConfig() error {
 		"",
 		"Ключ подписи запросов.",
 	)
+	flag.StringVar(
+		&ServerConfig.RetentionPolicy,
+		"rp",
+		defaultRetentionPolicy,
+		"Политика хранения истории: срок для сырых значений и свёрток вида raw=24h,1m=30d,1h=365d",
+	)
+	flag.IntVar(
+		&ServerConfig.CompactInterval,
+		"ci",
+		defaultCompactInterval,
+		"Интервал сжатия истории в секундах",
+	)
+	flag.StringVar(
+		&ServerConfig.AlertRulesFile,
+		"ar",
+		"",
+		"Файл с правилами алертинга, по одному в строке, например: gauge FreeMemory < 500MB for 2m",
+	)
+	flag.IntVar(
+		&ServerConfig.AlertInterval,
+		"ai",
+		defaultAlertInterval,
+		"Интервал проверки правил алертинга в секундах",
+	)
+	flag.StringVar(
+		&ServerConfig.WebhookURLs,
+		"wh",
+		"",
+		"Адреса вебхуков через запятую для уведомлений о срабатывании правил",
+	)
+	flag.IntVar(
+		&ServerConfig.ReportInterval,
+		"ri",
+		defaultReportInterval,
+		"Ожидаемый интервал отправки метрик агентами в секундах",
+	)
+	flag.IntVar(
+		&ServerConfig.AbsenceIntervals,
+		"an",
+		defaultAbsenceIntervals,
+		"Через сколько интервалов отправки без обновлений метрика или агент считаются отсутствующими",
+	)
+	flag.StringVar(
+		&ServerConfig.StatsdAddress,
+		"sd",
+		"",
+		"UDP-адрес HOST:PORT для приёма метрик StatsD, пустой — не слушать",
+	)
+	flag.IntVar(
+		&ServerConfig.StatsdFlush,
+		"sf",
+		defaultStatsdFlush,
+		"Интервал сброса накопленных метрик StatsD в хранилище в секундах",
+	)
+	flag.StringVar(
+		&ServerConfig.GRPCAddress,
+		"g",
+		"",
+		"Эндпоинт gRPC-сервиса Metrics HOST:PORT, пустой — не запускать",
+	)
+	flag.StringVar(
+		&ServerConfig.GraphiteAddress,
+		"gr",
+		"",
+		"TCP-адрес HOST:PORT для приёма метрик Graphite, пустой — не слушать",
+	)
+	flag.StringVar(
+		&ServerConfig.GraphiteMapping,
+		"gm",
+		"",
+		"Имена метрик для путей Graphite, например servers.*.cpu=CPU_$1,jobs.*.duration=$1Duration",
+	)
+	flag.StringVar(
+		&ServerConfig.HistogramBuckets,
+		"hb",
+		"",
+		"Границы корзин гистограмм для одиночных наблюдений через /update/, пустые — как в клиентах Prometheus",
+	)
+	flag.StringVar(
+		&ServerConfig.InfluxCounters,
+		"ic",
+		"",
+		"Шаблоны имён накопительных счётчиков line protocol через запятую, например net_bytes_*,diskio_reads",
+	)
+	flag.Float64Var(
+		&ServerConfig.SummaryAccuracy,
+		"sa",
+		sketch.DefaultAlpha,
+		"Относительная точность квантилей summary для одиночных значений через /update/",
+	)
+	flag.UintVar(
+		&ServerConfig.SetPrecision,
+		"sp",
+		hll.DefaultPrecision,
+		"Точность HyperLogLog для set: число бит номера регистра от 4 до 18",
+	)
+	flag.IntVar(
+		&ServerConfig.StaleTTL,
+		"st",
+		0,
+		"Через сколько секунд без обновлений gauge и counter скрываются из списков (0 - не скрывать)",
+	)
+	flag.IntVar(
+		&ServerConfig.ExpireTTL,
+		"et",
+		0,
+		"Через сколько секунд без обновлений gauge и counter удаляются (0 - не удалять)",
+	)
+	flag.StringVar(
+		&ServerConfig.AnomalySensitivity,
+		"as",
+		"",
+		"Порог аномалий в сигмах по шаблонам имён gauge, например HeapAlloc=4,CPUutilization*=2.5",
+	)
 	flag.Parse()
 	if len(flag.Args()) > 0 {
 		return errors.New("too many args")
@@ -87,6 +240,151 @@ func fillConfig() error {
 	if envSignKey := os.Getenv("KEY"); envSignKey != "" {
 		ServerConfig.SignKey = envSignKey
 	}
+	if envRetentionPolicy := os.Getenv("RETENTION_POLICY"); envRetentionPolicy != "" {
+		ServerConfig.RetentionPolicy = envRetentionPolicy
+	}
+	if envCompactInterval := os.Getenv("COMPACT_INTERVAL"); envCompactInterval != "" {
+		value, err := strconv.Atoi(envCompactInterval)
+		if err != nil {
+			return fmt.Errorf("can't parse COMPACT_INTERVAL: %w", err)
+		}
+		ServerConfig.CompactInterval = value
+	}
+	if envAlertRules := os.Getenv("ALERT_RULES"); envAlertRules != "" {
+		ServerConfig.AlertRulesFile = envAlertRules
+	}
+	if envAlertInterval := os.Getenv("ALERT_INTERVAL"); envAlertInterval != "" {
+		value, err := strconv.Atoi(envAlertInterval)
+		if err != nil {
+			return fmt.Errorf("can't parse ALERT_INTERVAL: %w", err)
+		}
+		ServerConfig.AlertInterval = value
+	}
+	if envWebhookURLs := os.Getenv("WEBHOOK_URLS"); envWebhookURLs != "" {
+		ServerConfig.WebhookURLs = envWebhookURLs
+	}
+	if envAnomalySensitivity := os.Getenv("ANOMALY_SENSITIVITY"); envAnomalySensitivity != "" {
+		ServerConfig.AnomalySensitivity = envAnomalySensitivity
+	}
+	if envReportInterval := os.Getenv("REPORT_INTERVAL"); envReportInterval != "" {
+		value, err := strconv.Atoi(envReportInterval)
+		if err != nil {
+			return fmt.Errorf("can't parse REPORT_INTERVAL: %w", err)
+		}
+		ServerConfig.ReportInterval = value
+	}
+	if envAbsenceIntervals := os.Getenv("ABSENCE_INTERVALS"); envAbsenceIntervals != "" {
+		value, err := strconv.Atoi(envAbsenceIntervals)
+		if err != nil {
+			return fmt.Errorf("can't parse ABSENCE_INTERVALS: %w", err)
+		}
+		ServerConfig.AbsenceIntervals = value
+	}
+	if envStatsdAddress := os.Getenv("STATSD_ADDRESS"); envStatsdAddress != "" {
+		ServerConfig.StatsdAddress = envStatsdAddress
+	}
+	if envStatsdFlush := os.Getenv("STATSD_FLUSH_INTERVAL"); envStatsdFlush != "" {
+		value, err := strconv.Atoi(envStatsdFlush)
+		if err != nil {
+			return fmt.Errorf("can't parse STATSD_FLUSH_INTERVAL: %w", err)
+		}
+		ServerConfig.StatsdFlush = value
+	}
+	if envGRPCAddress := os.Getenv("GRPC_ADDRESS"); envGRPCAddress != "" {
+		ServerConfig.GRPCAddress = envGRPCAddress
+	}
+	if envGraphiteAddress := os.Getenv("GRAPHITE_ADDRESS"); envGraphiteAddress != "" {
+		ServerConfig.GraphiteAddress = envGraphiteAddress
+	}
+	if envGraphiteMapping := os.Getenv("GRAPHITE_MAPPING"); envGraphiteMapping != "" {
+		ServerConfig.GraphiteMapping = envGraphiteMapping
+	}
+	if envHistogramBuckets := os.Getenv("HISTOGRAM_BUCKETS"); envHistogramBuckets != "" {
+		ServerConfig.HistogramBuckets = envHistogramBuckets
+	}
+	if envInfluxCounters := os.Getenv("INFLUX_COUNTERS"); envInfluxCounters != "" {
+		ServerConfig.InfluxCounters = envInfluxCounters
+	}
+	if envSummaryAccuracy := os.Getenv("SUMMARY_ACCURACY"); envSummaryAccuracy != "" {
+		value, err := strconv.ParseFloat(envSummaryAccuracy, 64)
+		if err != nil {
+			return fmt.Errorf("can't parse SUMMARY_ACCURACY: %w", err)
+		}
+		ServerConfig.SummaryAccuracy = value
+	}
+	if envSetPrecision := os.Getenv("SET_PRECISION"); envSetPrecision != "" {
+		value, err := strconv.ParseUint(envSetPrecision, 10, 8)
+		if err != nil {
+			return fmt.Errorf("can't parse SET_PRECISION: %w", err)
+		}
+		ServerConfig.SetPrecision = uint(value)
+	}
+	if envStaleTTL := os.Getenv("STALE_TTL"); envStaleTTL != "" {
+		value, err := strconv.Atoi(envStaleTTL)
+		if err != nil {
+			return fmt.Errorf("can't parse STALE_TTL: %w", err)
+		}
+		ServerConfig.StaleTTL = value
+	}
+	if envExpireTTL := os.Getenv("EXPIRE_TTL"); envExpireTTL != "" {
+		value, err := strconv.Atoi(envExpireTTL)
+		if err != nil {
+			return fmt.Errorf("can't parse EXPIRE_TTL: %w", err)
+		}
+		ServerConfig.ExpireTTL = value
+	}
+	if ServerConfig.CompactInterval <= 0 {
+		return errors.New("compact interval must be positive")
+	}
+	if ServerConfig.AlertInterval <= 0 {
+		return errors.New("alert interval must be positive")
+	}
+	if ServerConfig.StatsdFlush <= 0 {
+		return errors.New("statsd flush interval must be positive")
+	}
+	if ServerConfig.ReportInterval <= 0 || ServerConfig.AbsenceIntervals <= 0 {
+		return errors.New("report interval and absence intervals must be positive")
+	}
+	if ServerConfig.StaleTTL < 0 || ServerConfig.ExpireTTL < 0 {
+		return errors.New("stale and expire TTL must not be negative")
+	}
+	if ServerConfig.StaleTTL > 0 && ServerConfig.ExpireTTL > 0 && ServerConfig.ExpireTTL < ServerConfig.StaleTTL {
+		return errors.New("expire TTL must not be less than stale TTL")
+	}
+	if err := sketch.ValidAlpha(ServerConfig.SummaryAccuracy); err != nil {
+		return fmt.Errorf("wrong summary accuracy: %w", err)
+	}
+	if ServerConfig.SetPrecision < hll.MinPrecision || ServerConfig.SetPrecision > hll.MaxPrecision {
+		return errors.New("set precision must be in [4, 18]")
+	}
+	policy, err := history.ParsePolicy(ServerConfig.RetentionPolicy)
+	if err != nil {
+		return fmt.Errorf("can't parse RETENTION_POLICY: %w", err)
+	}
+	ServerConfig.HistoryPolicy = policy
+	sensitivity, err := anomaly.ParseSensitivity(ServerConfig.AnomalySensitivity)
+	if err != nil {
+		return fmt.Errorf("can't parse ANOMALY_SENSITIVITY: %w", err)
+	}
+	ServerConfig.Sensitivity = sensitivity
+	rules, err := graphite.ParseMapping(ServerConfig.GraphiteMapping)
+	if err != nil {
+		return fmt.Errorf("can't parse GRAPHITE_MAPPING: %w", err)
+	}
+	ServerConfig.GraphiteRules = rules
+	counters, err := influx.ParseCounters(ServerConfig.InfluxCounters)
+	if err != nil {
+		return fmt.Errorf("can't parse INFLUX_COUNTERS: %w", err)
+	}
+	ServerConfig.InfluxCounterRules = counters
+	ServerConfig.HistogramBounds = histogram.DefaultBounds
+	if ServerConfig.HistogramBuckets != "" {
+		bounds, err := histogram.ParseBounds(ServerConfig.HistogramBuckets)
+		if err != nil {
+			return fmt.Errorf("can't parse HISTOGRAM_BUCKETS: %w", err)
+		}
+		ServerConfig.HistogramBounds = bounds
+	}
 
 	ServerConfig.log()
 	return nil
@@ -0,0 +1,74 @@
+package server
+
+import (
+	"net/http"
+	"path"
+
+	"github.com/NikolayStrekalov/vigilant-octo-waddle.git/internal/models"
+	"github.com/NikolayStrekalov/vigilant-octo-waddle.git/internal/series"
+	"github.com/go-chi/chi/v5"
+)
+
+const (
+	deleteMetricsPath = "/api/metrics"
+	resetCounterPath  = "/reset/counter/{name}"
+	patternParam      = "pattern"
+)
+
+// forgetMetrics убирает удалённые серии из отслеживания пропаж и аномалий.
+func forgetMetrics(match func(kind, name string) bool) {
+	if Absence != nil {
+		Absence.Forget(match)
+	}
+	if Anomalies != nil {
+		Anomalies.Forget(func(name string) bool { return match(gaugeKind, name) })
+	}
+}
+
+// deleteMetricHandler удаляет серию вместе с историей, метки задаются параметрами запроса.
+func deleteMetricHandler(res http.ResponseWriter, req *http.Request) {
+	kind := chi.URLParam(req, "kind")
+	switch kind {
+	case gaugeKind, counterKind, histogramKind, summaryKind, setKind:
+	default:
+		http.Error(res, wrongMetricType, http.StatusNotFound)
+		return
+	}
+	name := series.Key(chi.URLParam(req, "name"), queryLabels(req))
+	if err := Storage.DeleteMetric(kind, name); err != nil {
+		http.Error(res, metricNotFound, http.StatusNotFound)
+		return
+	}
+	forgetMetrics(func(k, n string) bool { return k == kind && n == name })
+	res.WriteHeader(http.StatusNoContent)
+}
+
+// deleteMetricsHandler удаляет серии всех типов, ключи которых подходят под шаблон path.Match,
+// например DELETE /api/metrics?pattern=*{host="old"}.
+func deleteMetricsHandler(res http.ResponseWriter, req *http.Request) {
+	pattern := req.URL.Query().Get(patternParam)
+	if _, err := path.Match(pattern, ""); err != nil || pattern == "" {
+		http.Error(res, "Wrong pattern!", http.StatusBadRequest)
+		return
+	}
+	deleted, err := Storage.DeleteMetrics(pattern)
+	if err != nil {
+		http.Error(res, messageInternalServerError, http.StatusInternalServerError)
+		return
+	}
+	forgetMetrics(func(_, n string) bool {
+		ok, _ := path.Match(pattern, n)
+		return ok
+	})
+	writeJSON(res, http.StatusOK, &models.Deleted{Deleted: deleted})
+}
+
+// resetCounterHandler обнуляет счётчик, накопленный итог агента при этом сохраняется.
+func resetCounterHandler(res http.ResponseWriter, req *http.Request) {
+	name := series.Key(chi.URLParam(req, "name"), queryLabels(req))
+	if err := Storage.ResetCounter(name); err != nil {
+		http.Error(res, metricNotFound, http.StatusNotFound)
+		return
+	}
+	res.WriteHeader(http.StatusNoContent)
+}
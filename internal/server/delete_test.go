@@ -0,0 +1,74 @@
+package server
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/NikolayStrekalov/vigilant-octo-waddle.git/internal/logger"
+	"github.com/NikolayStrekalov/vigilant-octo-waddle.git/internal/memstorage"
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_deleteHandlers(t *testing.T) {
+	_ = logger.InitLog()
+	storage, _, _ := memstorage.NewMemStorage("", false, 300)
+	Storage = storage
+	Storage.UpdateGauge(`Alloc{host="a"}`, 1)
+	Storage.UpdateGauge(`Alloc{host="b"}`, 2)
+	Storage.UpdateGauge("Typo", 3)
+	Storage.IncrementCounter(`PollCount{host="b"}`, 4)
+	Storage.IncrementCounter("requests", 5)
+	r := chi.NewRouter()
+	prepareRoutes(r)
+
+	tests := []struct {
+		name   string
+		method string
+		path   string
+		status int
+		want   string
+	}{
+		{name: "Delete", method: http.MethodDelete, path: "/value/gauge/Typo", status: http.StatusNoContent},
+		{name: "Deleted", method: http.MethodGet, path: "/value/gauge/Typo", status: http.StatusNotFound},
+		{name: "Delete again", method: http.MethodDelete, path: "/value/gauge/Typo", status: http.StatusNotFound},
+		{name: "Wrong type", method: http.MethodDelete, path: "/value/unknown/Typo", status: http.StatusNotFound},
+		{name: "Labels", method: http.MethodDelete, path: "/value/gauge/Alloc?host=a", status: http.StatusNoContent},
+		{name: "Other labels", method: http.MethodGet, path: "/value/gauge/Alloc?host=b", status: http.StatusOK, want: "2"},
+		{name: "No pattern", method: http.MethodDelete, path: "/api/metrics", status: http.StatusBadRequest},
+		{name: "Wrong pattern", method: http.MethodDelete, path: "/api/metrics?pattern=%5B", status: http.StatusBadRequest},
+		{
+			name:   "Pattern",
+			method: http.MethodDelete,
+			path:   "/api/metrics?pattern=*%7Bhost%3D%22b%22%7D",
+			status: http.StatusOK,
+			want:   `{"deleted":2}`,
+		},
+		{name: "Reset", method: http.MethodPost, path: "/reset/counter/requests", status: http.StatusNoContent},
+		{name: "Reset value", method: http.MethodGet, path: "/value/counter/requests", status: http.StatusOK, want: "0"},
+		{name: "Reset unknown", method: http.MethodPost, path: "/reset/counter/PollCount", status: http.StatusNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, "http://localhost:8080"+tt.path, http.NoBody)
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+			res := w.Result()
+			defer func() {
+				_ = res.Body.Close()
+			}()
+			assert.Equal(t, tt.status, res.StatusCode)
+			if tt.want != "" {
+				data, err := io.ReadAll(res.Body)
+				require.NoError(t, err)
+				assert.Equal(t, tt.want, strings.TrimSpace(string(data)))
+			}
+		})
+	}
+	assert.Empty(t, Storage.GetGaugeList())
+	assert.Len(t, Storage.GetCounterList(), 1)
+}
@@ -0,0 +1,42 @@
+package server
+
+import (
+	"time"
+
+	"github.com/NikolayStrekalov/vigilant-octo-waddle.git/internal/logger"
+)
+
+// expireMetrics периодически удаляет gauge и counter, не обновлявшиеся дольше ExpireTTL.
+func expireMetrics(interval time.Duration) {
+	ttl := time.Duration(ServerConfig.ExpireTTL) * time.Second
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		<-ticker.C
+		expire(time.Now().Add(-ttl))
+	}
+}
+
+// expire удаляет серии, не обновлявшиеся с before, и перестаёт отслеживать их пропажу и аномалии.
+func expire(before time.Time) {
+	deleted, err := Storage.ExpireMetrics(before)
+	if err != nil {
+		logger.Info("error expiring metrics:", err)
+		return
+	}
+	if deleted == 0 {
+		return
+	}
+	logger.Info("expired metrics:", deleted)
+	forgetMetrics(func(kind, name string) bool {
+		switch kind {
+		case gaugeKind:
+			_, err := Storage.GetGauge(name)
+			return err != nil
+		case counterKind:
+			_, err := Storage.GetCounter(name)
+			return err != nil
+		}
+		return false
+	})
+}
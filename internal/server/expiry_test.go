@@ -0,0 +1,33 @@
+package server
+
+import (
+	"testing"
+	"time"
+
+	"github.com/NikolayStrekalov/vigilant-octo-waddle.git/internal/absence"
+	"github.com/NikolayStrekalov/vigilant-octo-waddle.git/internal/memstorage"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_expire(t *testing.T) {
+	Storage, _, _ = memstorage.NewMemStorage("", false, 300)
+	Absence = absence.NewTracker(time.Minute)
+	defer func() { Absence = nil }()
+	Storage.UpdateGauge("Alloc", 1)
+	Storage.IncrementCounter("PollCount", 1)
+	now := time.Now()
+	markSeen("10.0.0.1", gaugeKind, "Alloc")
+	markSeen("10.0.0.1", counterKind, "PollCount")
+	Absence.Check(now.Add(2 * time.Minute))
+	assert.Len(t, Absence.Absences(), 3)
+
+	expire(now.Add(-time.Hour))
+	assert.Len(t, Storage.GetGaugeList(), 1, "fresh metrics are kept")
+	expire(now.Add(time.Second))
+	assert.Empty(t, Storage.GetGaugeList())
+	assert.Empty(t, Storage.GetCounterList())
+	absences := Absence.Absences()
+	if assert.Len(t, absences, 1) {
+		assert.Equal(t, absence.KindAgent, absences[0].Kind)
+	}
+}
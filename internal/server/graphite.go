@@ -0,0 +1,25 @@
+package server
+
+import (
+	"fmt"
+
+	"github.com/NikolayStrekalov/vigilant-octo-waddle.git/internal/graphite"
+	"github.com/NikolayStrekalov/vigilant-octo-waddle.git/internal/logger"
+)
+
+// startGraphite запускает приём Graphite, если задан адрес, и возвращает функцию остановки.
+func startGraphite() (func() error, error) {
+	if ServerConfig.GraphiteAddress == "" {
+		return func() error { return nil }, nil
+	}
+	listener, err := graphite.Listen(ServerConfig.GraphiteAddress, ServerConfig.GraphiteRules)
+	if err != nil {
+		return nil, fmt.Errorf("can't start graphite: %w", err)
+	}
+	go func() {
+		if err := listener.Serve(ingestStorage{}); err != nil {
+			logger.Info("graphite error:", err)
+		}
+	}()
+	return listener.Close, nil
+}
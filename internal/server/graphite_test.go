@@ -0,0 +1,44 @@
+package server
+
+import (
+	"net"
+	"testing"
+	"time"
+
+	"github.com/NikolayStrekalov/vigilant-octo-waddle.git/internal/graphite"
+	"github.com/NikolayStrekalov/vigilant-octo-waddle.git/internal/logger"
+	"github.com/NikolayStrekalov/vigilant-octo-waddle.git/internal/memstorage"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_startGraphite(t *testing.T) {
+	_ = logger.InitLog()
+	storage, _, _ := memstorage.NewMemStorage("", false, 300)
+	Storage = storage
+	probe, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	addr := probe.Addr().String()
+	require.NoError(t, probe.Close())
+	defer func(prev Config) { ServerConfig = prev }(ServerConfig)
+	ServerConfig.GraphiteAddress = addr
+	ServerConfig.GraphiteRules, err = graphite.ParseMapping("cron.*.duration=$1Duration")
+	require.NoError(t, err)
+
+	stop, err := startGraphite()
+	require.NoError(t, err)
+	defer func() { _ = stop() }()
+	conn, err := net.Dial("tcp", addr)
+	require.NoError(t, err)
+	_, err = conn.Write([]byte("cron.backup.duration 31.5 1717243200\ncron.backup.size 1024 1717243200\n"))
+	require.NoError(t, err)
+	require.NoError(t, conn.Close())
+
+	assert.Eventually(t, func() bool {
+		v, err := storage.GetGauge("backupDuration")
+		return err == nil && v == 31.5
+	}, time.Second, 10*time.Millisecond)
+	v, err := storage.GetGauge("cron.backup.size")
+	assert.Nil(t, err)
+	assert.Equal(t, 1024.0, v)
+}
@@ -0,0 +1,252 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"time"
+
+	"github.com/NikolayStrekalov/vigilant-octo-waddle.git/internal/logger"
+	"github.com/NikolayStrekalov/vigilant-octo-waddle.git/internal/models"
+	pb "github.com/NikolayStrekalov/vigilant-octo-waddle.git/internal/proto"
+	"github.com/NikolayStrekalov/vigilant-octo-waddle.git/internal/series"
+	"github.com/NikolayStrekalov/vigilant-octo-waddle.git/internal/sign"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+)
+
+// SignatureMetadata — ключ метаданных с подписью запроса, аналог заголовка Hashsha256.
+const SignatureMetadata = "hashsha256"
+
+// metricsServer реализует gRPC-сервис Metrics поверх Storage так же, как HTTP-обработчики.
+type metricsServer struct {
+	pb.UnimplementedMetricsServer
+}
+
+// fromProto переводит метрику из gRPC в модель, проверяя тип и наличие значения.
+func fromProto(m *pb.Metric) (models.Metrics, error) {
+	ret := models.Metrics{ID: m.GetId(), Labels: m.GetLabels()}
+	if err := series.Validate(ret.Labels); err != nil {
+		return ret, status.Error(codes.InvalidArgument, err.Error())
+	}
+	switch m.GetType() {
+	case pb.Metric_GAUGE:
+		ret.MType = gaugeKind
+		if m.Value == nil {
+			return ret, status.Error(codes.InvalidArgument, "Provide value field for update!")
+		}
+		v := m.GetValue()
+		ret.Value = &v
+	case pb.Metric_COUNTER:
+		ret.MType = counterKind
+		if m.Delta == nil {
+			return ret, status.Error(codes.InvalidArgument, "Provide delta field for increment!")
+		}
+		d := m.GetDelta()
+		ret.Delta = &d
+	default:
+		return ret, status.Error(codes.InvalidArgument, wrongMetricType)
+	}
+	return ret, nil
+}
+
+// readMetric читает текущее значение серии в формате gRPC.
+func readMetric(kind pb.Metric_MType, key string) (*pb.Metric, error) {
+	name, labels := series.Parse(key)
+	ret := &pb.Metric{Id: name, Type: kind, Labels: labels}
+	switch kind {
+	case pb.Metric_GAUGE:
+		v, err := Storage.GetGauge(key)
+		if err != nil {
+			return nil, status.Error(codes.NotFound, metricNotFound)
+		}
+		ret.Value = &v
+		ret.Maintenance = Maintenance.Flagged(gaugeKind, key)
+	case pb.Metric_COUNTER:
+		v, err := Storage.GetCounter(key)
+		if err != nil {
+			return nil, status.Error(codes.NotFound, metricNotFound)
+		}
+		ret.Delta = &v
+		ret.Maintenance = Maintenance.Flagged(counterKind, key)
+	default:
+		return nil, status.Error(codes.InvalidArgument, wrongMetricType)
+	}
+	return ret, nil
+}
+
+// firstMetadata возвращает первое значение ключа метаданных или пустую строку.
+func firstMetadata(md metadata.MD, key string) string {
+	if values := md.Get(key); len(values) > 0 {
+		return values[0]
+	}
+	return ""
+}
+
+// peerAddress возвращает адрес агента без порта, как agentAddress для HTTP.
+func peerAddress(ctx context.Context) string {
+	p, ok := peer.FromContext(ctx)
+	if !ok || p.Addr == nil {
+		return ""
+	}
+	host, _, err := net.SplitHostPort(p.Addr.String())
+	if err != nil {
+		return p.Addr.String()
+	}
+	return host
+}
+
+// peerAgent определяет агента по метаданным Agent-Id, как agentName для HTTP, или по адресу.
+func peerAgent(ctx context.Context) string {
+	md, _ := metadata.FromIncomingContext(ctx)
+	return agentIDOr(firstMetadata(md, AgentIDHeader), peerAddress(ctx))
+}
+
+func (metricsServer) Update(ctx context.Context, req *pb.UpdateRequest) (*pb.UpdateResponse, error) {
+	m, err := fromProto(req.GetMetric())
+	if err != nil {
+		return nil, err
+	}
+	key := m.Key()
+	flagged, ok := Maintenance.Admit(key, time.Now())
+	if !ok {
+		return nil, status.Error(codes.FailedPrecondition, metricInMaintenance)
+	}
+	if m.MType == gaugeKind {
+		Storage.UpdateGauge(key, *m.Value)
+	} else {
+		Storage.IncrementCounter(key, *m.Delta)
+	}
+	markUpdated(peerAgent(ctx), m.MType, key, flagged)
+	stored, err := readMetric(req.GetMetric().GetType(), key)
+	if err != nil {
+		return nil, err
+	}
+	return &pb.UpdateResponse{Metric: stored}, nil
+}
+
+func (metricsServer) BulkUpdate(ctx context.Context, req *pb.BulkUpdateRequest) (*pb.BulkUpdateResponse, error) {
+	metrics := make(models.MetricsSlice, 0, len(req.GetMetrics()))
+	for _, m := range req.GetMetrics() {
+		metric, err := fromProto(m)
+		if err != nil {
+			return nil, err
+		}
+		metrics = append(metrics, metric)
+	}
+	md, _ := metadata.FromIncomingContext(ctx)
+	key, seq := firstMetadata(md, IdempotencyKeyMetadata), firstMetadata(md, IdempotencySequenceMetadata)
+	n, err := parseBatchKey(key, seq)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	agent, err := agentInfo(func(k string) string { return firstMetadata(md, k) })
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	if err := ingestOnce(metrics, peerAgent(ctx), key, n); err != nil {
+		logger.Info("error applying batch:", err)
+		return nil, status.Error(codes.Internal, messageInternalServerError)
+	}
+	registerAgent(&agent, peerAddress(ctx), len(metrics))
+	return &pb.BulkUpdateResponse{}, nil
+}
+
+func (metricsServer) Get(ctx context.Context, req *pb.GetRequest) (*pb.GetResponse, error) {
+	m, err := readMetric(req.GetType(), series.Key(req.GetId(), req.GetLabels()))
+	if err != nil {
+		return nil, err
+	}
+	return &pb.GetResponse{Metric: m}, nil
+}
+
+func (metricsServer) List(ctx context.Context, req *pb.ListRequest) (*pb.ListResponse, error) {
+	counters := Storage.GetCounterList()
+	gauges := Storage.GetGaugeList()
+	ret := &pb.ListResponse{Metrics: make([]*pb.Metric, 0, len(counters)+len(gauges))}
+	for i := range counters {
+		name, labels := series.Parse(counters[i].Name)
+		ret.Metrics = append(ret.Metrics, &pb.Metric{
+			Id:          name,
+			Labels:      labels,
+			Type:        pb.Metric_COUNTER,
+			Delta:       &counters[i].Value,
+			Maintenance: Maintenance.Flagged(counterKind, counters[i].Name),
+		})
+	}
+	for i := range gauges {
+		name, labels := series.Parse(gauges[i].Name)
+		ret.Metrics = append(ret.Metrics, &pb.Metric{
+			Id:          name,
+			Labels:      labels,
+			Type:        pb.Metric_GAUGE,
+			Value:       &gauges[i].Value,
+			Maintenance: Maintenance.Flagged(gaugeKind, gauges[i].Name),
+		})
+	}
+	return ret, nil
+}
+
+// signatureInterceptor проверяет подпись запроса, если агент её передал, как shaMiddlewareBuilder для HTTP.
+// Подписывается детерминированная сериализация сообщения.
+func signatureInterceptor(key string) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		md, _ := metadata.FromIncomingContext(ctx)
+		sent := md.Get(SignatureMetadata)
+		if len(sent) == 0 {
+			return handler(ctx, req)
+		}
+		msg, ok := req.(proto.Message)
+		if !ok {
+			return nil, status.Error(codes.Internal, messageInternalServerError)
+		}
+		data, err := proto.MarshalOptions{Deterministic: true}.Marshal(msg)
+		if err != nil {
+			return nil, status.Error(codes.Internal, messageInternalServerError)
+		}
+		expected, err := sign.Sign(data, key)
+		if err != nil {
+			return nil, status.Error(codes.Internal, messageInternalServerError)
+		}
+		if sent[0] != expected {
+			return nil, status.Error(codes.InvalidArgument, "wrong signature")
+		}
+		return handler(ctx, req)
+	}
+}
+
+// newGRPCServer создаёт gRPC-сервер с зарегистрированным сервисом Metrics.
+func newGRPCServer() *grpc.Server {
+	var opts []grpc.ServerOption
+	if ServerConfig.SignKey != "" {
+		opts = append(opts, grpc.UnaryInterceptor(signatureInterceptor(ServerConfig.SignKey)))
+	}
+	s := grpc.NewServer(opts...)
+	pb.RegisterMetricsServer(s, metricsServer{})
+	return s
+}
+
+// startGRPC запускает gRPC-сервер, если задан адрес, и возвращает функцию остановки.
+func startGRPC() (func() error, error) {
+	if ServerConfig.GRPCAddress == "" {
+		return func() error { return nil }, nil
+	}
+	ln, err := net.Listen("tcp", ServerConfig.GRPCAddress)
+	if err != nil {
+		return nil, fmt.Errorf("can't start grpc: %w", err)
+	}
+	s := newGRPCServer()
+	go func() {
+		if err := s.Serve(ln); err != nil {
+			logger.Info("grpc error:", err)
+		}
+	}()
+	return func() error {
+		s.GracefulStop()
+		return nil
+	}, nil
+}
@@ -0,0 +1,80 @@
+package server
+
+import (
+	"context"
+	"net"
+	"testing"
+
+	"github.com/NikolayStrekalov/vigilant-octo-waddle.git/internal/memstorage"
+	pb "github.com/NikolayStrekalov/vigilant-octo-waddle.git/internal/proto"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+func grpcClient(t *testing.T) pb.MetricsClient {
+	t.Helper()
+	ln := bufconn.Listen(1 << 20)
+	s := newGRPCServer()
+	go func() { _ = s.Serve(ln) }()
+	t.Cleanup(s.Stop)
+	conn, err := grpc.NewClient(
+		"passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return ln.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = conn.Close() })
+	return pb.NewMetricsClient(conn)
+}
+
+func TestMetricsServer(t *testing.T) {
+	storage, _, _ := memstorage.NewMemStorage("", false, 300)
+	Storage = storage
+	client := grpcClient(t)
+	ctx := context.Background()
+	value, delta := 12.5, int64(3)
+
+	counter := &pb.UpdateRequest{Metric: &pb.Metric{Id: "PollCount", Type: pb.Metric_COUNTER, Delta: &delta}}
+	resp, err := client.Update(ctx, counter)
+	require.NoError(t, err)
+	assert.Equal(t, int64(3), resp.GetMetric().GetDelta())
+	resp, err = client.Update(ctx, counter)
+	require.NoError(t, err)
+	assert.Equal(t, int64(6), resp.GetMetric().GetDelta())
+
+	_, err = client.Update(ctx, &pb.UpdateRequest{Metric: &pb.Metric{Id: "Alloc", Type: pb.Metric_GAUGE}})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+	_, err = client.BulkUpdate(ctx, &pb.BulkUpdateRequest{Metrics: []*pb.Metric{{Id: "Alloc", Value: &value}}})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+
+	_, err = client.BulkUpdate(ctx, &pb.BulkUpdateRequest{Metrics: []*pb.Metric{
+		{Id: "Alloc", Type: pb.Metric_GAUGE, Value: &value},
+		{Id: "PollCount", Type: pb.Metric_COUNTER, Delta: &delta},
+	}})
+	require.NoError(t, err)
+	got, err := client.Get(ctx, &pb.GetRequest{Id: "Alloc", Type: pb.Metric_GAUGE})
+	require.NoError(t, err)
+	assert.Equal(t, 12.5, got.GetMetric().GetValue())
+	_, err = client.Get(ctx, &pb.GetRequest{Id: "Missing", Type: pb.Metric_GAUGE})
+	assert.Equal(t, codes.NotFound, status.Code(err))
+
+	list, err := client.List(ctx, &pb.ListRequest{})
+	require.NoError(t, err)
+	require.Len(t, list.GetMetrics(), 2)
+	assert.Equal(t, "PollCount", list.GetMetrics()[0].GetId())
+	assert.Equal(t, int64(9), list.GetMetrics()[0].GetDelta())
+	assert.Equal(t, "Alloc", list.GetMetrics()[1].GetId())
+
+	defer func(prev Config) { ServerConfig = prev }(ServerConfig)
+	ServerConfig.SignKey = "secret"
+	client = grpcClient(t)
+	signed := metadata.AppendToOutgoingContext(ctx, SignatureMetadata, "wrong")
+	_, err = client.Update(signed, counter)
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+}
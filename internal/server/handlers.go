@@ -2,11 +2,17 @@ package server
 
 import (
 	"io"
+	"math"
 	"net/http"
 	"strconv"
 
+	"github.com/NikolayStrekalov/vigilant-octo-waddle.git/internal/histogram"
+	"github.com/NikolayStrekalov/vigilant-octo-waddle.git/internal/hll"
+	"github.com/NikolayStrekalov/vigilant-octo-waddle.git/internal/logger"
 	"github.com/NikolayStrekalov/vigilant-octo-waddle.git/internal/models"
 	"github.com/NikolayStrekalov/vigilant-octo-waddle.git/internal/pgstorage"
+	"github.com/NikolayStrekalov/vigilant-octo-waddle.git/internal/series"
+	"github.com/NikolayStrekalov/vigilant-octo-waddle.git/internal/sketch"
 	"github.com/mailru/easyjson"
 
 	"github.com/go-chi/chi/v5"
@@ -20,28 +26,96 @@ const (
 	updateMetricPathJSON       = "/update/"
 	pingPath                   = "/ping"
 	bulkUpdatePath             = "/updates/"
+	historyPath                = "/history/{kind}/{name}"
+	alertsPath                 = "/alerts"
+	alertRulesPath             = "/api/alerts/rules"
+	alertRulePath              = "/api/alerts/rules/{id}"
+	maintenancePath            = "/api/maintenance"
+	maintenanceWindowPath      = "/api/maintenance/{id}"
+	anomaliesPath              = "/api/anomalies"
+	absentPath                 = "/api/absent"
+	agentsPath                 = "/api/agents"
+	prometheusPath             = "/metrics"
+	remoteWritePath            = "/api/v1/write"
+	otlpMetricsPath            = "/v1/metrics"
+	influxWritePath            = "/write"
 	messageInternalServerError = "InternalServerError"
 	gaugeKind                  = "gauge"
 	counterKind                = "counter"
+	histogramKind              = "histogram"
+	summaryKind                = "summary"
+	setKind                    = "set"
+	quantileParam              = "q"
 	metricNotFound             = "Metric not found!"
 	wrongMetricType            = "Wrong metric type!"
+	wrongTotal                 = "Total must not be negative!"
 	applicationJSONType        = "application/json"
 )
 
 func prepareRoutes(r *chi.Mux) {
 	r.Get(indexPath, indexHandler)
 	r.Get(getMetricPath, metricHandler)
+	r.Delete(getMetricPath, deleteMetricHandler)
+	r.Delete(deleteMetricsPath, deleteMetricsHandler)
+	r.Post(resetCounterPath, resetCounterHandler)
 	r.Post(updateMetricPath, updateMetricHandler)
 	r.Post(getMetricPathJSON, metricJSONHandler)
 	r.Post(updateMetricPathJSON, updateMetricJSONHandler)
 	r.Get(pingPath, pingHandler)
 	r.Post(bulkUpdatePath, bulkHandler)
+	r.Get(historyPath, historyHandler)
+	r.Get(alertsPath, alertsHandler)
+	r.Get(alertRulesPath, alertRulesHandler)
+	r.Post(alertRulesPath, addAlertRuleHandler)
+	r.Get(alertRulePath, alertRuleHandler)
+	r.Put(alertRulePath, updateAlertRuleHandler)
+	r.Delete(alertRulePath, deleteAlertRuleHandler)
+	r.Get(maintenancePath, maintenanceWindowsHandler)
+	r.Post(maintenancePath, addMaintenanceWindowHandler)
+	r.Get(maintenanceWindowPath, maintenanceWindowHandler)
+	r.Delete(maintenanceWindowPath, deleteMaintenanceWindowHandler)
+	r.Get(anomaliesPath, anomaliesHandler)
+	r.Get(absentPath, absentHandler)
+	r.Get(agentsPath, agentsHandler)
+	r.Get(ratePath, rateHandler)
+	r.Get(increasePath, increaseHandler)
+	r.Get(prometheusPath, prometheusHandler)
+	r.Post(remoteWritePath, remoteWriteHandler)
+	r.Post(otlpMetricsPath, otlpMetricsHandler)
+	r.Post(influxWritePath, influxWriteHandler)
 }
 
+// queryLabels возвращает метки из параметров запроса, например /value/gauge/FreeMemory?host=db1.
+// Параметры reserved метками не считаются.
+func queryLabels(req *http.Request, reserved ...string) map[string]string {
+	query := req.URL.Query()
+	for _, k := range reserved {
+		query.Del(k)
+	}
+	if len(query) == 0 {
+		return nil
+	}
+	labels := make(map[string]string, len(query))
+	for k := range query {
+		labels[k] = query.Get(k)
+	}
+	return labels
+}
+
+// indexHandler показывает серии, у которых есть все метки из параметров запроса.
 func indexHandler(res http.ResponseWriter, req *http.Request) {
-	counters := Storage.GetCounterList()
-	gauges := Storage.GetGaugeList()
-	html, err := renderIndexPage(counters, gauges)
+	selector := queryLabels(req)
+	args := &templateArgs{
+		Counter:   filterSeries(Storage.GetCounterList(), func(c *CounterListItem) string { return c.Name }, selector),
+		Gauge:     filterSeries(Storage.GetGaugeList(), func(g *GaugeListItem) string { return g.Name }, selector),
+		Histogram: filterSeries(Storage.GetHistogramList(), func(h *HistogramListItem) string { return h.Name }, selector),
+		Summary:   filterSeries(Storage.GetSummaryList(), func(s *SummaryListItem) string { return s.Name }, selector),
+		Set:       filterSeries(Storage.GetSetList(), func(s *SetListItem) string { return s.Name }, selector),
+	}
+	if agents, err := Storage.GetAgents(); err == nil {
+		args.Agents = agents
+	}
+	html, err := renderIndexPage(args)
 	if err != nil {
 		http.Error(res, messageInternalServerError, http.StatusInternalServerError)
 		return
@@ -56,7 +130,7 @@ func indexHandler(res http.ResponseWriter, req *http.Request) {
 
 func metricHandler(res http.ResponseWriter, req *http.Request) {
 	kind := chi.URLParam(req, "kind")
-	name := chi.URLParam(req, "name")
+	name := series.Key(chi.URLParam(req, "name"), queryLabels(req, quantileParam))
 	switch kind {
 	case gaugeKind:
 		v, err := Storage.GetGauge(name)
@@ -76,6 +150,24 @@ func metricHandler(res http.ResponseWriter, req *http.Request) {
 		if _, err := io.WriteString(res, strconv.FormatInt(v, 10)); err != nil {
 			http.Error(res, messageInternalServerError, http.StatusInternalServerError)
 		}
+	case histogramKind:
+		v, err := Storage.GetHistogram(name)
+		if err != nil {
+			http.Error(res, metricNotFound, http.StatusNotFound)
+			return
+		}
+		writeJSON(res, http.StatusOK, &v)
+	case summaryKind:
+		quantilesHandler(res, req, name)
+	case setKind:
+		v, err := Storage.GetSet(name)
+		if err != nil {
+			http.Error(res, metricNotFound, http.StatusNotFound)
+			return
+		}
+		if _, err := io.WriteString(res, strconv.FormatUint(hll.Count(&v), 10)); err != nil {
+			http.Error(res, messageInternalServerError, http.StatusInternalServerError)
+		}
 	default:
 		http.Error(res, wrongMetricType, http.StatusNotFound)
 		return
@@ -91,14 +183,61 @@ func updateMetricHandler(res http.ResponseWriter, req *http.Request) {
 			http.Error(res, "Wrong float value!", http.StatusBadRequest)
 			return
 		}
+		flagged, ok := admitUpdate(res, chi.URLParam(req, "name"))
+		if !ok {
+			return
+		}
 		Storage.UpdateGauge(chi.URLParam(req, "name"), val)
+		markUpdated(agentName(req), kind, chi.URLParam(req, "name"), flagged)
 	case counterKind:
 		val, err := strconv.ParseInt(chi.URLParam(req, "value"), 10, 64)
 		if err != nil {
 			http.Error(res, "Wrong integer value!", http.StatusBadRequest)
 			return
 		}
+		flagged, ok := admitUpdate(res, chi.URLParam(req, "name"))
+		if !ok {
+			return
+		}
 		Storage.IncrementCounter(chi.URLParam(req, "name"), val)
+		markUpdated(agentName(req), kind, chi.URLParam(req, "name"), flagged)
+	case histogramKind:
+		val, err := strconv.ParseFloat(chi.URLParam(req, "value"), 64)
+		if err != nil || math.IsNaN(val) || math.IsInf(val, 0) {
+			http.Error(res, "Wrong float value!", http.StatusBadRequest)
+			return
+		}
+		flagged, ok := admitUpdate(res, chi.URLParam(req, "name"))
+		if !ok {
+			return
+		}
+		err = Storage.UpdateHistogram(chi.URLParam(req, "name"), observation(chi.URLParam(req, "name"), val))
+		if !histogramUpdated(res, err) {
+			return
+		}
+		markUpdated(agentName(req), kind, chi.URLParam(req, "name"), flagged)
+	case summaryKind:
+		val, err := strconv.ParseFloat(chi.URLParam(req, "value"), 64)
+		if err != nil || math.IsNaN(val) || math.IsInf(val, 0) {
+			http.Error(res, "Wrong float value!", http.StatusBadRequest)
+			return
+		}
+		flagged, ok := admitUpdate(res, chi.URLParam(req, "name"))
+		if !ok {
+			return
+		}
+		err = Storage.UpdateSummary(chi.URLParam(req, "name"), summaryObservation(chi.URLParam(req, "name"), val))
+		if !summaryUpdated(res, err) {
+			return
+		}
+		markUpdated(agentName(req), kind, chi.URLParam(req, "name"), flagged)
+	case setKind:
+		flagged, ok := admitUpdate(res, chi.URLParam(req, "name"))
+		if !ok {
+			return
+		}
+		Storage.UpdateSet(chi.URLParam(req, "name"), setMembers(chi.URLParam(req, "name"), chi.URLParam(req, "value")))
+		markUpdated(agentName(req), kind, chi.URLParam(req, "name"), flagged)
 	default:
 		http.Error(res, wrongMetricType, http.StatusBadRequest)
 		return
@@ -120,25 +259,50 @@ func metricJSONHandler(res http.ResponseWriter, req *http.Request) {
 		http.Error(res, "Wrong json provided.", http.StatusBadRequest)
 		return
 	}
+	key := m.Key()
 	switch m.MType {
 	case counterKind:
-		v, err := Storage.GetCounter(m.ID)
+		v, err := Storage.GetCounter(key)
 		if err != nil {
 			http.Error(res, metricNotFound, http.StatusNotFound)
 			return
 		}
 		m.Delta = &v
 	case gaugeKind:
-		v, err := Storage.GetGauge(m.ID)
+		v, err := Storage.GetGauge(key)
 		if err != nil {
 			http.Error(res, metricNotFound, http.StatusNotFound)
 			return
 		}
 		m.Value = &v
+	case histogramKind:
+		v, err := Storage.GetHistogram(key)
+		if err != nil {
+			http.Error(res, metricNotFound, http.StatusNotFound)
+			return
+		}
+		m.Histogram = &v
+	case summaryKind:
+		v, err := Storage.GetSummary(key)
+		if err != nil {
+			http.Error(res, metricNotFound, http.StatusNotFound)
+			return
+		}
+		m.Sketch = &v
+	case setKind:
+		v, err := Storage.GetSet(key)
+		if err != nil {
+			http.Error(res, metricNotFound, http.StatusNotFound)
+			return
+		}
+		count := hll.Count(&v)
+		m.HLL = &v
+		m.Cardinality = &count
 	default:
 		http.Error(res, wrongMetricType, http.StatusBadRequest)
 		return
 	}
+	m.Maintenance = Maintenance.Flagged(m.MType, key)
 	rawBytes, err := easyjson.Marshal(&m)
 	if err != nil {
 		http.Error(res, messageInternalServerError, http.StatusInternalServerError)
@@ -167,35 +331,128 @@ func updateMetricJSONHandler(res http.ResponseWriter, req *http.Request) {
 		http.Error(res, "Wrong json provided.", http.StatusBadRequest)
 		return
 	}
+	if err := series.Validate(m.Labels); err != nil {
+		http.Error(res, "Wrong labels: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+	key := m.Key()
 	switch m.MType {
 	case counterKind:
-		if m.Delta == nil {
+		if m.Delta == nil && m.Total == nil {
 			http.Error(res, "Provide delta field for increment!", http.StatusBadRequest)
 			return
 		}
-		Storage.IncrementCounter(m.ID, *m.Delta)
-		v, err := Storage.GetCounter(m.ID)
+		if m.Total != nil && *m.Total < 0 {
+			http.Error(res, wrongTotal, http.StatusBadRequest)
+			return
+		}
+		flagged, ok := admitUpdate(res, key)
+		if !ok {
+			return
+		}
+		if m.Total != nil {
+			Storage.AddCounterTotal(key, *m.Total)
+		} else {
+			Storage.IncrementCounter(key, *m.Delta)
+		}
+		markUpdated(agentName(req), m.MType, key, flagged)
+		v, err := Storage.GetCounter(key)
 		if err != nil {
 			http.Error(res, metricNotFound, http.StatusNotFound)
 			return
 		}
-		*m.Delta = v
+		m.Delta = &v
 	case gaugeKind:
 		if m.Value == nil {
 			http.Error(res, "Provide value field for update!", http.StatusBadRequest)
 			return
 		}
-		Storage.UpdateGauge(m.ID, *m.Value)
-		v, err := Storage.GetGauge(m.ID)
+		flagged, ok := admitUpdate(res, key)
+		if !ok {
+			return
+		}
+		Storage.UpdateGauge(key, *m.Value)
+		markUpdated(agentName(req), m.MType, key, flagged)
+		v, err := Storage.GetGauge(key)
 		if err != nil {
 			http.Error(res, metricNotFound, http.StatusNotFound)
 			return
 		}
 		*m.Value = v
+	case histogramKind:
+		if m.Histogram == nil {
+			http.Error(res, "Provide histogram field for update!", http.StatusBadRequest)
+			return
+		}
+		if err := histogram.Validate(m.Histogram); err != nil {
+			http.Error(res, "Wrong histogram: "+err.Error(), http.StatusBadRequest)
+			return
+		}
+		flagged, ok := admitUpdate(res, key)
+		if !ok {
+			return
+		}
+		if !histogramUpdated(res, Storage.UpdateHistogram(key, m.Histogram)) {
+			return
+		}
+		markUpdated(agentName(req), m.MType, key, flagged)
+		v, err := Storage.GetHistogram(key)
+		if err != nil {
+			http.Error(res, metricNotFound, http.StatusNotFound)
+			return
+		}
+		m.Histogram = &v
+	case summaryKind:
+		if m.Sketch == nil {
+			http.Error(res, "Provide sketch field for update!", http.StatusBadRequest)
+			return
+		}
+		if err := sketch.Validate(m.Sketch); err != nil {
+			http.Error(res, "Wrong sketch: "+err.Error(), http.StatusBadRequest)
+			return
+		}
+		flagged, ok := admitUpdate(res, key)
+		if !ok {
+			return
+		}
+		if !summaryUpdated(res, Storage.UpdateSummary(key, m.Sketch)) {
+			return
+		}
+		markUpdated(agentName(req), m.MType, key, flagged)
+		v, err := Storage.GetSummary(key)
+		if err != nil {
+			http.Error(res, metricNotFound, http.StatusNotFound)
+			return
+		}
+		m.Sketch = &v
+	case setKind:
+		if m.HLL == nil && len(m.Members) == 0 {
+			http.Error(res, "Provide hll or members field for update!", http.StatusBadRequest)
+			return
+		}
+		if err := setUpdate(&m); err != nil {
+			http.Error(res, "Wrong hll: "+err.Error(), http.StatusBadRequest)
+			return
+		}
+		flagged, ok := admitUpdate(res, key)
+		if !ok {
+			return
+		}
+		Storage.UpdateSet(key, m.HLL)
+		markUpdated(agentName(req), m.MType, key, flagged)
+		v, err := Storage.GetSet(key)
+		if err != nil {
+			http.Error(res, metricNotFound, http.StatusNotFound)
+			return
+		}
+		count := hll.Count(&v)
+		m.HLL = nil
+		m.Cardinality = &count
 	default:
 		http.Error(res, wrongMetricType, http.StatusBadRequest)
 		return
 	}
+	m.Maintenance = Maintenance.Flagged(m.MType, key)
 	rawBytes, err := easyjson.Marshal(&m)
 	if err != nil {
 		http.Error(res, messageInternalServerError, http.StatusInternalServerError)
@@ -233,6 +490,59 @@ func bulkHandler(res http.ResponseWriter, req *http.Request) {
 		http.Error(res, "Wrong json provided.", http.StatusBadRequest)
 		return
 	}
-	Storage.BulkUpdate(metrics)
+	for i := range metrics {
+		if err := series.Validate(metrics[i].Labels); err != nil {
+			http.Error(res, "Wrong labels: "+err.Error(), http.StatusBadRequest)
+			return
+		}
+		if metrics[i].MType == histogramKind && metrics[i].Histogram != nil {
+			if err := histogram.Validate(metrics[i].Histogram); err != nil {
+				http.Error(res, "Wrong histogram: "+err.Error(), http.StatusBadRequest)
+				return
+			}
+		}
+		if metrics[i].MType == summaryKind && metrics[i].Sketch != nil {
+			if err := sketch.Validate(metrics[i].Sketch); err != nil {
+				http.Error(res, "Wrong sketch: "+err.Error(), http.StatusBadRequest)
+				return
+			}
+		}
+		if metrics[i].MType == counterKind && metrics[i].Total != nil && *metrics[i].Total < 0 {
+			http.Error(res, wrongTotal, http.StatusBadRequest)
+			return
+		}
+		if metrics[i].MType == setKind {
+			if err := setUpdate(&metrics[i]); err != nil {
+				http.Error(res, "Wrong hll: "+err.Error(), http.StatusBadRequest)
+				return
+			}
+		}
+	}
+	if err := checkHistogramBounds(metrics); err != nil {
+		http.Error(res, "Wrong histogram: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+	if err := checkSummaryAlpha(metrics); err != nil {
+		http.Error(res, "Wrong sketch: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+	key := req.Header.Get(IdempotencyKeyHeader)
+	seq, err := parseBatchKey(key, req.Header.Get(IdempotencySequenceHeader))
+	if err != nil {
+		http.Error(res, "Wrong idempotency key or sequence!", http.StatusBadRequest)
+		return
+	}
+	agent, err := agentInfo(req.Header.Get)
+	if err != nil {
+		http.Error(res, "Wrong agent: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+	count := len(metrics)
+	if err := ingestOnce(metrics, agentName(req), key, seq); err != nil {
+		logger.Info("error applying batch:", err)
+		http.Error(res, messageInternalServerError, http.StatusInternalServerError)
+		return
+	}
+	registerAgent(&agent, agentAddress(req), count)
 	res.WriteHeader(http.StatusOK)
 }
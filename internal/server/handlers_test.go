@@ -5,8 +5,10 @@ import (
 	"io"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
 
+	"github.com/NikolayStrekalov/vigilant-octo-waddle.git/internal/logger"
 	"github.com/NikolayStrekalov/vigilant-octo-waddle.git/internal/memstorage"
 	"github.com/go-chi/chi/v5"
 	"github.com/stretchr/testify/assert"
@@ -392,3 +394,193 @@ func Test_bulkHandler(t *testing.T) {
 		})
 	}
 }
+
+func Test_metricLabels(t *testing.T) {
+	_ = logger.InitLog()
+	storage, _, _ := memstorage.NewMemStorage("", false, 300)
+	Storage = storage
+	r := chi.NewRouter()
+	prepareRoutes(r)
+
+	tests := []struct {
+		name   string
+		method string
+		path   string
+		body   string
+		status int
+		want   string
+	}{
+		{
+			name:   "Update without labels",
+			method: http.MethodPost,
+			path:   "/update/",
+			body:   `{"id":"FreeMemory","type":"gauge","value":1}`,
+			status: http.StatusOK,
+			want:   `{"id":"FreeMemory","type":"gauge","value":1}`,
+		},
+		{
+			name:   "Update with labels",
+			method: http.MethodPost,
+			path:   "/update/",
+			body:   `{"id":"FreeMemory","type":"gauge","value":2,"labels":{"host":"db1"}}`,
+			status: http.StatusOK,
+			want:   `{"id":"FreeMemory","type":"gauge","value":2,"labels":{"host":"db1"}}`,
+		},
+		{
+			name:   "Bulk with labels",
+			method: http.MethodPost,
+			path:   "/updates/",
+			body:   `[{"id":"FreeMemory","type":"gauge","value":3,"labels":{"host":"db2"}}]`,
+			status: http.StatusOK,
+		},
+		{
+			name:   "Wrong label name",
+			method: http.MethodPost,
+			path:   "/updates/",
+			body:   `[{"id":"FreeMemory","type":"gauge","value":3,"labels":{"host.name":"db2"}}]`,
+			status: http.StatusBadRequest,
+		},
+		{
+			name:   "Value without labels",
+			method: http.MethodPost,
+			path:   "/value/",
+			body:   `{"id":"FreeMemory","type":"gauge"}`,
+			status: http.StatusOK,
+			want:   `{"id":"FreeMemory","type":"gauge","value":1}`,
+		},
+		{
+			name:   "Value with labels",
+			method: http.MethodPost,
+			path:   "/value/",
+			body:   `{"id":"FreeMemory","type":"gauge","labels":{"host":"db2"}}`,
+			status: http.StatusOK,
+			want:   `{"id":"FreeMemory","type":"gauge","value":3,"labels":{"host":"db2"}}`,
+		},
+		{name: "Plain value", method: http.MethodGet, path: "/value/gauge/FreeMemory?host=db1", status: http.StatusOK},
+		{
+			name:   "Unknown labels",
+			method: http.MethodGet,
+			path:   "/value/gauge/FreeMemory?host=db3",
+			status: http.StatusNotFound,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, "http://localhost:8080"+tt.path, strings.NewReader(tt.body))
+			req.Header.Set("Content-Type", applicationJSONType)
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+			res := w.Result()
+			defer func() {
+				_ = res.Body.Close()
+			}()
+			assert.Equal(t, tt.status, res.StatusCode)
+			if tt.want != "" {
+				data, err := io.ReadAll(res.Body)
+				require.NoError(t, err)
+				assert.JSONEq(t, tt.want, string(data))
+			}
+		})
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "http://localhost:8080/?host=db2", http.NoBody)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	res := w.Result()
+	defer func() {
+		_ = res.Body.Close()
+	}()
+	data, err := io.ReadAll(res.Body)
+	require.NoError(t, err)
+	assert.Contains(t, string(data), "db2")
+	assert.NotContains(t, string(data), "db1")
+}
+
+func Test_counterTotal(t *testing.T) {
+	_ = logger.InitLog()
+	storage, _, _ := memstorage.NewMemStorage("", false, 300)
+	Storage = storage
+	r := chi.NewRouter()
+	prepareRoutes(r)
+
+	tests := []struct {
+		name   string
+		path   string
+		body   string
+		status int
+		want   string
+	}{
+		{
+			name:   "First total",
+			path:   "/update/",
+			body:   `{"id":"Requests","type":"counter","total":10}`,
+			status: http.StatusOK,
+			want:   `{"id":"Requests","type":"counter","total":10,"delta":10}`,
+		},
+		{
+			name:   "Growth",
+			path:   "/update/",
+			body:   `{"id":"Requests","type":"counter","total":15}`,
+			status: http.StatusOK,
+			want:   `{"id":"Requests","type":"counter","total":15,"delta":15}`,
+		},
+		{
+			name:   "Retry",
+			path:   "/update/",
+			body:   `{"id":"Requests","type":"counter","total":15}`,
+			status: http.StatusOK,
+			want:   `{"id":"Requests","type":"counter","total":15,"delta":15}`,
+		},
+		{
+			name:   "Reset",
+			path:   "/update/",
+			body:   `{"id":"Requests","type":"counter","total":4}`,
+			status: http.StatusOK,
+			want:   `{"id":"Requests","type":"counter","total":4,"delta":19}`,
+		},
+		{
+			name:   "Delta still works",
+			path:   "/update/",
+			body:   `{"id":"Requests","type":"counter","delta":1}`,
+			status: http.StatusOK,
+			want:   `{"id":"Requests","type":"counter","delta":20}`,
+		},
+		{
+			name:   "Bulk retry",
+			path:   "/updates/",
+			body:   `[{"id":"Requests","type":"counter","total":6},{"id":"Requests","type":"counter","total":6}]`,
+			status: http.StatusOK,
+		},
+		{
+			name:   "Negative total",
+			path:   "/updates/",
+			body:   `[{"id":"Requests","type":"counter","total":-1}]`,
+			status: http.StatusBadRequest,
+		},
+		{
+			name:   "Value",
+			path:   "/value/",
+			body:   `{"id":"Requests","type":"counter"}`,
+			status: http.StatusOK,
+			want:   `{"id":"Requests","type":"counter","delta":22}`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "http://localhost:8080"+tt.path, strings.NewReader(tt.body))
+			req.Header.Set("Content-Type", applicationJSONType)
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+			res := w.Result()
+			defer func() {
+				_ = res.Body.Close()
+			}()
+			assert.Equal(t, tt.status, res.StatusCode)
+			if tt.want != "" {
+				data, err := io.ReadAll(res.Body)
+				require.NoError(t, err)
+				assert.JSONEq(t, tt.want, string(data))
+			}
+		})
+	}
+}
@@ -0,0 +1,62 @@
+package server
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/NikolayStrekalov/vigilant-octo-waddle.git/internal/histogram"
+	"github.com/NikolayStrekalov/vigilant-octo-waddle.git/internal/logger"
+	"github.com/NikolayStrekalov/vigilant-octo-waddle.git/internal/models"
+)
+
+// observation возвращает приращение гистограммы name с одним наблюдением value.
+// Берутся границы уже накопленной гистограммы, чтобы наблюдение не сбросило её данные.
+func observation(name string, value float64) *models.Histogram {
+	bounds := ServerConfig.HistogramBounds
+	if h, err := Storage.GetHistogram(name); err == nil {
+		bounds = h.Bounds
+	}
+	delta := histogram.New(bounds)
+	histogram.Observe(&delta, value)
+	return &delta
+}
+
+// histogramUpdated отвечает на ошибку обновления гистограммы: 400 при других границах корзин, иначе 500.
+func histogramUpdated(res http.ResponseWriter, err error) bool {
+	switch {
+	case err == nil:
+		return true
+	case errors.Is(err, histogram.ErrBoundsMismatch):
+		http.Error(res, "Wrong histogram: "+err.Error(), http.StatusBadRequest)
+	default:
+		logger.Info("error updating histogram:", err)
+		http.Error(res, messageInternalServerError, http.StatusInternalServerError)
+	}
+	return false
+}
+
+// checkHistogramBounds проверяет, что гистограммы пачки можно прибавить к накопленным: границы корзин
+// должны совпадать с сохранёнными и с другими гистограммами той же серии в пачке.
+func checkHistogramBounds(metrics models.MetricsSlice) error {
+	seen := make(map[string]*models.Histogram)
+	for i := range metrics {
+		if metrics[i].MType != histogramKind || metrics[i].Histogram == nil {
+			continue
+		}
+		key := metrics[i].Key()
+		prev, ok := seen[key]
+		if !ok {
+			if h, err := Storage.GetHistogram(key); err == nil {
+				prev, ok = &h, true
+			}
+		}
+		if ok {
+			if err := histogram.Compatible(prev, metrics[i].Histogram); err != nil {
+				return fmt.Errorf("%s: %w", key, err)
+			}
+		}
+		seen[key] = metrics[i].Histogram
+	}
+	return nil
+}
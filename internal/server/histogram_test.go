@@ -0,0 +1,138 @@
+package server
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/NikolayStrekalov/vigilant-octo-waddle.git/internal/logger"
+	"github.com/NikolayStrekalov/vigilant-octo-waddle.git/internal/memstorage"
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_histogramHandlers(t *testing.T) {
+	_ = logger.InitLog()
+	storage, _, _ := memstorage.NewMemStorage("", false, 300)
+	Storage = storage
+	ServerConfig.HistogramBounds = []float64{0.1, 1}
+	defer func() { ServerConfig.HistogramBounds = nil }()
+	r := chi.NewRouter()
+	prepareRoutes(r)
+
+	tests := []struct {
+		name   string
+		method string
+		path   string
+		body   string
+		status int
+		want   string
+	}{
+		{name: "Observe", method: http.MethodPost, path: "/update/histogram/latency/0.05", status: http.StatusOK},
+		{name: "Observe again", method: http.MethodPost, path: "/update/histogram/latency/2", status: http.StatusOK},
+		{name: "Wrong value", method: http.MethodPost, path: "/update/histogram/latency/NaN", status: http.StatusBadRequest},
+		{
+			name:   "Value",
+			method: http.MethodGet,
+			path:   "/value/histogram/latency",
+			status: http.StatusOK,
+			want:   `{"bounds":[0.1,1],"counts":[1,0,1],"sum":2.05,"count":2}`,
+		},
+		{
+			name:   "Update JSON",
+			method: http.MethodPost,
+			path:   "/update/",
+			body:   `{"id":"latency","type":"histogram","histogram":{"bounds":[0.1,1],"counts":[0,3,0],"sum":1.5,"count":3}}`,
+			status: http.StatusOK,
+			want: `{"id":"latency","type":"histogram",
+				"histogram":{"bounds":[0.1,1],"counts":[1,3,1],"sum":3.55,"count":5}}`,
+		},
+		{
+			name:   "Update JSON with other bounds",
+			method: http.MethodPost,
+			path:   "/update/",
+			body:   `{"id":"latency","type":"histogram","histogram":{"bounds":[5],"counts":[1,0],"sum":1,"count":1}}`,
+			status: http.StatusBadRequest,
+		},
+		{
+			name:   "Update JSON without histogram",
+			method: http.MethodPost,
+			path:   "/update/",
+			body:   `{"id":"latency","type":"histogram"}`,
+			status: http.StatusBadRequest,
+		},
+		{
+			name:   "Bulk",
+			method: http.MethodPost,
+			path:   "/updates/",
+			body:   `[{"id":"size","type":"histogram","histogram":{"bounds":[10],"counts":[1,1],"sum":25,"count":2}}]`,
+			status: http.StatusOK,
+		},
+		{
+			name:   "Bulk with other bounds",
+			method: http.MethodPost,
+			path:   "/updates/",
+			body:   `[{"id":"size","type":"histogram","histogram":{"bounds":[20],"counts":[1,0],"sum":5,"count":1}}]`,
+			status: http.StatusBadRequest,
+		},
+		{
+			name:   "Bulk with different bounds in one batch",
+			method: http.MethodPost,
+			path:   "/updates/",
+			body: `[{"id":"new","type":"histogram","histogram":{"bounds":[1],"counts":[1,0],"sum":0.5,"count":1}},
+				{"id":"new","type":"histogram","histogram":{"bounds":[2],"counts":[1,0],"sum":0.5,"count":1}}]`,
+			status: http.StatusBadRequest,
+		},
+		{
+			name:   "Bulk with wrong counts",
+			method: http.MethodPost,
+			path:   "/updates/",
+			body:   `[{"id":"size","type":"histogram","histogram":{"bounds":[10],"counts":[1],"sum":25,"count":1}}]`,
+			status: http.StatusBadRequest,
+		},
+		{
+			name:   "Value JSON",
+			method: http.MethodPost,
+			path:   "/value/",
+			body:   `{"id":"size","type":"histogram"}`,
+			status: http.StatusOK,
+			want:   `{"id":"size","type":"histogram","histogram":{"bounds":[10],"counts":[1,1],"sum":25,"count":2}}`,
+		},
+		{name: "Missing", method: http.MethodGet, path: "/value/histogram/unknown", status: http.StatusNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, "http://localhost:8080"+tt.path, strings.NewReader(tt.body))
+			req.Header.Set("Content-Type", applicationJSONType)
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+			res := w.Result()
+			defer func() {
+				_ = res.Body.Close()
+			}()
+			assert.Equal(t, tt.status, res.StatusCode)
+			if tt.want != "" {
+				data, err := io.ReadAll(res.Body)
+				require.NoError(t, err)
+				assert.JSONEq(t, tt.want, string(data))
+			}
+		})
+	}
+
+	buf := renderPrometheus(nil, nil, Storage.GetHistogramList(), nil, nil, false)
+	assert.Equal(t, `# TYPE latency histogram
+latency_bucket{le="0.1"} 1
+latency_bucket{le="1"} 4
+latency_bucket{le="+Inf"} 5
+latency_sum 3.55
+latency_count 5
+# TYPE size histogram
+size_bucket{le="10"} 1
+size_bucket{le="+Inf"} 2
+size_sum 25
+size_count 2
+`, buf.String())
+}
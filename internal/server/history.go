@@ -0,0 +1,134 @@
+package server
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/NikolayStrekalov/vigilant-octo-waddle.git/internal/logger"
+	"github.com/NikolayStrekalov/vigilant-octo-waddle.git/internal/memstorage"
+	"github.com/NikolayStrekalov/vigilant-octo-waddle.git/internal/models"
+	"github.com/NikolayStrekalov/vigilant-octo-waddle.git/internal/series"
+	"github.com/go-chi/chi/v5"
+	"github.com/mailru/easyjson"
+)
+
+const defaultHistoryRange = time.Hour
+
+var (
+	errWrongHistoryRange = errors.New("from must not be after to")
+
+	historyParams = []string{"from", "to", "step"} // параметры запроса истории, не метки серии
+)
+
+type historyQuery struct {
+	from time.Time
+	to   time.Time
+	step time.Duration
+}
+
+// parseHistoryQuery разбирает параметры from, to и step.
+// Время принимается в формате RFC3339 или unix-секундах, шаг — как длительность Go или в секундах.
+func parseHistoryQuery(values url.Values, now time.Time) (historyQuery, error) {
+	q := historyQuery{to: now}
+	var err error
+	if v := values.Get("to"); v != "" {
+		if q.to, err = parseHistoryTime(v); err != nil {
+			return q, fmt.Errorf("wrong to: %w", err)
+		}
+	}
+	q.from = q.to.Add(-defaultHistoryRange)
+	if v := values.Get("from"); v != "" {
+		if q.from, err = parseHistoryTime(v); err != nil {
+			return q, fmt.Errorf("wrong from: %w", err)
+		}
+	}
+	if q.from.After(q.to) {
+		return q, errWrongHistoryRange
+	}
+	if v := values.Get("step"); v != "" {
+		if q.step, err = parseHistoryStep(v); err != nil {
+			return q, fmt.Errorf("wrong step: %w", err)
+		}
+	}
+	return q, nil
+}
+
+func parseHistoryTime(v string) (time.Time, error) {
+	if sec, err := strconv.ParseInt(v, 10, 64); err == nil {
+		return time.Unix(sec, 0), nil
+	}
+	t, err := time.Parse(time.RFC3339, v)
+	if err != nil {
+		return t, fmt.Errorf("can't parse time %s: %w", v, err)
+	}
+	return t, nil
+}
+
+func parseHistoryStep(v string) (time.Duration, error) {
+	if sec, err := strconv.ParseUint(v, 10, 32); err == nil {
+		return time.Duration(sec) * time.Second, nil
+	}
+	d, err := time.ParseDuration(v)
+	if err != nil {
+		return d, fmt.Errorf("can't parse duration %s: %w", v, err)
+	}
+	if d < 0 {
+		return d, fmt.Errorf("negative duration %s", v)
+	}
+	return d, nil
+}
+
+// compactHistory периодически сворачивает и очищает историю в хранилище.
+func compactHistory(interval time.Duration) {
+	since := time.Now().Add(-ServerConfig.HistoryPolicy.Raw)
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		<-ticker.C
+		now := time.Now()
+		if err := Storage.CompactHistory(since, now); err != nil {
+			logger.Info("error compacting history:", err)
+			continue
+		}
+		since = now
+	}
+}
+
+func historyHandler(res http.ResponseWriter, req *http.Request) {
+	kind := chi.URLParam(req, "kind")
+	if kind != gaugeKind && kind != counterKind {
+		http.Error(res, wrongMetricType, http.StatusNotFound)
+		return
+	}
+	q, err := parseHistoryQuery(req.URL.Query(), time.Now())
+	if err != nil {
+		http.Error(res, err.Error(), http.StatusBadRequest)
+		return
+	}
+	ret := models.MetricHistory{ID: chi.URLParam(req, "name"), MType: kind, Labels: queryLabels(req, historyParams...)}
+	points, err := Storage.GetHistory(kind, series.Key(ret.ID, ret.Labels), q.from, q.to, q.step)
+	if errors.Is(err, memstorage.ErrNotSupported) {
+		http.Error(res, "History is not supported by the storage!", http.StatusNotImplemented)
+		return
+	}
+	if err != nil {
+		logger.Info("error getting history:", err)
+		http.Error(res, messageInternalServerError, http.StatusInternalServerError)
+		return
+	}
+	ret.Points = points
+	rawBytes, err := easyjson.Marshal(&ret)
+	if err != nil {
+		http.Error(res, messageInternalServerError, http.StatusInternalServerError)
+		return
+	}
+	res.Header().Set("Content-Type", applicationJSONType)
+	res.WriteHeader(http.StatusOK)
+	if _, err := res.Write(rawBytes); err != nil {
+		http.Error(res, messageInternalServerError, http.StatusInternalServerError)
+	}
+}
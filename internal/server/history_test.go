@@ -0,0 +1,114 @@
+package server
+
+import (
+	"net/http"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/NikolayStrekalov/vigilant-octo-waddle.git/internal/history"
+	"github.com/NikolayStrekalov/vigilant-octo-waddle.git/internal/logger"
+	"github.com/NikolayStrekalov/vigilant-octo-waddle.git/internal/memstorage"
+	"github.com/NikolayStrekalov/vigilant-octo-waddle.git/internal/models"
+	"github.com/go-chi/chi/v5"
+	"github.com/mailru/easyjson"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_parseHistoryQuery(t *testing.T) {
+	now := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name    string
+		query   string
+		want    historyQuery
+		wantErr bool
+	}{
+		{
+			name:  "Defaults",
+			query: "",
+			want:  historyQuery{from: now.Add(-time.Hour), to: now},
+		},
+		{
+			name:  "RFC3339 and duration step",
+			query: "from=2024-06-01T10:00:00Z&to=2024-06-01T11:00:00Z&step=5m",
+			want: historyQuery{
+				from: time.Date(2024, 6, 1, 10, 0, 0, 0, time.UTC),
+				to:   time.Date(2024, 6, 1, 11, 0, 0, 0, time.UTC),
+				step: 5 * time.Minute,
+			},
+		},
+		{
+			name:  "Unix seconds",
+			query: "from=1717236000&to=1717239600&step=60",
+			want: historyQuery{
+				from: time.Unix(1717236000, 0),
+				to:   time.Unix(1717239600, 0),
+				step: time.Minute,
+			},
+		},
+		{
+			name:    "From after to",
+			query:   "from=2024-06-01T13:00:00Z",
+			wantErr: true,
+		},
+		{
+			name:    "Wrong step",
+			query:   "step=fast",
+			wantErr: true,
+		},
+		{
+			name:    "Negative step",
+			query:   "step=-1m",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			values, err := url.ParseQuery(tt.query)
+			assert.Nil(t, err)
+			got, err := parseHistoryQuery(values, now)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.Nil(t, err)
+			assert.True(t, tt.want.from.Equal(got.from))
+			assert.True(t, tt.want.to.Equal(got.to))
+			assert.Equal(t, tt.want.step, got.step)
+		})
+	}
+}
+
+func Test_historyHandler(t *testing.T) {
+	_ = logger.InitLog()
+	storage, _, _ := memstorage.NewMemStorage("", false, 300)
+	Storage = storage
+	Storage.SetHistoryPolicy(history.Policy{Raw: time.Hour})
+	r := chi.NewRouter()
+	prepareRoutes(r)
+	Storage.UpdateGauge("Alloc", 1)
+	Storage.UpdateGauge(`Alloc{host="db1"}`, 2)
+
+	tests := []struct {
+		name   string
+		path   string
+		labels map[string]string
+		value  float64
+	}{
+		{name: "Without labels", path: "/history/gauge/Alloc", value: 1},
+		{name: "Labels", path: "/history/gauge/Alloc?host=db1&step=0", labels: map[string]string{"host": "db1"}, value: 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			status, body := getResponse(t, r, tt.path)
+			require.Equal(t, http.StatusOK, status)
+			got := models.MetricHistory{}
+			require.NoError(t, easyjson.Unmarshal([]byte(body), &got))
+			assert.Equal(t, "Alloc", got.ID)
+			assert.Equal(t, tt.labels, got.Labels)
+			require.Len(t, got.Points, 1)
+			assert.Equal(t, tt.value, got.Points[0].Value)
+		})
+	}
+}
@@ -0,0 +1,26 @@
+package server
+
+import (
+	"io"
+	"net/http"
+
+	"github.com/NikolayStrekalov/vigilant-octo-waddle.git/internal/influx"
+)
+
+// influxWriteHandler принимает запись в формате InfluxDB line protocol, как /write в InfluxDB 1.x.
+// Тело с ошибкой отклоняется целиком.
+func influxWriteHandler(res http.ResponseWriter, req *http.Request) {
+	data, err := io.ReadAll(req.Body)
+	defer func() { _ = req.Body.Close() }()
+	if err != nil {
+		http.Error(res, messageInternalServerError, http.StatusInternalServerError)
+		return
+	}
+	points, err := influx.Parse(string(data))
+	if err != nil {
+		http.Error(res, "Wrong line protocol: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+	ingest(influx.ToMetrics(points, ServerConfig.InfluxCounterRules), agentName(req))
+	res.WriteHeader(http.StatusNoContent)
+}
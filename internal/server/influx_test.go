@@ -0,0 +1,44 @@
+package server
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/NikolayStrekalov/vigilant-octo-waddle.git/internal/memstorage"
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_influxWriteHandler(t *testing.T) {
+	storage, _, _ := memstorage.NewMemStorage("", false, 300)
+	Storage = storage
+	ServerConfig.InfluxCounterRules = []string{"mem_active"}
+	defer func() { ServerConfig.InfluxCounterRules = nil }()
+	r := chi.NewRouter()
+	prepareRoutes(r)
+	post := func(body string) int {
+		req := httptest.NewRequest(http.MethodPost, "http://localhost:8080/write?db=telegraf", strings.NewReader(body))
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		res := w.Result()
+		_ = res.Body.Close()
+		return res.StatusCode
+	}
+
+	assert.Equal(t, http.StatusNoContent, post("mem,host=db1 used_percent=41.5,active=10i 1717243200000000000"))
+	assert.Equal(t, http.StatusNoContent, post("mem,host=db1 used_percent=42,active=25i,available=100i"))
+	assert.Equal(t, http.StatusNoContent, post("mem,host=db1 available=60i"))
+	assert.Equal(t, http.StatusBadRequest, post("mem used_percent=43\nmem"))
+
+	g, err := storage.GetGauge(`mem_used_percent{host="db1"}`)
+	assert.Nil(t, err)
+	assert.Equal(t, 42.0, g)
+	c, err := storage.GetCounter(`mem_active{host="db1"}`)
+	assert.Nil(t, err)
+	assert.Equal(t, int64(25), c)
+	g, err = storage.GetGauge(`mem_available{host="db1"}`)
+	assert.Nil(t, err)
+	assert.Equal(t, 60.0, g, "a decreasing integer field is a gauge")
+}
@@ -0,0 +1,166 @@
+package server
+
+import (
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/NikolayStrekalov/vigilant-octo-waddle.git/internal/logger"
+	"github.com/NikolayStrekalov/vigilant-octo-waddle.git/internal/maintenance"
+	"github.com/NikolayStrekalov/vigilant-octo-waddle.git/internal/models"
+	"github.com/go-chi/chi/v5"
+	"github.com/mailru/easyjson"
+)
+
+const (
+	metricInMaintenance = "Metric is in maintenance!"
+	windowNotFound      = "Maintenance window not found!"
+)
+
+var Maintenance = maintenance.NewSchedule()
+
+// reloadMaintenance загружает окна обслуживания из хранилища.
+func reloadMaintenance() {
+	if Storage == nil {
+		return
+	}
+	windows, err := Storage.GetMaintenanceWindows()
+	if err != nil {
+		logger.Info("can't load maintenance windows:", err)
+		return
+	}
+	Maintenance.SetWindows(windows)
+}
+
+// restoreMaintenanceFlags восстанавливает пометки обновлений во время обслуживания из хранилища
+// и сохраняет в него их изменения, чтобы пометки пережили перезапуск.
+func restoreMaintenanceFlags() {
+	flags, err := Storage.GetMaintenanceFlags()
+	if err != nil {
+		logger.Info("can't load maintenance flags:", err)
+	}
+	Maintenance.SetFlags(flags)
+	Maintenance.OnFlag(func(key string, flagged bool) {
+		if err := Storage.SetMaintenanceFlag(key, flagged); err != nil {
+			logger.Info("can't save maintenance flag:", err)
+		}
+	})
+}
+
+// admitUpdate проверяет окна обслуживания и отвечает клиенту, если обновление отклонено.
+// Возвращает также, нужно ли пометить обновление после сохранения.
+func admitUpdate(res http.ResponseWriter, name string) (flagged, ok bool) {
+	flagged, ok = Maintenance.Admit(name, time.Now())
+	if !ok {
+		http.Error(res, metricInMaintenance, http.StatusConflict)
+	}
+	return flagged, ok
+}
+
+// admitBulk убирает из пачки метрики, обновления которых отклоняются окнами обслуживания,
+// и возвращает пометки принятых.
+func admitBulk(metrics models.MetricsSlice) (models.MetricsSlice, []bool) {
+	now := time.Now()
+	admitted := metrics[:0]
+	flags := make([]bool, 0, len(metrics))
+	for i := range metrics {
+		if flagged, ok := Maintenance.Admit(metrics[i].Key(), now); ok {
+			admitted = append(admitted, metrics[i])
+			flags = append(flags, flagged)
+		}
+	}
+	return admitted, flags
+}
+
+// markUpdated запоминает пометку сохранённого обновления и отмечает его получение.
+func markUpdated(agent, kind, name string, flagged bool) {
+	Maintenance.Record(kind, name, flagged)
+	markSeen(agent, kind, name)
+}
+
+// markBulk вызывает markUpdated для сохранённой пачки.
+func markBulk(agent string, metrics models.MetricsSlice, flags []bool) {
+	for i := range metrics {
+		markUpdated(agent, metrics[i].MType, metrics[i].Key(), flags[i])
+	}
+}
+
+// ingest сохраняет пачку метрик с учётом окон обслуживания и отмечает их получение.
+func ingest(metrics models.MetricsSlice, agent string) {
+	metrics, flags := admitBulk(metrics)
+	Storage.BulkUpdate(metrics)
+	markBulk(agent, metrics, flags)
+}
+
+func maintenanceWindowsHandler(res http.ResponseWriter, req *http.Request) {
+	windows, err := Storage.GetMaintenanceWindows()
+	if err != nil {
+		http.Error(res, messageInternalServerError, http.StatusInternalServerError)
+		return
+	}
+	writeJSON(res, http.StatusOK, models.MaintenanceWindows(windows))
+}
+
+func maintenanceWindowHandler(res http.ResponseWriter, req *http.Request) {
+	id, err := strconv.ParseInt(chi.URLParam(req, "id"), 10, 64)
+	if err != nil {
+		http.Error(res, windowNotFound, http.StatusNotFound)
+		return
+	}
+	windows, err := Storage.GetMaintenanceWindows()
+	if err != nil {
+		http.Error(res, messageInternalServerError, http.StatusInternalServerError)
+		return
+	}
+	for i := range windows {
+		if windows[i].ID == id {
+			writeJSON(res, http.StatusOK, &windows[i])
+			return
+		}
+	}
+	http.Error(res, windowNotFound, http.StatusNotFound)
+}
+
+func addMaintenanceWindowHandler(res http.ResponseWriter, req *http.Request) {
+	if val, ok := req.Header["Content-Type"]; !ok || val[0] != applicationJSONType {
+		http.Error(res, "Wrong Content-Type, use application/json!", http.StatusBadRequest)
+		return
+	}
+	window := models.MaintenanceWindow{}
+	data, err := io.ReadAll(req.Body)
+	defer func() { _ = req.Body.Close() }()
+	if err != nil {
+		http.Error(res, messageInternalServerError, http.StatusInternalServerError)
+		return
+	}
+	if err := easyjson.Unmarshal(data, &window); err != nil {
+		http.Error(res, "Wrong json provided.", http.StatusBadRequest)
+		return
+	}
+	if err := maintenance.Validate(&window); err != nil {
+		http.Error(res, "Wrong maintenance window: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+	added, err := Storage.AddMaintenanceWindow(&window)
+	if err != nil {
+		http.Error(res, messageInternalServerError, http.StatusInternalServerError)
+		return
+	}
+	reloadMaintenance()
+	writeJSON(res, http.StatusCreated, &added)
+}
+
+func deleteMaintenanceWindowHandler(res http.ResponseWriter, req *http.Request) {
+	id, err := strconv.ParseInt(chi.URLParam(req, "id"), 10, 64)
+	if err != nil {
+		http.Error(res, windowNotFound, http.StatusNotFound)
+		return
+	}
+	if err := Storage.DeleteMaintenanceWindow(id); err != nil {
+		http.Error(res, windowNotFound, http.StatusNotFound)
+		return
+	}
+	reloadMaintenance()
+	res.WriteHeader(http.StatusNoContent)
+}
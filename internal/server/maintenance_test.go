@@ -0,0 +1,145 @@
+package server
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/NikolayStrekalov/vigilant-octo-waddle.git/internal/maintenance"
+	"github.com/NikolayStrekalov/vigilant-octo-waddle.git/internal/memstorage"
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_maintenance(t *testing.T) {
+	Storage, _, _ = memstorage.NewMemStorage("", false, 300)
+	Maintenance = maintenance.NewSchedule()
+	defer func() { Maintenance = maintenance.NewSchedule() }()
+	restoreMaintenanceFlags()
+	r := chi.NewRouter()
+	prepareRoutes(r)
+	start := time.Now().Add(-time.Minute).UTC().Format(time.RFC3339)
+	end := time.Now().Add(time.Hour).UTC().Format(time.RFC3339)
+	latency := `{"bounds":[1],"counts":[1,0],"sum":0.5,"count":1}`
+
+	tests := []struct {
+		name   string
+		method string
+		path   string
+		body   string
+		status int
+		want   string
+	}{
+		{
+			name:   "Histogram before window",
+			method: http.MethodPost,
+			path:   "/update/",
+			body:   `{"id":"CPUutilizationLatency","type":"histogram","histogram":` + latency + `}`,
+			status: http.StatusOK,
+		},
+		{
+			name:   "Flag window",
+			method: http.MethodPost,
+			path:   "/api/maintenance",
+			body:   `{"pattern":"CPUutilization*","start":"` + start + `","end":"` + end + `"}`,
+			status: http.StatusCreated,
+			want:   `{"id":1,"pattern":"CPUutilization*","mode":"flag","start":"` + start + `","end":"` + end + `"}`,
+		},
+		{
+			name:   "Reject window",
+			method: http.MethodPost,
+			path:   "/api/maintenance",
+			body:   `{"pattern":"FreeMemory","mode":"reject","start":"` + start + `","end":"` + end + `"}`,
+			status: http.StatusCreated,
+			want:   `{"id":2,"pattern":"FreeMemory","mode":"reject","start":"` + start + `","end":"` + end + `"}`,
+		},
+		{
+			name:   "Wrong window",
+			method: http.MethodPost,
+			path:   "/api/maintenance",
+			body:   `{"pattern":"FreeMemory","start":"` + end + `","end":"` + start + `"}`,
+			status: http.StatusBadRequest,
+		},
+		{
+			name:   "Rejected update",
+			method: http.MethodPost,
+			path:   "/update/gauge/FreeMemory/10",
+			status: http.StatusConflict,
+		},
+		{
+			name:   "Flagged update",
+			method: http.MethodPost,
+			path:   "/update/",
+			body:   `{"id":"CPUutilization1","type":"gauge","value":95}`,
+			status: http.StatusOK,
+			want:   `{"id":"CPUutilization1","type":"gauge","value":95,"maintenance":true}`,
+		},
+		{
+			name:   "Rejected histogram is not flagged",
+			method: http.MethodPost,
+			path:   "/update/",
+			body:   `{"id":"CPUutilizationLatency","type":"histogram","histogram":{"bounds":[2],"counts":[1,0],"count":1}}`,
+			status: http.StatusBadRequest,
+		},
+		{
+			name:   "Bulk update",
+			method: http.MethodPost,
+			path:   "/updates/",
+			body:   `[{"id":"FreeMemory","type":"gauge","value":1},{"id":"PollCount","type":"counter","delta":1}]`,
+			status: http.StatusOK,
+		},
+		{
+			name:   "Not flagged value",
+			method: http.MethodPost,
+			path:   "/value/",
+			body:   `{"id":"PollCount","type":"counter"}`,
+			status: http.StatusOK,
+			want:   `{"id":"PollCount","type":"counter","delta":1}`,
+		},
+		{name: "Rejected metric", method: http.MethodGet, path: "/value/gauge/FreeMemory", status: http.StatusNotFound},
+		{name: "Delete window", method: http.MethodDelete, path: "/api/maintenance/2", status: http.StatusNoContent},
+		{name: "Accepted update", method: http.MethodPost, path: "/update/gauge/FreeMemory/10", status: http.StatusOK},
+		{
+			name:   "List",
+			method: http.MethodGet,
+			path:   "/api/maintenance",
+			status: http.StatusOK,
+			want:   `[{"id":1,"pattern":"CPUutilization*","mode":"flag","start":"` + start + `","end":"` + end + `"}]`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, "http://localhost:8080"+tt.path, strings.NewReader(tt.body))
+			req.Header.Set("Content-Type", applicationJSONType)
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+			res := w.Result()
+			defer func() {
+				_ = res.Body.Close()
+			}()
+			assert.Equal(t, tt.status, res.StatusCode)
+			if tt.want != "" {
+				data, err := io.ReadAll(res.Body)
+				require.NoError(t, err)
+				assert.JSONEq(t, tt.want, string(data))
+			}
+		})
+	}
+
+	html, err := renderIndexPage(&templateArgs{Gauge: Storage.GetGaugeList()})
+	require.NoError(t, err)
+	assert.Contains(t, html.String(), "<li>gauge CPUutilization1 95 (in maintenance)</li>")
+	assert.Contains(t, html.String(), "<li>gauge FreeMemory 10</li>")
+
+	// пометки сохраняются в хранилище и восстанавливаются после перезапуска
+	Maintenance.Flush()
+	Maintenance = maintenance.NewSchedule()
+	restoreMaintenanceFlags()
+	assert.True(t, Maintenance.Flagged(gaugeKind, "CPUutilization1"))
+	assert.False(t, Maintenance.Flagged(gaugeKind, "FreeMemory"))
+	assert.False(t, Maintenance.Flagged(histogramKind, "CPUutilizationLatency"))
+}
@@ -0,0 +1,48 @@
+package server
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/NikolayStrekalov/vigilant-octo-waddle.git/internal/cumulative"
+	"github.com/NikolayStrekalov/vigilant-octo-waddle.git/internal/otlp"
+	colmetricspb "go.opentelemetry.io/proto/otlp/collector/metrics/v1"
+)
+
+// otlpSeriesTTL — через сколько забывается время начала серии OTLP, от которой не было точек.
+const otlpSeriesTTL = time.Hour
+
+// otlpTracker хранит время начала накопительных сумм OTLP и остатки дельта-сумм.
+var otlpTracker = cumulative.NewTracker(otlpSeriesTTL)
+
+func otlpMetricsHandler(res http.ResponseWriter, req *http.Request) {
+	contentType := req.Header.Get("Content-Type")
+	data, err := io.ReadAll(req.Body)
+	defer func() { _ = req.Body.Close() }()
+	if err != nil {
+		http.Error(res, messageInternalServerError, http.StatusInternalServerError)
+		return
+	}
+	export, err := otlp.Unmarshal(data, contentType)
+	if errors.Is(err, otlp.ErrUnsupportedType) {
+		http.Error(res, err.Error(), http.StatusUnsupportedMediaType)
+		return
+	}
+	if err != nil {
+		http.Error(res, "Wrong OTLP request: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+	ingest(otlp.ToMetrics(export, otlpTracker, time.Now()), agentName(req))
+	body, err := otlp.Marshal(&colmetricspb.ExportMetricsServiceResponse{}, contentType)
+	if err != nil {
+		http.Error(res, messageInternalServerError, http.StatusInternalServerError)
+		return
+	}
+	res.Header().Set("Content-Type", otlp.MediaType(contentType))
+	res.WriteHeader(http.StatusOK)
+	if _, err := res.Write(body); err != nil {
+		http.Error(res, messageInternalServerError, http.StatusInternalServerError)
+	}
+}
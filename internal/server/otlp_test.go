@@ -0,0 +1,74 @@
+package server
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/NikolayStrekalov/vigilant-octo-waddle.git/internal/cumulative"
+	"github.com/NikolayStrekalov/vigilant-octo-waddle.git/internal/memstorage"
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_otlpMetricsHandler(t *testing.T) {
+	storage, _, _ := memstorage.NewMemStorage("", false, 300)
+	Storage = storage
+	otlpTracker = cumulative.NewTracker(otlpSeriesTTL)
+	r := chi.NewRouter()
+	prepareRoutes(r)
+	body := func(requests int) string {
+		return `{"resourceMetrics":[{"scopeMetrics":[{"metrics":[
+			{"name":"heap","gauge":{"dataPoints":[{"asDouble":1024}]}},
+			{"name":"requests","sum":{"aggregationTemporality":2,"isMonotonic":true,"dataPoints":[{"asInt":"` +
+			strconv.Itoa(requests) + `"}]}}
+		]}]}]}`
+	}
+	tests := []struct {
+		name        string
+		contentType string
+		body        string
+		status      int
+		want        int64
+		restart     bool
+	}{
+		{name: "First export", contentType: "application/json", body: body(11), status: http.StatusOK, want: 11},
+		{name: "Cumulative export", contentType: "application/json", body: body(111), status: http.StatusOK, want: 111},
+		{name: "Wrong type", contentType: "text/plain", body: body(111), status: http.StatusUnsupportedMediaType, want: 111},
+		{name: "Wrong body", contentType: "application/x-protobuf", body: "\xff", status: http.StatusBadRequest, want: 111},
+		{name: "After restart", contentType: "application/json", body: body(121), status: http.StatusOK, want: 121,
+			restart: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.restart {
+				otlpTracker = cumulative.NewTracker(otlpSeriesTTL)
+			}
+			req := httptest.NewRequest(http.MethodPost, "http://localhost:8080/v1/metrics", strings.NewReader(tt.body))
+			req.Header.Set("Content-Type", tt.contentType)
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+			res := w.Result()
+			defer func() {
+				_ = res.Body.Close()
+			}()
+			assert.Equal(t, tt.status, res.StatusCode)
+			if tt.status == http.StatusOK {
+				assert.Equal(t, tt.contentType, res.Header.Get("Content-Type"))
+				data, err := io.ReadAll(res.Body)
+				require.NoError(t, err)
+				assert.JSONEq(t, `{}`, string(data))
+			}
+			v, err := storage.GetCounter("requests")
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, v)
+		})
+	}
+	v, err := storage.GetGauge("heap")
+	require.NoError(t, err)
+	assert.Equal(t, 1024.0, v)
+}
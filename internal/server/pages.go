@@ -5,11 +5,21 @@ import (
 	"errors"
 	"html/template"
 	"log"
+
+	"github.com/NikolayStrekalov/vigilant-octo-waddle.git/internal/absence"
+	"github.com/NikolayStrekalov/vigilant-octo-waddle.git/internal/hll"
+	"github.com/NikolayStrekalov/vigilant-octo-waddle.git/internal/models"
+	"github.com/NikolayStrekalov/vigilant-octo-waddle.git/internal/series"
 )
 
 type templateArgs struct {
-	Gauge   []GaugeListItem
-	Counter []CounterListItem
+	Gauge        []GaugeListItem
+	Counter      []CounterListItem
+	Histogram    []HistogramListItem
+	Summary      []SummaryListItem
+	Set          []SetListItem
+	Agents       []models.Agent
+	AbsentAgents []string
 }
 
 var indexTemplate = `<!DOCTYPE html>
@@ -20,19 +30,74 @@ var indexTemplate = `<!DOCTYPE html>
   </head>
   <body>
 	<ul>{{ range .Gauge}}
-	<li>gauge {{ .Name }} {{ .Value }}</li>{{ end }}{{ range .Counter}}
-	<li>counter {{ .Name }} {{ .Value }}</li>{{ end }}
+	<li>gauge {{ .Name }} {{ .Value }}{{ marks "gauge" .Name }}</li>{{ end }}{{ range .Counter}}
+	<li>counter {{ .Name }} {{ .Value }}{{ marks "counter" .Name }}</li>{{ end }}{{ range .Histogram }}
+	<li>histogram {{ .Name }} count={{ .Value.Count }} sum={{ .Value.Sum }}
+	{{- marks "histogram" .Name }}</li>{{ end }}{{ range .Summary }}
+	<li>summary {{ .Name }} count={{ .Value.Count }} sum={{ .Value.Sum }}{{ marks "summary" .Name }}</li>{{ end }}
+	{{- range .Set }}
+	<li>set {{ .Name }} cardinality={{ cardinality .Value }}
+	{{- marks "set" .Name }}</li>{{ end }}
+	{{- range .Agents }}
+	<li>agent {{ .ID }} host={{ .Hostname }} version={{ .Version }} address={{ .Address }}
+	{{- " " }}metrics={{ .Metrics }} lastSeen={{ .LastSeen.Format "2006-01-02T15:04:05Z07:00" }}</li>{{ end }}
+	{{- range .AbsentAgents }}
+	<li>agent {{ . }} (absent)</li>{{ end }}
 	</ul>
   </body>
 </html>
 `
 
-func renderIndexPage(counters []CounterListItem, gauges []GaugeListItem) (*bytes.Buffer, error) {
-	indexTemplate := template.Must(template.New("metrics").Parse(indexTemplate))
+// renderIndexPage выводит переданные серии, отсутствующие агенты добавляются сами.
+func renderIndexPage(args *templateArgs) (*bytes.Buffer, error) {
+	indexTemplate := template.Must(template.New("metrics").Funcs(template.FuncMap{
+		"marks":       metricMarks,
+		"cardinality": func(h models.HLL) uint64 { return hll.Count(&h) },
+	}).Parse(indexTemplate))
 	buf := new(bytes.Buffer)
-	if err := indexTemplate.Execute(buf, templateArgs{Gauge: gauges, Counter: counters}); err != nil {
+	args.AbsentAgents = absentAgents()
+	if err := indexTemplate.Execute(buf, args); err != nil {
 		log.Println(err)
 		return nil, errors.Unwrap(err)
 	}
 	return buf, nil
 }
+
+// metricMarks помечает метрики, полученные во время обслуживания или переставшие обновляться.
+func metricMarks(kind, name string) string {
+	marks := ""
+	if Maintenance.Flagged(kind, name) {
+		marks += " (in maintenance)"
+	}
+	if Absence != nil && Absence.Absent(kind, name) {
+		marks += " (absent)"
+	}
+	return marks
+}
+
+func absentAgents() []string {
+	if Absence == nil {
+		return nil
+	}
+	var agents []string
+	for _, a := range Absence.Absences() {
+		if a.Kind == absence.KindAgent {
+			agents = append(agents, a.Name)
+		}
+	}
+	return agents
+}
+
+// filterSeries оставляет серии, у которых есть все метки selector.
+func filterSeries[T any](items []T, key func(*T) string, selector map[string]string) []T {
+	if len(selector) == 0 {
+		return items
+	}
+	ret := make([]T, 0, len(items))
+	for i := range items {
+		if series.Match(key(&items[i]), selector) {
+			ret = append(ret, items[i])
+		}
+	}
+	return ret
+}
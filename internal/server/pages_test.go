@@ -63,7 +63,7 @@ func Test_renderIndexPage(t *testing.T) {
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			htmlBuf, err := renderIndexPage(tt.args.counters, tt.args.gauges)
+			htmlBuf, err := renderIndexPage(&templateArgs{Counter: tt.args.counters, Gauge: tt.args.gauges})
 			assert.Nil(t, err)
 			assert.Equal(t, tt.want, htmlBuf.String())
 		})
@@ -0,0 +1,277 @@
+package server
+
+import (
+	"bytes"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/NikolayStrekalov/vigilant-octo-waddle.git/internal/histogram"
+	"github.com/NikolayStrekalov/vigilant-octo-waddle.git/internal/hll"
+	"github.com/NikolayStrekalov/vigilant-octo-waddle.git/internal/logger"
+	"github.com/NikolayStrekalov/vigilant-octo-waddle.git/internal/models"
+	"github.com/NikolayStrekalov/vigilant-octo-waddle.git/internal/series"
+	"github.com/NikolayStrekalov/vigilant-octo-waddle.git/internal/sketch"
+)
+
+const (
+	prometheusTextType  = "text/plain; version=0.0.4; charset=utf-8"
+	openMetricsType     = "application/openmetrics-text"
+	openMetricsTextType = "application/openmetrics-text; version=1.0.0; charset=utf-8"
+	openMetricsTotal    = "_total"
+)
+
+// promName приводит имя метрики к виду [a-zA-Z_:][a-zA-Z0-9_:]*, недопустимые символы заменяются на _.
+func promName(name string) string {
+	var b strings.Builder
+	for i, r := range name {
+		switch {
+		case r == '_' || r == ':' || (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z'):
+			b.WriteRune(r)
+		case r >= '0' && r <= '9':
+			if i == 0 {
+				b.WriteByte('_')
+			}
+			b.WriteRune(r)
+		default:
+			b.WriteByte('_')
+		}
+	}
+	if b.Len() == 0 {
+		return "_"
+	}
+	return b.String()
+}
+
+// promSample — значение одной серии: исходное имя, имя семейства и метки в формате экспозиции.
+type promSample struct {
+	name   string
+	family string
+	labels string
+	value  string
+}
+
+func promSamples[T any](items []T, sample func(*T) (string, string)) []promSample {
+	ret := make([]promSample, 0, len(items))
+	for i := range items {
+		key, value := sample(&items[i])
+		name, labels := series.Parse(key)
+		ret = append(ret, promSample{name: name, family: promName(name), labels: series.Key("", labels), value: value})
+	}
+	sort.Slice(ret, func(i, j int) bool {
+		if ret[i].family != ret[j].family {
+			return ret[i].family < ret[j].family
+		}
+		if ret[i].name != ret[j].name {
+			return ret[i].name < ret[j].name
+		}
+		return ret[i].labels < ret[j].labels
+	})
+	return ret
+}
+
+// promFamily — семейство экспозиции: тип и сэмплы всех его серий.
+type promFamily struct {
+	name   string
+	kind   string
+	source string // вид и исходное имя метрики, которой принадлежит семейство
+	buf    bytes.Buffer
+}
+
+// promFamilies собирает семейства в порядке появления, чтобы каждое вывести один раз.
+// Семейство принадлежит первой метрике, которая его заняла. Метрика другого вида с тем же именем
+// или с другим именем, совпавшим с ним после promName (a.b и a_b), пропускается с записью в лог.
+type promFamilies struct {
+	index   map[string]*promFamily
+	skipped map[[2]string]bool
+	order   []*promFamily
+}
+
+func newPromFamilies() *promFamilies {
+	return &promFamilies{index: make(map[string]*promFamily), skipped: make(map[[2]string]bool)}
+}
+
+// get возвращает семейство name для метрики source или nil, если семейство занято другой метрикой.
+func (f *promFamilies) get(name, kind, source string) *promFamily {
+	fam, ok := f.index[name]
+	if !ok {
+		fam = &promFamily{name: name, kind: kind, source: source}
+		f.index[name] = fam
+		f.order = append(f.order, fam)
+		return fam
+	}
+	if fam.source == source {
+		return fam
+	}
+	if !f.skipped[[2]string{name, source}] {
+		f.skipped[[2]string{name, source}] = true
+		logger.Info("skip prometheus family collision:", name, source, "conflicts with", fam.source)
+	}
+	return nil
+}
+
+// addSamples добавляет серии метрик вида source в семейства типа kind. suffix добавляется к имени сэмплов.
+func (f *promFamilies) addSamples(samples []promSample, kind, source, suffix string) {
+	for i := range samples {
+		fam := f.get(samples[i].family, kind, source+" "+samples[i].name)
+		if fam == nil {
+			continue
+		}
+		fam.buf.WriteString(samples[i].family + suffix + samples[i].labels + " " + samples[i].value + "\n")
+	}
+}
+
+// writeTo выводит семейства, объявляя тип один раз на семейство.
+func (f *promFamilies) writeTo(buf *bytes.Buffer) {
+	for _, fam := range f.order {
+		buf.WriteString("# TYPE " + fam.name + " " + fam.kind + "\n")
+		_, _ = fam.buf.WriteTo(buf)
+	}
+}
+
+// promSeries — серия с составным значением: гистограмма или набросок.
+type promSeries[T any] struct {
+	labels map[string]string
+	value  *T
+	name   string
+	family string
+	key    string // метки в формате экспозиции
+}
+
+// promSortedSeries разбирает ключи серий и упорядочивает их по семейству, исходному имени, затем по меткам.
+func promSortedSeries[T, V any](items []T, item func(*T) (string, *V)) []promSeries[V] {
+	ret := make([]promSeries[V], 0, len(items))
+	for i := range items {
+		key, value := item(&items[i])
+		name, labels := series.Parse(key)
+		ret = append(ret, promSeries[V]{
+			name: name, family: promName(name), labels: labels, key: series.Key("", labels), value: value,
+		})
+	}
+	sort.Slice(ret, func(i, j int) bool {
+		if ret[i].family != ret[j].family {
+			return ret[i].family < ret[j].family
+		}
+		if ret[i].name != ret[j].name {
+			return ret[i].name < ret[j].name
+		}
+		return ret[i].key < ret[j].key
+	})
+	return ret
+}
+
+// withLabel возвращает метки серии в формате экспозиции с дополнительной меткой name.
+func withLabel(labels map[string]string, name, value string) string {
+	ret := make(map[string]string, len(labels)+1)
+	for k, v := range labels {
+		ret[k] = v
+	}
+	ret[name] = value
+	return series.Key("", ret)
+}
+
+// addHistograms добавляет гистограммы: накопленные числа наблюдений по корзинам с меткой le, сумму и число.
+func (f *promFamilies) addHistograms(items []HistogramListItem) {
+	hs := promSortedSeries(items, func(h *HistogramListItem) (string, *models.Histogram) { return h.Name, &h.Value })
+	for i := range hs {
+		fam := f.get(hs[i].family, histogramKind, histogramKind+" "+hs[i].name)
+		if fam == nil {
+			continue
+		}
+		for j, c := range histogram.Cumulative(hs[i].value) {
+			le := "+Inf"
+			if j < len(hs[i].value.Bounds) {
+				le = strconv.FormatFloat(hs[i].value.Bounds[j], 'g', -1, 64)
+			}
+			fam.buf.WriteString(hs[i].family + "_bucket" + withLabel(hs[i].labels, "le", le) + " " +
+				strconv.FormatUint(c, 10) + "\n")
+		}
+		fam.buf.WriteString(hs[i].family + "_sum" + hs[i].key + " " +
+			strconv.FormatFloat(hs[i].value.Sum, 'g', -1, 64) + "\n")
+		fam.buf.WriteString(hs[i].family + "_count" + hs[i].key + " " + strconv.FormatUint(hs[i].value.Count, 10) + "\n")
+	}
+}
+
+// promQuantiles — квантили, которые выводятся для summary.
+var promQuantiles = []float64{0.5, 0.9, 0.99}
+
+// addSummaries добавляет summary: квантили с меткой quantile, сумму и число значений.
+func (f *promFamilies) addSummaries(items []SummaryListItem) {
+	ss := promSortedSeries(items, func(s *SummaryListItem) (string, *models.Sketch) { return s.Name, &s.Value })
+	for i := range ss {
+		fam := f.get(ss[i].family, summaryKind, summaryKind+" "+ss[i].name)
+		if fam == nil {
+			continue
+		}
+		for _, q := range promQuantiles {
+			v, err := sketch.Quantile(ss[i].value, q)
+			if err != nil {
+				continue
+			}
+			fam.buf.WriteString(ss[i].family + withLabel(ss[i].labels, "quantile", strconv.FormatFloat(q, 'g', -1, 64)) +
+				" " + strconv.FormatFloat(v, 'g', -1, 64) + "\n")
+		}
+		fam.buf.WriteString(ss[i].family + "_sum" + ss[i].key + " " +
+			strconv.FormatFloat(ss[i].value.Sum, 'g', -1, 64) + "\n")
+		fam.buf.WriteString(ss[i].family + "_count" + ss[i].key + " " + strconv.FormatUint(ss[i].value.Count, 10) + "\n")
+	}
+}
+
+// renderPrometheus выводит метрики в текстовом формате Prometheus или в OpenMetrics.
+// Каждое семейство выводится один раз, метрики разных видов с одним именем не смешиваются:
+// остаётся первая в порядке gauge, set, counter, histogram, summary.
+// В OpenMetrics семейство счётчика называется без суффикса _total, а сам сэмпл — с ним.
+// Set выводится как gauge с оценкой числа различных значений.
+func renderPrometheus(
+	counters []CounterListItem,
+	gauges []GaugeListItem,
+	histograms []HistogramListItem,
+	summaries []SummaryListItem,
+	sets []SetListItem,
+	openMetrics bool,
+) *bytes.Buffer {
+	families := newPromFamilies()
+	families.addSamples(promSamples(gauges, func(g *GaugeListItem) (string, string) {
+		return g.Name, strconv.FormatFloat(g.Value, 'g', -1, 64)
+	}), gaugeKind, gaugeKind, "")
+	families.addSamples(promSamples(sets, func(s *SetListItem) (string, string) {
+		return s.Name, strconv.FormatUint(hll.Count(&s.Value), 10)
+	}), gaugeKind, setKind, "")
+	counterSamples := promSamples(counters, func(c *CounterListItem) (string, string) {
+		return c.Name, strconv.FormatInt(c.Value, 10)
+	})
+	suffix := ""
+	if openMetrics {
+		suffix = openMetricsTotal
+		for i := range counterSamples {
+			counterSamples[i].family = strings.TrimSuffix(counterSamples[i].family, openMetricsTotal)
+		}
+	}
+	families.addSamples(counterSamples, counterKind, counterKind, suffix)
+	families.addHistograms(histograms)
+	families.addSummaries(summaries)
+	buf := new(bytes.Buffer)
+	families.writeTo(buf)
+	if openMetrics {
+		buf.WriteString("# EOF\n")
+	}
+	return buf
+}
+
+func prometheusHandler(res http.ResponseWriter, req *http.Request) {
+	openMetrics := strings.Contains(req.Header.Get("Accept"), openMetricsType)
+	buf := renderPrometheus(
+		Storage.GetCounterList(), Storage.GetGaugeList(), Storage.GetHistogramList(), Storage.GetSummaryList(),
+		Storage.GetSetList(), openMetrics,
+	)
+	if openMetrics {
+		res.Header().Set("Content-Type", openMetricsTextType)
+	} else {
+		res.Header().Set("Content-Type", prometheusTextType)
+	}
+	res.WriteHeader(http.StatusOK)
+	if _, err := buf.WriteTo(res); err != nil {
+		http.Error(res, messageInternalServerError, http.StatusInternalServerError)
+	}
+}
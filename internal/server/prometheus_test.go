@@ -0,0 +1,136 @@
+package server
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/NikolayStrekalov/vigilant-octo-waddle.git/internal/logger"
+	"github.com/NikolayStrekalov/vigilant-octo-waddle.git/internal/memstorage"
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_promName(t *testing.T) {
+	tests := []struct {
+		name string
+		want string
+	}{
+		{name: "HeapAlloc", want: "HeapAlloc"},
+		{name: "CPU.utilization-1", want: "CPU_utilization_1"},
+		{name: "1st", want: "_1st"},
+		{name: "", want: "_"},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, promName(tt.name))
+	}
+}
+
+func Test_prometheusHandler(t *testing.T) {
+	storage, _, _ := memstorage.NewMemStorage("", false, 300)
+	storage.UpdateGauge("HeapAlloc", 1.5e6)
+	storage.UpdateGauge("Free.Memory", 1024)
+	storage.UpdateGauge(`HeapAlloc{host="db2"}`, 2e6)
+	storage.UpdateGauge(`HeapAlloc{host="db1"}`, 1e6)
+	storage.IncrementCounter("PollCount", 5)
+	Storage = storage
+	r := chi.NewRouter()
+	prepareRoutes(r)
+
+	tests := []struct {
+		name        string
+		accept      string
+		contentType string
+		want        string
+	}{
+		{
+			name:        "Prometheus text",
+			contentType: prometheusTextType,
+			want: `# TYPE Free_Memory gauge
+Free_Memory 1024
+# TYPE HeapAlloc gauge
+HeapAlloc 1.5e+06
+HeapAlloc{host="db1"} 1e+06
+HeapAlloc{host="db2"} 2e+06
+# TYPE PollCount counter
+PollCount 5
+`,
+		},
+		{
+			name:        "OpenMetrics",
+			accept:      "application/openmetrics-text;version=1.0.0,text/plain;version=0.0.4;q=0.5",
+			contentType: openMetricsTextType,
+			want: `# TYPE Free_Memory gauge
+Free_Memory 1024
+# TYPE HeapAlloc gauge
+HeapAlloc 1.5e+06
+HeapAlloc{host="db1"} 1e+06
+HeapAlloc{host="db2"} 2e+06
+# TYPE PollCount counter
+PollCount_total 5
+# EOF
+`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "http://localhost:8080/metrics", http.NoBody)
+			if tt.accept != "" {
+				req.Header.Set("Accept", tt.accept)
+			}
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+			res := w.Result()
+			defer func() {
+				_ = res.Body.Close()
+			}()
+			assert.Equal(t, http.StatusOK, res.StatusCode)
+			assert.Equal(t, tt.contentType, res.Header.Get("Content-Type"))
+			data, err := io.ReadAll(res.Body)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, string(data))
+		})
+	}
+}
+
+func Test_renderPrometheusCollisions(t *testing.T) {
+	_ = logger.InitLog()
+	counters := []CounterListItem{{Name: "requests", Value: 7}, {Name: "cpu_load", Value: 3}}
+	gauges := []GaugeListItem{
+		{Name: "requests", Value: 2},
+		{Name: "cpu.load", Value: 0.5},
+		{Name: `cpu_load{host="a"}`, Value: 0.7},
+	}
+	tests := []struct {
+		name        string
+		openMetrics bool
+		want        string
+	}{
+		{
+			name: "Prometheus text",
+			want: `# TYPE cpu_load gauge
+cpu_load 0.5
+# TYPE requests gauge
+requests 2
+`,
+		},
+		{
+			name:        "OpenMetrics",
+			openMetrics: true,
+			want: `# TYPE cpu_load gauge
+cpu_load 0.5
+# TYPE requests gauge
+requests 2
+# EOF
+`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			buf := renderPrometheus(counters, gauges, nil, nil, nil, tt.openMetrics)
+			assert.Equal(t, tt.want, buf.String())
+		})
+	}
+}
@@ -0,0 +1,73 @@
+package server
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/NikolayStrekalov/vigilant-octo-waddle.git/internal/history"
+	"github.com/NikolayStrekalov/vigilant-octo-waddle.git/internal/logger"
+	"github.com/NikolayStrekalov/vigilant-octo-waddle.git/internal/memstorage"
+	"github.com/NikolayStrekalov/vigilant-octo-waddle.git/internal/models"
+	"github.com/NikolayStrekalov/vigilant-octo-waddle.git/internal/series"
+	"github.com/go-chi/chi/v5"
+)
+
+const (
+	ratePath     = "/api/rate/{name}"
+	increasePath = "/api/increase/{name}"
+	windowParam  = "window"
+)
+
+// counterIncrease считает прирост counter за окно из параметра window по сырым сэмплам истории,
+// при ошибке отвечает клиенту сам. Окно не может быть длиннее срока хранения сырых сэмплов.
+func counterIncrease(res http.ResponseWriter, req *http.Request) (models.CounterRate, bool) {
+	ret := models.CounterRate{ID: chi.URLParam(req, "name"), Labels: queryLabels(req, windowParam)}
+	window, err := parseHistoryStep(req.URL.Query().Get(windowParam))
+	if err != nil || window <= 0 {
+		http.Error(res, "Wrong window!", http.StatusBadRequest)
+		return ret, false
+	}
+	if raw := ServerConfig.HistoryPolicy.Raw; raw > 0 && window > raw {
+		http.Error(res, "Window exceeds raw history retention!", http.StatusBadRequest)
+		return ret, false
+	}
+	key := series.Key(ret.ID, ret.Labels)
+	if _, err := Storage.GetCounter(key); err != nil {
+		http.Error(res, metricNotFound, http.StatusNotFound)
+		return ret, false
+	}
+	now := time.Now()
+	points, err := Storage.GetCounterSamples(key, now.Add(-window), now)
+	if errors.Is(err, memstorage.ErrNotSupported) {
+		http.Error(res, "History is not supported by the storage!", http.StatusNotImplemented)
+		return ret, false
+	}
+	if err != nil {
+		logger.Info("error getting counter samples:", err)
+		http.Error(res, messageInternalServerError, http.StatusInternalServerError)
+		return ret, false
+	}
+	ret.Window = window.Seconds()
+	ret.Value = history.Increase(points)
+	return ret, true
+}
+
+// increaseHandler отвечает приростом counter за окно, например /api/increase/requests?window=1h.
+func increaseHandler(res http.ResponseWriter, req *http.Request) {
+	increase, ok := counterIncrease(res, req)
+	if !ok {
+		return
+	}
+	writeJSON(res, http.StatusOK, &increase)
+}
+
+// rateHandler отвечает средним приростом counter в секунду за окно, например /api/rate/requests?window=5m.
+func rateHandler(res http.ResponseWriter, req *http.Request) {
+	rate, ok := counterIncrease(res, req)
+	if !ok {
+		return
+	}
+	rate.Value /= rate.Window
+	writeJSON(res, http.StatusOK, &rate)
+}
@@ -0,0 +1,88 @@
+package server
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/NikolayStrekalov/vigilant-octo-waddle.git/internal/history"
+	"github.com/NikolayStrekalov/vigilant-octo-waddle.git/internal/logger"
+	"github.com/NikolayStrekalov/vigilant-octo-waddle.git/internal/memstorage"
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_rateHandlers(t *testing.T) {
+	_ = logger.InitLog()
+	storage, _, _ := memstorage.NewMemStorage("", false, 300)
+	Storage = storage
+	r := chi.NewRouter()
+	prepareRoutes(r)
+	Storage.IncrementCounter("requests", 10)
+	status, _ := getResponse(t, r, "/api/increase/requests?window=1m")
+	assert.Equal(t, http.StatusNotImplemented, status)
+
+	ServerConfig.HistoryPolicy = history.Policy{Raw: time.Hour}
+	defer func() { ServerConfig.HistoryPolicy = history.Policy{} }()
+	Storage.SetHistoryPolicy(ServerConfig.HistoryPolicy)
+	Storage.IncrementCounter("requests", 10)
+	Storage.IncrementCounter("requests", 5)
+	require.NoError(t, Storage.ResetCounter("requests"))
+	Storage.IncrementCounter("requests", 3)
+	Storage.IncrementCounter(`requests{host="a"}`, 7)
+
+	tests := []struct {
+		name   string
+		path   string
+		status int
+		want   string
+	}{
+		{
+			name:   "Increase",
+			path:   "/api/increase/requests?window=1m",
+			status: http.StatusOK,
+			want:   `{"id":"requests","window":60,"value":8}`,
+		},
+		{
+			name:   "Rate",
+			path:   "/api/rate/requests?window=10",
+			status: http.StatusOK,
+			want:   `{"id":"requests","window":10,"value":0.8}`,
+		},
+		{
+			name:   "Labels",
+			path:   "/api/increase/requests?window=1m&host=a",
+			status: http.StatusOK,
+			want:   `{"id":"requests","labels":{"host":"a"},"window":60,"value":0}`,
+		},
+		{name: "No window", path: "/api/rate/requests", status: http.StatusBadRequest},
+		{name: "Wrong window", path: "/api/rate/requests?window=-1m", status: http.StatusBadRequest},
+		{name: "Long window", path: "/api/rate/requests?window=2h", status: http.StatusBadRequest},
+		{name: "Missing", path: "/api/increase/unknown?window=1m", status: http.StatusNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			status, body := getResponse(t, r, tt.path)
+			assert.Equal(t, tt.status, status)
+			if tt.want != "" {
+				assert.JSONEq(t, tt.want, body)
+			}
+		})
+	}
+}
+
+func getResponse(t *testing.T, r http.Handler, path string) (int, string) {
+	t.Helper()
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "http://localhost:8080"+path, http.NoBody))
+	res := w.Result()
+	defer func() {
+		_ = res.Body.Close()
+	}()
+	data, err := io.ReadAll(res.Body)
+	require.NoError(t, err)
+	return res.StatusCode, string(data)
+}
@@ -0,0 +1,29 @@
+package server
+
+import (
+	"io"
+	"net/http"
+
+	"github.com/NikolayStrekalov/vigilant-octo-waddle.git/internal/logger"
+	"github.com/NikolayStrekalov/vigilant-octo-waddle.git/internal/remotewrite"
+)
+
+func remoteWriteHandler(res http.ResponseWriter, req *http.Request) {
+	data, err := io.ReadAll(req.Body)
+	defer func() { _ = req.Body.Close() }()
+	if err != nil {
+		http.Error(res, messageInternalServerError, http.StatusInternalServerError)
+		return
+	}
+	wr, err := remotewrite.Decode(data)
+	if err != nil {
+		http.Error(res, "Wrong remote write request: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+	metrics, dropped := remotewrite.ToMetrics(&wr)
+	if dropped > 0 {
+		logger.Info("remote write: dropped series with wrong labels:", dropped)
+	}
+	ingest(metrics, agentName(req))
+	res.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,75 @@
+package server
+
+import (
+	"bytes"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+
+	"github.com/NikolayStrekalov/vigilant-octo-waddle.git/internal/memstorage"
+	"github.com/go-chi/chi/v5"
+	"github.com/golang/snappy"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+// writeRequest собирает WriteRequest из серий с одной меткой __name__ и одним сэмплом.
+func writeRequest(samples map[string]float64) []byte {
+	var b []byte
+	for name, value := range samples {
+		var label, sample, ts []byte
+		label = protowire.AppendTag(label, 1, protowire.BytesType)
+		label = protowire.AppendString(label, "__name__")
+		label = protowire.AppendTag(label, 2, protowire.BytesType)
+		label = protowire.AppendString(label, name)
+		sample = protowire.AppendTag(sample, 1, protowire.Fixed64Type)
+		sample = protowire.AppendFixed64(sample, math.Float64bits(value))
+		ts = protowire.AppendTag(ts, 1, protowire.BytesType)
+		ts = protowire.AppendBytes(ts, label)
+		ts = protowire.AppendTag(ts, 2, protowire.BytesType)
+		ts = protowire.AppendBytes(ts, sample)
+		b = protowire.AppendTag(b, 1, protowire.BytesType)
+		b = protowire.AppendBytes(b, ts)
+	}
+	return snappy.Encode(nil, b)
+}
+
+func Test_remoteWriteHandler(t *testing.T) {
+	dump := filepath.Join(t.TempDir(), "metrics.json")
+	storage, _, _ := memstorage.NewMemStorage(dump, false, 0)
+	Storage = storage
+	r := chi.NewRouter()
+	prepareRoutes(r)
+	post := func(body []byte) int {
+		req := httptest.NewRequest(http.MethodPost, "http://localhost:8080/api/v1/write", bytes.NewReader(body))
+		req.Header.Set("Content-Encoding", "snappy")
+		req.Header.Set("Content-Type", "application/x-protobuf")
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		res := w.Result()
+		_ = res.Body.Close()
+		return res.StatusCode
+	}
+
+	assert.Equal(t, http.StatusNoContent, post(writeRequest(map[string]float64{
+		"go_goroutines": 12, "http_requests_total": 10,
+	})))
+	assert.Equal(t, http.StatusNoContent, post(writeRequest(map[string]float64{"http_requests_total": 25})))
+	assert.Equal(t, http.StatusBadRequest, post([]byte("not snappy")))
+
+	g, err := storage.GetGauge("go_goroutines")
+	assert.Nil(t, err)
+	assert.Equal(t, 12.0, g)
+	c, err := storage.GetCounter("http_requests_total")
+	assert.Nil(t, err)
+	assert.Equal(t, int64(25), c)
+
+	// после перезапуска сервера накопленное значение не прибавляется заново
+	restarted, _, _ := memstorage.NewMemStorage(dump, true, 0)
+	Storage = restarted
+	assert.Equal(t, http.StatusNoContent, post(writeRequest(map[string]float64{"http_requests_total": 30})))
+	c, _ = restarted.GetCounter("http_requests_total")
+	assert.Equal(t, int64(30), c)
+}
@@ -0,0 +1,39 @@
+package server
+
+import (
+	"github.com/NikolayStrekalov/vigilant-octo-waddle.git/internal/hll"
+	"github.com/NikolayStrekalov/vigilant-octo-waddle.git/internal/models"
+)
+
+// setMembers возвращает набросок set name со значениями members. Берётся точность уже накопленного
+// наброска, чтобы при слиянии он не сворачивался.
+func setMembers(name string, members ...string) *models.HLL {
+	precision := uint8(ServerConfig.SetPrecision)
+	if h, err := Storage.GetSet(name); err == nil {
+		precision = h.Precision
+	}
+	h := hll.New(precision)
+	for _, m := range members {
+		hll.Add(&h, m)
+	}
+	return &h
+}
+
+// setUpdate заменяет значения members в метрике set наброском. Пришедший набросок
+// сливается с ними, так что агент может прислать и то, и другое.
+func setUpdate(m *models.Metrics) error {
+	if m.HLL != nil {
+		if err := hll.Validate(m.HLL); err != nil {
+			return err
+		}
+	}
+	if len(m.Members) > 0 {
+		h := setMembers(m.Key(), m.Members...)
+		if m.HLL != nil {
+			hll.Merge(h, m.HLL)
+		}
+		m.HLL = h
+		m.Members = nil
+	}
+	return nil
+}
@@ -0,0 +1,134 @@
+package server
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/NikolayStrekalov/vigilant-octo-waddle.git/internal/hll"
+	"github.com/NikolayStrekalov/vigilant-octo-waddle.git/internal/logger"
+	"github.com/NikolayStrekalov/vigilant-octo-waddle.git/internal/memstorage"
+	"github.com/NikolayStrekalov/vigilant-octo-waddle.git/internal/models"
+	"github.com/go-chi/chi/v5"
+	"github.com/mailru/easyjson"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_setHandlers(t *testing.T) {
+	_ = logger.InitLog()
+	storage, _, _ := memstorage.NewMemStorage("", false, 300)
+	Storage = storage
+	ServerConfig.SetPrecision = hll.DefaultPrecision
+	defer func() { ServerConfig.SetPrecision = 0 }()
+	r := chi.NewRouter()
+	prepareRoutes(r)
+
+	do := func(method, path, body string) (int, string) {
+		req := httptest.NewRequest(method, "http://localhost:8080"+path, strings.NewReader(body))
+		req.Header.Set("Content-Type", applicationJSONType)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		res := w.Result()
+		defer func() {
+			_ = res.Body.Close()
+		}()
+		data, err := io.ReadAll(res.Body)
+		require.NoError(t, err)
+		return res.StatusCode, string(data)
+	}
+
+	// два агента считают пересекающиеся множества пользователей и присылают наброски
+	for agent := 0; agent < 2; agent++ {
+		h := hll.New(hll.DefaultPrecision)
+		for i := agent * 500; i < agent*500+1000; i++ {
+			hll.Add(&h, "user-"+strconv.Itoa(i))
+		}
+		body, err := easyjson.Marshal(models.MetricsSlice{{
+			ID: "users", MType: setKind, Labels: map[string]string{"host": "a"}, HLL: &h,
+		}})
+		require.NoError(t, err)
+		status, _ := do(http.MethodPost, "/updates/", string(body))
+		assert.Equal(t, http.StatusOK, status)
+	}
+	status, body := do(http.MethodGet, "/value/set/users?host=a", "")
+	assert.Equal(t, http.StatusOK, status)
+	got, err := strconv.Atoi(body)
+	require.NoError(t, err)
+	assert.InEpsilon(t, 1500, got, 0.03)
+
+	tests := []struct {
+		name   string
+		method string
+		path   string
+		body   string
+		status int
+		want   string
+	}{
+		{name: "Add", method: http.MethodPost, path: "/update/set/visitors/alice", status: http.StatusOK},
+		{name: "Add again", method: http.MethodPost, path: "/update/set/visitors/alice", status: http.StatusOK},
+		{name: "Add other", method: http.MethodPost, path: "/update/set/visitors/bob", status: http.StatusOK},
+		{name: "Value", method: http.MethodGet, path: "/value/set/visitors", status: http.StatusOK, want: "2"},
+		{
+			name:   "Update JSON",
+			method: http.MethodPost,
+			path:   "/update/",
+			body:   `{"id":"visitors","type":"set","members":["carol","alice"]}`,
+			status: http.StatusOK,
+			want:   `{"id":"visitors","type":"set","cardinality":3}`,
+		},
+		{
+			name:   "Update JSON without members",
+			method: http.MethodPost,
+			path:   "/update/",
+			body:   `{"id":"visitors","type":"set"}`,
+			status: http.StatusBadRequest,
+		},
+		{
+			name:   "Wrong hll",
+			method: http.MethodPost,
+			path:   "/updates/",
+			body:   `[{"id":"visitors","type":"set","hll":{"precision":4,"registers":"AAAA"}}]`,
+			status: http.StatusBadRequest,
+		},
+		{
+			name:   "Bulk members",
+			method: http.MethodPost,
+			path:   "/updates/",
+			body:   `[{"id":"visitors","type":"set","members":["dave"]}]`,
+			status: http.StatusOK,
+		},
+		{name: "After bulk", method: http.MethodGet, path: "/value/set/visitors", status: http.StatusOK, want: "4"},
+		{name: "Missing", method: http.MethodGet, path: "/value/set/unknown", status: http.StatusNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			status, body := do(tt.method, tt.path, tt.body)
+			assert.Equal(t, tt.status, status)
+			if tt.want == "" {
+				return
+			}
+			if strings.HasPrefix(tt.want, "{") {
+				assert.JSONEq(t, tt.want, body)
+			} else {
+				assert.Equal(t, tt.want, body)
+			}
+		})
+	}
+
+	status, body = do(http.MethodPost, "/value/", `{"id":"visitors","type":"set"}`)
+	assert.Equal(t, http.StatusOK, status)
+	m := models.Metrics{}
+	require.NoError(t, easyjson.Unmarshal([]byte(body), &m))
+	if assert.NotNil(t, m.HLL) && assert.NotNil(t, m.Cardinality) {
+		assert.Equal(t, uint8(hll.DefaultPrecision), m.HLL.Precision)
+		assert.Equal(t, uint64(4), *m.Cardinality)
+	}
+
+	buf := renderPrometheus(nil, nil, nil, nil, Storage.GetSetList(), false)
+	assert.Contains(t, buf.String(), "# TYPE visitors gauge\nvisitors 4\n")
+	assert.Contains(t, buf.String(), `users{host="a"} `)
+}
@@ -0,0 +1,40 @@
+package server
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/NikolayStrekalov/vigilant-octo-waddle.git/internal/models"
+	"github.com/NikolayStrekalov/vigilant-octo-waddle.git/internal/statsd"
+)
+
+// ingestStorage направляет метрики из StatsD и Graphite через ingest, как и обновления по HTTP.
+type ingestStorage struct{}
+
+func (ingestStorage) GetGauge(name string) (float64, error) {
+	v, err := Storage.GetGauge(name)
+	if err != nil {
+		return v, fmt.Errorf("can't get gauge %s: %w", name, err)
+	}
+	return v, nil
+}
+
+func (ingestStorage) BulkUpdate(metrics models.MetricsSlice) {
+	ingest(metrics, "")
+}
+
+// startStatsd запускает приём StatsD, если задан адрес, и возвращает функцию остановки.
+func startStatsd() (func() error, error) {
+	if ServerConfig.StatsdAddress == "" {
+		return func() error { return nil }, nil
+	}
+	listener, err := statsd.Listen(ServerConfig.StatsdAddress)
+	if err != nil {
+		return nil, fmt.Errorf("can't start statsd: %w", err)
+	}
+	go listener.Run(ingestStorage{}, time.Duration(ServerConfig.StatsdFlush)*time.Second)
+	return func() error {
+		listener.Flush(ingestStorage{})
+		return listener.Close()
+	}, nil
+}
@@ -0,0 +1,45 @@
+package server
+
+import (
+	"net"
+	"testing"
+	"time"
+
+	"github.com/NikolayStrekalov/vigilant-octo-waddle.git/internal/logger"
+	"github.com/NikolayStrekalov/vigilant-octo-waddle.git/internal/memstorage"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_startStatsd(t *testing.T) {
+	_ = logger.InitLog()
+	storage, _, _ := memstorage.NewMemStorage("", false, 300)
+	storage.UpdateGauge("queue", 10)
+	Storage = storage
+	// адрес берём у свободного сокета, чтобы слушать на известном порту
+	probe, err := net.ListenPacket("udp", "127.0.0.1:0")
+	require.NoError(t, err)
+	addr := probe.LocalAddr().String()
+	require.NoError(t, probe.Close())
+	defer func(prev Config) { ServerConfig = prev }(ServerConfig)
+	ServerConfig.StatsdAddress = addr
+	ServerConfig.StatsdFlush = 3600
+
+	stop, err := startStatsd()
+	require.NoError(t, err)
+	conn, err := net.Dial("udp", addr)
+	require.NoError(t, err)
+	defer func() { _ = conn.Close() }()
+	_, err = conn.Write([]byte("requests:1|c|@0.5\nqueue:+5|g"))
+	require.NoError(t, err)
+	// даём пакету дойти до сокета, затем останавливаем приём со сбросом накопленного
+	time.Sleep(100 * time.Millisecond)
+	require.NoError(t, stop())
+
+	c, err := storage.GetCounter("requests")
+	assert.Nil(t, err)
+	assert.Equal(t, int64(2), c)
+	g, err := storage.GetGauge("queue")
+	assert.Nil(t, err)
+	assert.Equal(t, 15.0, g)
+}
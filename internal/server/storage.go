@@ -1,15 +1,53 @@
 package server
 
-import "github.com/NikolayStrekalov/vigilant-octo-waddle.git/internal/models"
+import (
+	"time"
+
+	"github.com/NikolayStrekalov/vigilant-octo-waddle.git/internal/history"
+	"github.com/NikolayStrekalov/vigilant-octo-waddle.git/internal/models"
+)
 
 type StorageOperations interface {
 	GetGaugeList() []GaugeListItem
 	GetCounterList() []CounterListItem
+	GetHistogramList() []HistogramListItem
+	GetSummaryList() []SummaryListItem
+	GetSetList() []SetListItem
 	GetGauge(string) (float64, error)
 	GetCounter(string) (int64, error)
+	GetHistogram(string) (models.Histogram, error)
+	GetSummary(string) (models.Sketch, error)
+	GetSet(string) (models.HLL, error)
 	UpdateGauge(string, float64)
 	IncrementCounter(string, int64)
+	AddCounterTotal(string, int64)
+	UpdateHistogram(string, *models.Histogram) error
+	UpdateSummary(string, *models.Sketch) error
+	UpdateSet(string, *models.HLL)
+	DeleteMetric(kind, name string) error
+	DeleteMetrics(pattern string) (int64, error)
+	ResetCounter(string) error
+	SetStaleTTL(time.Duration)
+	ExpireMetrics(before time.Time) (int64, error)
 	BulkUpdate(models.MetricsSlice)
+	BulkUpdateOnce(key string, seq int64, metrics models.MetricsSlice) (bool, error)
+	ForgetBatches(before time.Time) error
+	GetHistory(kind, name string, from, to time.Time, step time.Duration) ([]models.HistoryPoint, error)
+	GetCounterSamples(name string, from, to time.Time) ([]models.HistoryPoint, error)
+	SetHistoryPolicy(history.Policy)
+	CompactHistory(since, now time.Time) error
+	GetAlertRules() ([]models.AlertRule, error)
+	GetAlertRule(id int64) (models.AlertRule, error)
+	AddAlertRule(*models.AlertRule) (models.AlertRule, error)
+	UpdateAlertRule(*models.AlertRule) error
+	DeleteAlertRule(id int64) error
+	GetMaintenanceWindows() ([]models.MaintenanceWindow, error)
+	AddMaintenanceWindow(*models.MaintenanceWindow) (models.MaintenanceWindow, error)
+	DeleteMaintenanceWindow(id int64) error
+	GetMaintenanceFlags() ([]string, error)
+	SetMaintenanceFlag(key string, flagged bool) error
+	GetAgents() ([]models.Agent, error)
+	SeenAgent(*models.Agent) error
 }
 
 type GaugeListItem = struct {
@@ -22,4 +60,19 @@ type CounterListItem = struct {
 	Value int64
 }
 
+type HistogramListItem = struct {
+	Name  string
+	Value models.Histogram
+}
+
+type SummaryListItem = struct {
+	Name  string
+	Value models.Sketch
+}
+
+type SetListItem = struct {
+	Name  string
+	Value models.HLL
+}
+
 var Storage StorageOperations
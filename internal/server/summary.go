@@ -0,0 +1,101 @@
+package server
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/NikolayStrekalov/vigilant-octo-waddle.git/internal/logger"
+	"github.com/NikolayStrekalov/vigilant-octo-waddle.git/internal/models"
+	"github.com/NikolayStrekalov/vigilant-octo-waddle.git/internal/sketch"
+)
+
+// summaryObservation возвращает набросок summary name с одним значением value.
+// Берётся точность уже накопленного наброска, чтобы значение не сбросило его данные.
+func summaryObservation(name string, value float64) *models.Sketch {
+	alpha := ServerConfig.SummaryAccuracy
+	if s, err := Storage.GetSummary(name); err == nil {
+		alpha = s.Alpha
+	}
+	s := sketch.New(alpha)
+	sketch.Observe(&s, value)
+	return &s
+}
+
+// summaryUpdated отвечает на ошибку обновления summary: 400 при другой точности наброска, иначе 500.
+func summaryUpdated(res http.ResponseWriter, err error) bool {
+	switch {
+	case err == nil:
+		return true
+	case errors.Is(err, sketch.ErrAlphaMismatch):
+		http.Error(res, "Wrong sketch: "+err.Error(), http.StatusBadRequest)
+	default:
+		logger.Info("error updating summary:", err)
+		http.Error(res, messageInternalServerError, http.StatusInternalServerError)
+	}
+	return false
+}
+
+// checkSummaryAlpha проверяет, что наброски пачки можно слить с накопленными: точность должна совпадать
+// с сохранённой и с другими набросками той же серии в пачке.
+func checkSummaryAlpha(metrics models.MetricsSlice) error {
+	seen := make(map[string]*models.Sketch)
+	for i := range metrics {
+		if metrics[i].MType != summaryKind || metrics[i].Sketch == nil {
+			continue
+		}
+		key := metrics[i].Key()
+		prev, ok := seen[key]
+		if !ok {
+			if s, err := Storage.GetSummary(key); err == nil {
+				prev, ok = &s, true
+			}
+		}
+		if ok {
+			if err := sketch.Compatible(prev, metrics[i].Sketch); err != nil {
+				return fmt.Errorf("%s: %w", key, err)
+			}
+		}
+		seen[key] = metrics[i].Sketch
+	}
+	return nil
+}
+
+// quantilesHandler отвечает квантилями summary для параметров q, по одному в строке в порядке запроса,
+// например /value/summary/latency?q=0.5&q=0.99.
+func quantilesHandler(res http.ResponseWriter, req *http.Request, name string) {
+	params := req.URL.Query()[quantileParam]
+	if len(params) == 0 {
+		http.Error(res, "Provide q parameter!", http.StatusBadRequest)
+		return
+	}
+	qs := make([]float64, 0, len(params))
+	for _, p := range params {
+		q, err := strconv.ParseFloat(p, 64)
+		if err != nil || q < 0 || q > 1 {
+			http.Error(res, "Wrong quantile!", http.StatusBadRequest)
+			return
+		}
+		qs = append(qs, q)
+	}
+	s, err := Storage.GetSummary(name)
+	if err != nil {
+		http.Error(res, metricNotFound, http.StatusNotFound)
+		return
+	}
+	values := make([]string, 0, len(qs))
+	for _, q := range qs {
+		v, err := sketch.Quantile(&s, q)
+		if err != nil {
+			http.Error(res, metricNotFound, http.StatusNotFound)
+			return
+		}
+		values = append(values, strconv.FormatFloat(v, 'f', -1, 64))
+	}
+	if _, err := io.WriteString(res, strings.Join(values, "\n")); err != nil {
+		http.Error(res, messageInternalServerError, http.StatusInternalServerError)
+	}
+}
@@ -0,0 +1,136 @@
+package server
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/NikolayStrekalov/vigilant-octo-waddle.git/internal/logger"
+	"github.com/NikolayStrekalov/vigilant-octo-waddle.git/internal/memstorage"
+	"github.com/NikolayStrekalov/vigilant-octo-waddle.git/internal/models"
+	"github.com/NikolayStrekalov/vigilant-octo-waddle.git/internal/sketch"
+	"github.com/go-chi/chi/v5"
+	"github.com/mailru/easyjson"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_summaryHandlers(t *testing.T) {
+	_ = logger.InitLog()
+	storage, _, _ := memstorage.NewMemStorage("", false, 300)
+	Storage = storage
+	ServerConfig.SummaryAccuracy = sketch.DefaultAlpha
+	defer func() { ServerConfig.SummaryAccuracy = 0 }()
+	r := chi.NewRouter()
+	prepareRoutes(r)
+
+	do := func(method, path, body string) (int, string) {
+		req := httptest.NewRequest(method, "http://localhost:8080"+path, strings.NewReader(body))
+		req.Header.Set("Content-Type", applicationJSONType)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		res := w.Result()
+		defer func() {
+			_ = res.Body.Close()
+		}()
+		data, err := io.ReadAll(res.Body)
+		require.NoError(t, err)
+		return res.StatusCode, string(data)
+	}
+
+	// два агента присылают наброски, сервер их сливает
+	for agent := 0; agent < 2; agent++ {
+		s := sketch.New(sketch.DefaultAlpha)
+		for i := 1; i <= 500; i++ {
+			sketch.Observe(&s, float64(agent*500+i))
+		}
+		body, err := easyjson.Marshal(models.MetricsSlice{{
+			ID: "latency", MType: summaryKind, Labels: map[string]string{"host": "a"}, Sketch: &s,
+		}})
+		require.NoError(t, err)
+		status, _ := do(http.MethodPost, "/updates/", string(body))
+		assert.Equal(t, http.StatusOK, status)
+	}
+	status, body := do(http.MethodGet, "/value/summary/latency?host=a&q=0.5&q=0.99", "")
+	assert.Equal(t, http.StatusOK, status)
+	values := strings.Split(body, "\n")
+	if assert.Len(t, values, 2) {
+		for i, want := range []float64{500, 990} {
+			got, err := strconv.ParseFloat(values[i], 64)
+			require.NoError(t, err)
+			assert.InEpsilon(t, want, got, sketch.DefaultAlpha+0.001)
+		}
+	}
+
+	tests := []struct {
+		name   string
+		method string
+		path   string
+		body   string
+		status int
+		want   string
+	}{
+		{name: "Observe", method: http.MethodPost, path: "/update/summary/size/3", status: http.StatusOK},
+		{name: "Wrong value", method: http.MethodPost, path: "/update/summary/size/Inf", status: http.StatusBadRequest},
+		{name: "Quantile", method: http.MethodGet, path: "/value/summary/size?q=0.5", status: http.StatusOK, want: "3"},
+		{name: "No quantile", method: http.MethodGet, path: "/value/summary/size", status: http.StatusBadRequest},
+		{name: "Wrong quantile", method: http.MethodGet, path: "/value/summary/size?q=2", status: http.StatusBadRequest},
+		{name: "Unknown labels", method: http.MethodGet, path: "/value/summary/size?q=1&host=a", status: http.StatusNotFound},
+		{
+			name:   "Update JSON",
+			method: http.MethodPost,
+			path:   "/update/",
+			body:   `{"id":"size","type":"summary","sketch":{"alpha":0.01,"zero":1,"count":1,"sum":0,"min":0,"max":0}}`,
+			status: http.StatusOK,
+		},
+		{
+			name:   "Update JSON with other alpha",
+			method: http.MethodPost,
+			path:   "/update/",
+			body:   `{"id":"size","type":"summary","sketch":{"alpha":0.05,"zero":1,"count":1,"sum":0,"min":0,"max":0}}`,
+			status: http.StatusBadRequest,
+		},
+		{
+			name:   "Bulk with other alpha",
+			method: http.MethodPost,
+			path:   "/updates/",
+			body:   `[{"id":"size","type":"summary","sketch":{"alpha":0.05,"zero":1,"count":1,"sum":0,"min":0,"max":0}}]`,
+			status: http.StatusBadRequest,
+		},
+		{
+			name:   "Wrong sketch",
+			method: http.MethodPost,
+			path:   "/updates/",
+			body:   `[{"id":"size","type":"summary","sketch":{"alpha":0.01,"zero":1,"count":2}}]`,
+			status: http.StatusBadRequest,
+		},
+		{name: "Min", method: http.MethodGet, path: "/value/summary/size?q=0", status: http.StatusOK, want: "0"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			status, body := do(tt.method, tt.path, tt.body)
+			assert.Equal(t, tt.status, status)
+			if tt.want != "" {
+				assert.Equal(t, tt.want, body)
+			}
+		})
+	}
+
+	status, body = do(http.MethodPost, "/value/", `{"id":"size","type":"summary"}`)
+	assert.Equal(t, http.StatusOK, status)
+	m := models.Metrics{}
+	require.NoError(t, easyjson.Unmarshal([]byte(body), &m))
+	if assert.NotNil(t, m.Sketch) {
+		assert.Equal(t, uint64(2), m.Sketch.Count)
+		assert.Equal(t, 3.0, m.Sketch.Max)
+	}
+
+	buf := renderPrometheus(nil, nil, nil, Storage.GetSummaryList(), nil, false)
+	assert.Contains(t, buf.String(), "# TYPE latency summary\n")
+	assert.Contains(t, buf.String(), `latency{host="a",quantile="0.5"} `)
+	assert.Contains(t, buf.String(), "latency_count{host=\"a\"} 1000\n")
+	assert.Contains(t, buf.String(), "size_sum 3\n")
+}
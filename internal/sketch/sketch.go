@@ -0,0 +1,233 @@
+// Package sketch реализует DDSketch — набросок распределения, по которому вычисляются квантили
+// с относительной точностью alpha.
+//
+// Положительное значение v попадает в корзину ceil(log_γ(v)), где γ = (1+alpha)/(1-alpha), поэтому
+// представитель корзины отличается от любого её значения не больше чем на alpha. Отрицательные значения
+// хранятся так же по модулю, значения около нуля считаются отдельно. Наброски с одинаковой точностью
+// сливаются сложением корзин, так что сервер может объединять наброски от многих агентов.
+package sketch
+
+import (
+	"errors"
+	"math"
+
+	"github.com/NikolayStrekalov/vigilant-octo-waddle.git/internal/models"
+)
+
+const (
+	// DefaultAlpha — точность квантилей по умолчанию, 1%.
+	DefaultAlpha = 0.01
+	// maxBins ограничивает число корзин, лишние корзины с наименьшими модулями сливаются.
+	maxBins = 2048
+	// minIndexable — значения меньше по модулю попадают в счётчик нулей.
+	minIndexable = 1e-9
+	// maxOffset ограничивает индексы корзин от клиентов, чтобы арифметика индексов не переполнялась.
+	maxOffset = 1 << 30
+)
+
+var (
+	errWrongAlpha = errors.New("alpha must be in (0, 1)")
+	errWrongBins  = errors.New("too many bins or bin offset out of range")
+	errWrongCount = errors.New("count must equal the number of values in bins")
+	errWrongStats = errors.New("sum, min and max must be finite, min must not exceed max")
+	errWrongQ     = errors.New("quantile must be in [0, 1]")
+	errEmpty      = errors.New("sketch is empty")
+
+	// ErrAlphaMismatch возвращается при слиянии набросков с разной точностью.
+	ErrAlphaMismatch = errors.New("alpha differs from the stored sketch")
+)
+
+// New возвращает пустой набросок с точностью alpha.
+func New(alpha float64) models.Sketch {
+	return models.Sketch{Alpha: alpha}
+}
+
+// ValidAlpha проверяет точность квантилей.
+func ValidAlpha(alpha float64) error {
+	if !(alpha > 0 && alpha < 1) {
+		return errWrongAlpha
+	}
+	return nil
+}
+
+// Validate проверяет набросок, полученный от клиента.
+func Validate(s *models.Sketch) error {
+	if err := ValidAlpha(s.Alpha); err != nil {
+		return err
+	}
+	for _, b := range []*models.SketchBins{&s.Positive, &s.Negative} {
+		if len(b.Counts) > maxBins || b.Offset > maxOffset || b.Offset < -maxOffset {
+			return errWrongBins
+		}
+	}
+	if total(&s.Positive)+total(&s.Negative)+s.Zero != s.Count {
+		return errWrongCount
+	}
+	for _, v := range []float64{s.Sum, s.Min, s.Max} {
+		if math.IsNaN(v) || math.IsInf(v, 0) {
+			return errWrongStats
+		}
+	}
+	if s.Count > 0 && s.Min > s.Max {
+		return errWrongStats
+	}
+	return nil
+}
+
+func total(b *models.SketchBins) uint64 {
+	var ret uint64
+	for _, c := range b.Counts {
+		ret += c
+	}
+	return ret
+}
+
+func gamma(alpha float64) float64 {
+	return (1 + alpha) / (1 - alpha)
+}
+
+func index(v, alpha float64) int {
+	return int(math.Ceil(math.Log(v) / math.Log(gamma(alpha))))
+}
+
+// value возвращает представителя корзины i, равноудалённого по относительной ошибке от её границ.
+func value(i int, alpha float64) float64 {
+	g := gamma(alpha)
+	return 2 * math.Pow(g, float64(i)) / (g + 1)
+}
+
+// Observe добавляет значение в набросок.
+func Observe(s *models.Sketch, v float64) {
+	switch {
+	case v > minIndexable:
+		add(&s.Positive, index(v, s.Alpha), 1)
+	case v < -minIndexable:
+		add(&s.Negative, index(-v, s.Alpha), 1)
+	default:
+		s.Zero++
+	}
+	if s.Count == 0 || v < s.Min {
+		s.Min = v
+	}
+	if s.Count == 0 || v > s.Max {
+		s.Max = v
+	}
+	s.Count++
+	s.Sum += v
+}
+
+// add прибавляет n значений к корзине i. Если корзин становится больше maxBins,
+// младшие сливаются в одну, поэтому память не растёт даже для далёких друг от друга индексов.
+func add(b *models.SketchBins, i int, n uint64) {
+	if len(b.Counts) == 0 {
+		b.Offset = i
+		b.Counts = []uint64{n}
+		return
+	}
+	lo, hi := min(b.Offset, i), max(b.Offset+len(b.Counts)-1, i)
+	if hi-lo >= maxBins {
+		lo = hi - maxBins + 1
+	}
+	if lo != b.Offset || hi != b.Offset+len(b.Counts)-1 {
+		counts := make([]uint64, hi-lo+1)
+		for j, c := range b.Counts {
+			counts[max(b.Offset+j, lo)-lo] += c
+		}
+		b.Counts, b.Offset = counts, lo
+	}
+	b.Counts[max(i, lo)-lo] += n
+}
+
+// Merge прибавляет к dst набросок src, пустой dst становится копией src.
+// Если точность различается, возвращается ErrAlphaMismatch, а dst не меняется.
+func Merge(dst, src *models.Sketch) error {
+	if dst.Count == 0 {
+		*dst = Copy(src)
+		return nil
+	}
+	if err := Compatible(dst, src); err != nil {
+		return err
+	}
+	if src.Count == 0 {
+		return nil
+	}
+	mergeBins(&dst.Positive, &src.Positive)
+	mergeBins(&dst.Negative, &src.Negative)
+	dst.Min = math.Min(dst.Min, src.Min)
+	dst.Max = math.Max(dst.Max, src.Max)
+	dst.Zero += src.Zero
+	dst.Count += src.Count
+	dst.Sum += src.Sum
+	return nil
+}
+
+// Compatible проверяет, что src можно слить с dst: точность набросков должна совпадать.
+func Compatible(dst, src *models.Sketch) error {
+	if dst.Alpha != src.Alpha {
+		return ErrAlphaMismatch
+	}
+	return nil
+}
+
+func mergeBins(dst, src *models.SketchBins) {
+	for i, c := range src.Counts {
+		if c > 0 {
+			add(dst, src.Offset+i, c)
+		}
+	}
+}
+
+func copyBins(b *models.SketchBins) models.SketchBins {
+	if len(b.Counts) == 0 {
+		return models.SketchBins{}
+	}
+	return models.SketchBins{Counts: append([]uint64{}, b.Counts...), Offset: b.Offset}
+}
+
+// Copy возвращает независимую копию наброска.
+func Copy(s *models.Sketch) models.Sketch {
+	return models.Sketch{
+		Positive: copyBins(&s.Positive),
+		Negative: copyBins(&s.Negative),
+		Alpha:    s.Alpha,
+		Sum:      s.Sum,
+		Min:      s.Min,
+		Max:      s.Max,
+		Zero:     s.Zero,
+		Count:    s.Count,
+	}
+}
+
+// Quantile возвращает квантиль q из [0, 1] с относительной точностью наброска.
+func Quantile(s *models.Sketch, q float64) (float64, error) {
+	if !(q >= 0 && q <= 1) {
+		return 0, errWrongQ
+	}
+	if s.Count == 0 {
+		return 0, errEmpty
+	}
+	rank := uint64(q * float64(s.Count-1))
+	var seen uint64
+	// отрицательные значения по возрастанию — корзины модулей в обратном порядке
+	for i := len(s.Negative.Counts) - 1; i >= 0; i-- {
+		seen += s.Negative.Counts[i]
+		if seen > rank {
+			return clamp(s, -value(s.Negative.Offset+i, s.Alpha)), nil
+		}
+	}
+	seen += s.Zero
+	if seen > rank {
+		return clamp(s, 0), nil
+	}
+	for i, c := range s.Positive.Counts {
+		seen += c
+		if seen > rank {
+			return clamp(s, value(s.Positive.Offset+i, s.Alpha)), nil
+		}
+	}
+	return s.Max, nil
+}
+
+func clamp(s *models.Sketch, v float64) float64 {
+	return math.Max(s.Min, math.Min(s.Max, v))
+}
@@ -0,0 +1,123 @@
+package sketch
+
+import (
+	"math"
+	"testing"
+
+	"github.com/NikolayStrekalov/vigilant-octo-waddle.git/internal/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestQuantile(t *testing.T) {
+	s := New(DefaultAlpha)
+	for i := 1; i <= 1000; i++ {
+		Observe(&s, float64(i))
+	}
+	tests := []struct {
+		q    float64
+		want float64
+	}{
+		{q: 0, want: 1},
+		{q: 0.5, want: 500},
+		{q: 0.9, want: 900},
+		{q: 0.99, want: 990},
+		{q: 1, want: 1000},
+	}
+	for _, tt := range tests {
+		got, err := Quantile(&s, tt.q)
+		assert.Nil(t, err)
+		assert.InEpsilon(t, tt.want, got, DefaultAlpha+0.001, "q=%v", tt.q)
+	}
+	_, err := Quantile(&s, 1.5)
+	assert.Error(t, err)
+	empty := New(DefaultAlpha)
+	_, err = Quantile(&empty, 0.5)
+	assert.Error(t, err)
+}
+
+func TestQuantile_Signs(t *testing.T) {
+	s := New(DefaultAlpha)
+	for _, v := range []float64{-100, -10, 0, 10, 100} {
+		Observe(&s, v)
+	}
+	assert.NoError(t, Validate(&s))
+	for q, want := range map[float64]float64{0: -100, 0.25: -10, 0.5: 0, 0.75: 10, 1: 100} {
+		got, err := Quantile(&s, q)
+		assert.Nil(t, err)
+		assert.InDelta(t, want, got, math.Abs(want)*DefaultAlpha, "q=%v", q)
+	}
+}
+
+func TestMerge(t *testing.T) {
+	a, b, all := New(DefaultAlpha), New(DefaultAlpha), New(DefaultAlpha)
+	for i := 1; i <= 100; i++ {
+		Observe(&a, float64(i))
+		Observe(&all, float64(i))
+	}
+	for i := 1; i <= 100; i++ {
+		Observe(&b, float64(i)*1000)
+		Observe(&all, float64(i)*1000)
+	}
+	merged := Copy(&a)
+	assert.Nil(t, Merge(&merged, &b))
+	assert.Equal(t, all, merged)
+	assert.Equal(t, uint64(100), a.Count, "merge must not change its source")
+
+	other := New(0.05)
+	Observe(&other, 7)
+	assert.ErrorIs(t, Merge(&merged, &other), ErrAlphaMismatch)
+	assert.Equal(t, all, merged, "stored sketch is kept on mismatch")
+
+	empty := models.Sketch{}
+	assert.Nil(t, Merge(&empty, &other))
+	assert.Equal(t, other, empty)
+}
+
+func TestBinsLimit(t *testing.T) {
+	s := New(DefaultAlpha)
+	Observe(&s, 1e-8)
+	Observe(&s, 1e300)
+	assert.LessOrEqual(t, len(s.Positive.Counts), maxBins)
+	assert.NoError(t, Validate(&s))
+	got, err := Quantile(&s, 1)
+	assert.Nil(t, err)
+	assert.InEpsilon(t, 1e300, got, DefaultAlpha)
+}
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		s       models.Sketch
+		wantErr bool
+	}{
+		{
+			name: "Valid",
+			s: models.Sketch{
+				Alpha: 0.01, Positive: models.SketchBins{Offset: 10, Counts: []uint64{1, 0, 2}},
+				Zero: 1, Count: 4, Min: 0, Max: 1.3, Sum: 3,
+			},
+		},
+		{name: "Empty", s: models.Sketch{Alpha: 0.01}},
+		{name: "Wrong alpha", s: models.Sketch{Alpha: 1}, wantErr: true},
+		{name: "Wrong count", s: models.Sketch{Alpha: 0.01, Zero: 1, Count: 2}, wantErr: true},
+		{
+			name: "Wrong offset",
+			s: models.Sketch{
+				Alpha: 0.01, Negative: models.SketchBins{Offset: math.MaxInt32, Counts: []uint64{1}}, Count: 1,
+			},
+			wantErr: true,
+		},
+		{name: "Wrong min", s: models.Sketch{Alpha: 0.01, Zero: 1, Count: 1, Min: 1}, wantErr: true},
+		{name: "NaN sum", s: models.Sketch{Alpha: 0.01, Sum: math.NaN()}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := Validate(&tt.s)
+			if tt.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
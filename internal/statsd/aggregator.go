@@ -0,0 +1,109 @@
+package statsd
+
+import (
+	"math"
+	"sort"
+	"sync"
+
+	"github.com/NikolayStrekalov/vigilant-octo-waddle.git/internal/models"
+	"github.com/NikolayStrekalov/vigilant-octo-waddle.git/internal/series"
+)
+
+// Storage — хранилище, в которое сбрасываются накопленные значения.
+type Storage interface {
+	GetGauge(string) (float64, error)
+	BulkUpdate(models.MetricsSlice)
+}
+
+type gaugeState struct {
+	value    float64
+	absolute bool // в интервале было абсолютное значение, иначе value — сумма изменений
+}
+
+// Aggregator копит значения серий между сбросами: счётчики суммируются с учётом частоты выборки,
+// для gauge берётся последнее значение с учётом последующих изменений.
+type Aggregator struct {
+	counters  map[string]float64
+	gauges    map[string]*gaugeState
+	remainder map[string]float64 // дробные части счётчиков, не попавшие в прошлые сбросы
+	mux       *sync.Mutex
+}
+
+func NewAggregator() *Aggregator {
+	return &Aggregator{
+		counters:  make(map[string]float64),
+		gauges:    make(map[string]*gaugeState),
+		remainder: make(map[string]float64),
+		mux:       &sync.Mutex{},
+	}
+}
+
+// Add учитывает строку, типы кроме c и g пропускаются.
+func (a *Aggregator) Add(l *Line) {
+	a.mux.Lock()
+	defer a.mux.Unlock()
+	switch l.Type {
+	case typeCounter:
+		a.counters[l.Key()] += l.Value / l.Rate
+	case typeGauge:
+		st, ok := a.gauges[l.Key()]
+		if !ok {
+			st = &gaugeState{}
+			a.gauges[l.Key()] = st
+		}
+		if l.Relative {
+			st.value += l.Value
+			return
+		}
+		st.value = l.Value
+		st.absolute = true
+	}
+}
+
+// Flush записывает накопленное в хранилище и начинает новый интервал.
+func (a *Aggregator) Flush(storage Storage) {
+	a.mux.Lock()
+	counters, gauges := a.counters, a.gauges
+	a.counters = make(map[string]float64)
+	a.gauges = make(map[string]*gaugeState)
+	metrics := make(models.MetricsSlice, 0, len(counters)+len(gauges))
+	for key, v := range counters {
+		total := v + a.remainder[key]
+		if math.Abs(total) >= maxCounter {
+			// сумма строк за интервал не помещается в int64, интервал серии пропускается
+			delete(a.remainder, key)
+			continue
+		}
+		delta := int64(math.Floor(total))
+		if rest := total - float64(delta); rest != 0 {
+			a.remainder[key] = rest
+		} else {
+			delete(a.remainder, key)
+		}
+		if delta == 0 {
+			continue
+		}
+		name, labels := series.Parse(key)
+		metrics = append(metrics, models.Metrics{ID: name, Labels: labels, MType: "counter", Delta: &delta})
+	}
+	a.mux.Unlock()
+	for key, st := range gauges {
+		value := st.value
+		if !st.absolute {
+			current, err := storage.GetGauge(key)
+			if err == nil {
+				value += current
+			}
+		}
+		if math.IsNaN(value) || math.IsInf(value, 0) {
+			continue
+		}
+		name, labels := series.Parse(key)
+		metrics = append(metrics, models.Metrics{ID: name, Labels: labels, MType: "gauge", Value: &value})
+	}
+	if len(metrics) == 0 {
+		return
+	}
+	sort.Slice(metrics, func(i, j int) bool { return metrics[i].Key() < metrics[j].Key() })
+	storage.BulkUpdate(metrics)
+}
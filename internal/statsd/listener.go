@@ -0,0 +1,84 @@
+package statsd
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+	"time"
+
+	"github.com/NikolayStrekalov/vigilant-octo-waddle.git/internal/logger"
+)
+
+const maxPacketSize = 65535
+
+// Listener читает пакеты StatsD из UDP-сокета и периодически сбрасывает их в хранилище.
+type Listener struct {
+	conn net.PacketConn
+	agg  *Aggregator
+}
+
+func Listen(addr string) (*Listener, error) {
+	conn, err := net.ListenPacket("udp", addr)
+	if err != nil {
+		return nil, fmt.Errorf("statsd listen error: %w", err)
+	}
+	return &Listener{conn: conn, agg: NewAggregator()}, nil
+}
+
+// Addr возвращает адрес, на котором слушает сокет.
+func (l *Listener) Addr() net.Addr {
+	return l.conn.LocalAddr()
+}
+
+// Serve читает пакеты до закрытия сокета, строки с ошибками пропускаются.
+func (l *Listener) Serve() error {
+	buf := make([]byte, maxPacketSize)
+	for {
+		n, _, err := l.conn.ReadFrom(buf)
+		if errors.Is(err, net.ErrClosed) {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("statsd read error: %w", err)
+		}
+		for _, s := range strings.Split(string(buf[:n]), "\n") {
+			if s = strings.TrimSpace(s); s == "" {
+				continue
+			}
+			line, err := ParseLine(s)
+			if err != nil {
+				logger.Info("statsd:", err)
+				continue
+			}
+			l.agg.Add(&line)
+		}
+	}
+}
+
+// Run принимает пакеты и сбрасывает накопленное в storage раз в interval.
+func (l *Listener) Run(storage Storage, interval time.Duration) {
+	go func() {
+		if err := l.Serve(); err != nil {
+			logger.Info(err)
+		}
+	}()
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		<-ticker.C
+		l.agg.Flush(storage)
+	}
+}
+
+// Flush сразу сбрасывает накопленное в storage.
+func (l *Listener) Flush(storage Storage) {
+	l.agg.Flush(storage)
+}
+
+func (l *Listener) Close() error {
+	if err := l.conn.Close(); err != nil {
+		return fmt.Errorf("statsd close error: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,101 @@
+// Package statsd принимает метрики в формате StatsD по UDP.
+package statsd
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/NikolayStrekalov/vigilant-octo-waddle.git/internal/series"
+)
+
+// Типы метрик StatsD.
+const (
+	typeCounter = "c"
+	typeGauge   = "g"
+)
+
+var (
+	errWrongLine  = errors.New("line format: <name>:<value>|<type>[|@<rate>][|#<tags>]")
+	errWrongRate  = errors.New("sample rate must be in (0, 1]")
+	errWrongValue = errors.New("value must be finite, counter value must fit in int64")
+)
+
+// maxCounter — граница int64 в float64: приращения счётчика по модулю не меньше неё не переводятся в int64.
+const maxCounter = math.MaxInt64
+
+// Line — разобранная строка StatsD.
+type Line struct {
+	Name     string
+	Type     string
+	Value    float64
+	Tags     map[string]string
+	Rate     float64
+	Relative bool // значение gauge со знаком изменяет текущее, а не задаёт новое
+}
+
+// Key возвращает ключ серии: теги становятся метками.
+func (l *Line) Key() string {
+	return series.Key(l.Name, l.Tags)
+}
+
+// ParseLine разбирает строку вида name:value|type[|@rate][|#tags].
+// Теги в формате DogStatsD key:value становятся метками, теги без значения пропускаются.
+// Значения NaN и Inf отклоняются, как и значения счётчиков с учётом частоты выборки вне диапазона int64.
+func ParseLine(s string) (Line, error) {
+	l := Line{Rate: 1}
+	name, rest, ok := strings.Cut(s, ":")
+	if !ok || name == "" {
+		return l, fmt.Errorf("wrong line %q: %w", s, errWrongLine)
+	}
+	l.Name = name
+	parts := strings.Split(rest, "|")
+	if len(parts) < 2 || parts[0] == "" || parts[1] == "" {
+		return l, fmt.Errorf("wrong line %q: %w", s, errWrongLine)
+	}
+	value, err := strconv.ParseFloat(parts[0], 64)
+	if err != nil {
+		return l, fmt.Errorf("wrong value in line %q: %w", s, err)
+	}
+	if math.IsNaN(value) || math.IsInf(value, 0) {
+		return l, fmt.Errorf("wrong value in line %q: %w", s, errWrongValue)
+	}
+	l.Value = value
+	l.Type = parts[1]
+	l.Relative = l.Type == typeGauge && (parts[0][0] == '+' || parts[0][0] == '-')
+	for _, p := range parts[2:] {
+		if strings.HasPrefix(p, "#") {
+			l.Tags = parseTags(p[1:])
+			continue
+		}
+		if !strings.HasPrefix(p, "@") {
+			continue
+		}
+		rate, err := strconv.ParseFloat(p[1:], 64)
+		if err != nil || rate <= 0 || rate > 1 {
+			return l, fmt.Errorf("wrong rate in line %q: %w", s, errWrongRate)
+		}
+		l.Rate = rate
+	}
+	if l.Type == typeCounter && math.Abs(l.Value/l.Rate) >= maxCounter {
+		return l, fmt.Errorf("wrong value in line %q: %w", s, errWrongValue)
+	}
+	return l, nil
+}
+
+func parseTags(s string) map[string]string {
+	var tags map[string]string
+	for _, tag := range strings.Split(s, ",") {
+		k, v, ok := strings.Cut(tag, ":")
+		if !ok || k == "" {
+			continue
+		}
+		if tags == nil {
+			tags = make(map[string]string)
+		}
+		tags[series.Sanitize(k)] = v
+	}
+	return tags
+}
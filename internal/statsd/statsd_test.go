@@ -0,0 +1,135 @@
+package statsd
+
+import (
+	"errors"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/NikolayStrekalov/vigilant-octo-waddle.git/internal/logger"
+	"github.com/NikolayStrekalov/vigilant-octo-waddle.git/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseLine(t *testing.T) {
+	tests := []struct {
+		name    string
+		line    string
+		want    Line
+		wantErr bool
+	}{
+		{name: "Counter", line: "requests:1|c", want: Line{Name: "requests", Type: "c", Value: 1, Rate: 1}},
+		{
+			name: "Sampled counter with tags",
+			line: "requests:2|c|@0.1|#env:prod,canary",
+			want: Line{Name: "requests", Type: "c", Value: 2, Rate: 0.1, Tags: map[string]string{"env": "prod"}},
+		},
+		{name: "Gauge", line: "queue:42|g", want: Line{Name: "queue", Type: "g", Value: 42, Rate: 1}},
+		{
+			name: "Relative gauge",
+			line: "queue:+3|g",
+			want: Line{Name: "queue", Type: "g", Value: 3, Rate: 1, Relative: true},
+		},
+		{
+			name: "Negative gauge change",
+			line: "queue:-3|g",
+			want: Line{Name: "queue", Type: "g", Value: -3, Rate: 1, Relative: true},
+		},
+		{name: "Timer", line: "latency:320|ms", want: Line{Name: "latency", Type: "ms", Value: 320, Rate: 1}},
+		{name: "No type", line: "requests:1", wantErr: true},
+		{name: "No name", line: ":1|c", wantErr: true},
+		{name: "Wrong value", line: "requests:x|c", wantErr: true},
+		{name: "Wrong rate", line: "requests:1|c|@2", wantErr: true},
+		{name: "NaN", line: "queue:NaN|g", wantErr: true},
+		{name: "Inf", line: "requests:+Inf|c", wantErr: true},
+		{name: "Huge counter", line: "requests:1e300|c", wantErr: true},
+		{name: "Huge sampled counter", line: "requests:1e19|c|@0.5", wantErr: true},
+		{name: "Huge gauge", line: "queue:1e300|g", want: Line{Name: "queue", Type: "g", Value: 1e300, Rate: 1}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseLine(tt.line)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.Nil(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+type fakeStorage struct {
+	gauges   map[string]float64
+	counters map[string]int64
+}
+
+func (f *fakeStorage) GetGauge(name string) (float64, error) {
+	if v, ok := f.gauges[name]; ok {
+		return v, nil
+	}
+	return 0, errors.New("not found")
+}
+
+func (f *fakeStorage) BulkUpdate(metrics models.MetricsSlice) {
+	for _, m := range metrics {
+		if m.MType == "counter" {
+			f.counters[m.ID] += *m.Delta
+		} else {
+			f.gauges[m.ID] = *m.Value
+		}
+	}
+}
+
+func TestAggregator(t *testing.T) {
+	storage := &fakeStorage{gauges: map[string]float64{"queue": 10}, counters: map[string]int64{}}
+	a := NewAggregator()
+	for _, s := range []string{"requests:1|c", "requests:1|c|@0.5", "queue:+3|g", "queue:-1|g", "free:5|g", "free:7|g"} {
+		l, err := ParseLine(s)
+		require.NoError(t, err)
+		a.Add(&l)
+	}
+	a.Flush(storage)
+	assert.Equal(t, map[string]int64{"requests": 3}, storage.counters)
+	assert.Equal(t, map[string]float64{"queue": 12, "free": 7}, storage.gauges)
+
+	l, _ := ParseLine("requests:1|c|@0.4")
+	a.Add(&l)
+	a.Flush(storage)
+	assert.Equal(t, int64(5), storage.counters["requests"])
+	a.Add(&l)
+	a.Flush(storage)
+	assert.Equal(t, int64(8), storage.counters["requests"])
+	assert.Empty(t, a.remainder, "zero remainders are pruned")
+
+	// сумма за интервал вне int64 пропускается и не портит следующие сбросы
+	huge, _ := ParseLine("requests:9e18|c")
+	a.Add(&huge)
+	a.Add(&huge)
+	a.Flush(storage)
+	assert.Equal(t, int64(8), storage.counters["requests"])
+	a.Add(&l)
+	a.Flush(storage)
+	assert.Equal(t, int64(10), storage.counters["requests"])
+}
+
+func TestListener(t *testing.T) {
+	_ = logger.InitLog()
+	l, err := Listen("127.0.0.1:0")
+	require.NoError(t, err)
+	go func() { _ = l.Serve() }()
+	defer func() { _ = l.Close() }()
+
+	conn, err := net.Dial("udp", l.Addr().String())
+	require.NoError(t, err)
+	defer func() { _ = conn.Close() }()
+	_, err = conn.Write([]byte("requests:2|c\nqueue:42|g\nbroken\n"))
+	require.NoError(t, err)
+
+	storage := &fakeStorage{gauges: map[string]float64{}, counters: map[string]int64{}}
+	assert.Eventually(t, func() bool {
+		l.Flush(storage)
+		return storage.counters["requests"] == 2 && storage.gauges["queue"] == 42
+	}, time.Second, 10*time.Millisecond)
+}